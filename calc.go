@@ -0,0 +1,662 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+)
+
+var (
+	// ErrInvalidCalc
+	ErrInvalidCalc = errors.New("invalid math expression")
+)
+
+var mathFunctions = map[string]bool{
+	"calc":  true,
+	"min":   true,
+	"max":   true,
+	"clamp": true,
+}
+
+// canonicalUnits converts compatible units to a canonical one
+var canonicalUnits = map[string]struct {
+	unit  string
+	ratio float64
+}{
+	"px":   {"px", 1},
+	"cm":   {"px", 96 / 2.54},
+	"mm":   {"px", 96 / 25.4},
+	"q":    {"px", 96 / 101.6},
+	"in":   {"px", 96},
+	"pt":   {"px", 96.0 / 72},
+	"pc":   {"px", 16},
+	"deg":  {"deg", 1},
+	"grad": {"deg", 0.9},
+	"rad":  {"deg", 180 / math.Pi},
+	"turn": {"deg", 360},
+	"s":    {"s", 1},
+	"ms":   {"s", 0.001},
+	"hz":   {"hz", 1},
+	"khz":  {"hz", 1000},
+	"dppx": {"dppx", 1},
+	"x":    {"dppx", 1},
+	"dpi":  {"dppx", 1.0 / 96},
+	"dpcm": {"dppx", 2.54 / 96},
+}
+
+// Calc is a math function https://developer.mozilla.org/en-US/docs/Web/CSS/calc
+// one of calc(), min(), max() or clamp()
+type Calc struct {
+	Func TextBytes   `json:"func"`
+	Args []*CalcNode `json:"args"`
+}
+
+// CalcNode is a node of math expression: an operation when Operator is set,
+// a nested math function, a raw value like var() or a number with unit
+type CalcNode struct {
+	Operator TextBytes `json:"operator,omitempty"`
+	Left     *CalcNode `json:"left,omitempty"`
+	Right    *CalcNode `json:"right,omitempty"`
+	Func     *Calc     `json:"func,omitempty"`
+	Raw      TextBytes `json:"raw,omitempty"`
+	Number   float64   `json:"number,omitempty"`
+	Unit     TextBytes `json:"unit,omitempty"`
+}
+
+// IsMathFunction reports whether b is a call of calc(), min(), max() or clamp()
+func IsMathFunction(b []byte) bool {
+	idx := bytes.IndexByte(b, leftParenthesis)
+	if idx < 0 || b[len(b)-1] != rightParenthesis {
+		return false
+	}
+	return mathFunctions[string(bytes.ToLower(b[:idx]))]
+}
+
+// ParseCalc parses a math function
+func ParseCalc(b []byte) (*Calc, error) {
+	p := &calcParser{src: bytes.TrimSpace(b)}
+
+	name := p.ident()
+	if !mathFunctions[string(bytes.ToLower(name))] || !p.consume(leftParenthesis) {
+		return nil, ErrInvalidCalc
+	}
+
+	ret, err := p.function(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skipSpaces(); p.pos != len(p.src) {
+		return nil, ErrInvalidCalc
+	}
+
+	return ret, nil
+}
+
+func (v *Calc) encode(dst *bytes.Buffer) error {
+	dst.Write(v.Func)
+	dst.WriteByte(leftParenthesis)
+	for idx, i := range v.Args {
+		if err := i.encode(dst); err != nil {
+			return err
+		}
+		if len(v.Args)-1 > idx {
+			dst.WriteByte(comma)
+		}
+	}
+	dst.WriteByte(rightParenthesis)
+
+	return nil
+}
+
+// Simplify folds compatible units and returns the simplified node,
+// expression with incompatible units stays symbolic
+func (v *Calc) Simplify() *CalcNode {
+	return (&CalcNode{Func: v}).simplify()
+}
+
+func (v *CalcNode) encode(dst *bytes.Buffer) error {
+	switch {
+	case v.Func != nil:
+		return v.Func.encode(dst)
+	case len(v.Raw) > 0:
+		dst.Write(v.Raw)
+	case len(v.Operator) > 0:
+		if v.Left == nil || v.Right == nil {
+			return ErrInvalidCalc
+		}
+		if err := v.Left.encodeOperand(dst, v.precedence() > v.Left.precedence()); err != nil {
+			return err
+		}
+		if v.precedence() == 1 {
+			dst.WriteByte(space)
+			dst.Write(v.Operator)
+			dst.WriteByte(space)
+		} else {
+			dst.Write(v.Operator)
+		}
+		return v.Right.encodeOperand(dst, v.precedence() >= v.Right.precedence())
+	default:
+		dst.WriteString(formatNumber(v.Number))
+		dst.Write(v.Unit)
+	}
+
+	return nil
+}
+
+func (v *CalcNode) encodeOperand(dst *bytes.Buffer, parentheses bool) error {
+	if !parentheses || len(v.Operator) == 0 {
+		return v.encode(dst)
+	}
+
+	dst.WriteByte(leftParenthesis)
+	if err := v.encode(dst); err != nil {
+		return err
+	}
+	dst.WriteByte(rightParenthesis)
+
+	return nil
+}
+
+func (v *CalcNode) precedence() int {
+	switch string(v.Operator) {
+	case "+", "-":
+		return 1
+	case "*", "/":
+		return 2
+	}
+	return 3
+}
+
+func (v *CalcNode) isNumeric() bool {
+	return v.Func == nil && len(v.Raw) == 0 && len(v.Operator) == 0
+}
+
+func (v *CalcNode) simplify() *CalcNode {
+	switch {
+	case v.Func != nil:
+		return v.simplifyFunc()
+	case len(v.Operator) == 0:
+		return v
+	}
+
+	left, right := v.Left.simplify(), v.Right.simplify()
+
+	switch string(v.Operator) {
+	case "+", "-":
+		return simplifySum(&CalcNode{Operator: v.Operator, Left: left, Right: right})
+	case "*":
+		if left.isNumeric() && right.isNumeric() {
+			if len(left.Unit) == 0 {
+				return &CalcNode{Number: left.Number * right.Number, Unit: right.Unit}
+			}
+			if len(right.Unit) == 0 {
+				return &CalcNode{Number: left.Number * right.Number, Unit: left.Unit}
+			}
+		}
+	case "/":
+		if left.isNumeric() && right.isNumeric() && len(right.Unit) == 0 && right.Number != 0 {
+			return &CalcNode{Number: left.Number / right.Number, Unit: left.Unit}
+		}
+	}
+
+	return &CalcNode{Operator: v.Operator, Left: left, Right: right}
+}
+
+func (v *CalcNode) simplifyFunc() *CalcNode {
+	args := make([]*CalcNode, len(v.Func.Args))
+	for k, i := range v.Func.Args {
+		args[k] = i.simplify()
+	}
+
+	name := string(bytes.ToLower(v.Func.Func))
+	switch name {
+	case "calc":
+		if len(args) == 1 {
+			return args[0]
+		}
+	case "min", "max":
+		args = foldMinMax(name == "min", args)
+		if len(args) == 1 {
+			return args[0]
+		}
+	case "clamp":
+		if len(args) == 3 {
+			lo, val, hi := args[0], args[1], args[2]
+			if sameUnit(lo, val, hi) {
+				return &CalcNode{Number: math.Max(lo.Number, math.Min(val.Number, hi.Number)), Unit: val.Unit}
+			}
+		}
+	}
+
+	return &CalcNode{Func: &Calc{Func: v.Func.Func, Args: args}}
+}
+
+// foldMinMax keeps the smallest or the largest of numeric arguments with
+// the same unit
+func foldMinMax(min bool, args []*CalcNode) []*CalcNode {
+	var ret []*CalcNode
+
+next:
+	for _, i := range args {
+		if i.isNumeric() {
+			for k, j := range ret {
+				if j.isNumeric() && bytes.Equal(j.Unit, i.Unit) {
+					if (min && i.Number < j.Number) || (!min && i.Number > j.Number) {
+						ret[k] = i
+					}
+					continue next
+				}
+			}
+		}
+		ret = append(ret, i)
+	}
+
+	return ret
+}
+
+func sameUnit(nodes ...*CalcNode) bool {
+	for _, i := range nodes {
+		if !i.isNumeric() || !bytes.Equal(i.Unit, nodes[0].Unit) {
+			return false
+		}
+	}
+	return true
+}
+
+type calcTerm struct {
+	negative bool
+	node     *CalcNode
+}
+
+// simplifySum sums numeric terms of the same or convertible units
+func simplifySum(v *CalcNode) *CalcNode {
+	var (
+		terms  []calcTerm
+		others []calcTerm
+		units  = map[string]int{}
+	)
+
+	for _, t := range flattenSum(v, false, nil) {
+		if !t.node.isNumeric() {
+			others = append(others, t)
+			continue
+		}
+
+		n := *t.node
+		if t.negative {
+			n.Number = -n.Number
+		}
+
+		unit := string(n.Unit)
+		if c, ok := canonicalUnits[unit]; ok {
+			unit = c.unit
+		}
+
+		idx, ok := units[unit]
+		if !ok {
+			units[unit] = len(terms)
+			terms = append(terms, calcTerm{node: &n})
+			continue
+		}
+
+		sum := terms[idx].node
+		if !bytes.Equal(sum.Unit, n.Unit) {
+			sum = toCanonicalUnit(sum)
+			n = *toCanonicalUnit(&n)
+		}
+		terms[idx].node = &CalcNode{Number: sum.Number + n.Number, Unit: sum.Unit}
+	}
+
+	var ret *CalcNode
+	for _, t := range append(terms, others...) {
+		node, negative := t.node, t.negative
+		if node.isNumeric() {
+			if node.Number == 0 && len(terms)+len(others) > 1 {
+				continue
+			}
+			if ret != nil && node.Number < 0 {
+				node = &CalcNode{Number: -node.Number, Unit: node.Unit}
+				negative = true
+			}
+		}
+
+		switch {
+		case ret == nil && negative:
+			ret = &CalcNode{Operator: TextBytes("*"), Left: &CalcNode{Number: -1}, Right: node}
+		case ret == nil:
+			ret = node
+		case negative:
+			ret = &CalcNode{Operator: TextBytes("-"), Left: ret, Right: node}
+		default:
+			ret = &CalcNode{Operator: TextBytes("+"), Left: ret, Right: node}
+		}
+	}
+
+	if ret == nil {
+		return terms[0].node
+	}
+
+	return ret
+}
+
+func flattenSum(v *CalcNode, negative bool, dst []calcTerm) []calcTerm {
+	op := string(v.Operator)
+	if op != "+" && op != "-" {
+		return append(dst, calcTerm{negative: negative, node: v})
+	}
+
+	dst = flattenSum(v.Left, negative, dst)
+	return flattenSum(v.Right, negative != (op == "-"), dst)
+}
+
+func toCanonicalUnit(v *CalcNode) *CalcNode {
+	c, ok := canonicalUnits[string(v.Unit)]
+	if !ok {
+		return v
+	}
+	return &CalcNode{Number: v.Number * c.ratio, Unit: TextBytes(c.unit)}
+}
+
+// SimplifyMath simplifies math functions in every declaration in place
+func SimplifyMath(s Statements) {
+	for k := range s {
+		simplifyMathStatement(&s[k])
+	}
+}
+
+func simplifyMathStatement(v *Statement) {
+	if v.AtRule != nil {
+		if info, ok := v.AtRule.Identifier.Information.(*FontFaceInformation); ok {
+			simplifyMathDeclarations(info.Declarations)
+		}
+		for _, i := range v.AtRule.Nested {
+			simplifyMathStatement(i)
+		}
+	}
+	if v.Ruleset != nil {
+		simplifyMathDeclarations(v.Ruleset.Declarations)
+		for _, i := range v.Ruleset.Nested {
+			simplifyMathStatement(i)
+		}
+	}
+}
+
+func simplifyMathDeclarations(decls []Declaration) {
+	for _, d := range decls {
+		for k := range d.Values {
+			d.Values[k].SimplifyMath()
+		}
+	}
+}
+
+// SimplifyMath simplifies math functions of the value in place, a typed
+// token simplified to a number or a dimension becomes a raw one
+func (v *Value) SimplifyMath() {
+	var (
+		raw    []TextBytes
+		tokens []Token
+	)
+
+	for _, i := range v.components() {
+		switch c := i.(type) {
+		case rawToken:
+			raw = append(raw, simplifyMathToken(TextBytes(c)))
+		case *Token:
+			t := *c
+			if t.Calc != nil {
+				node := t.Calc.Simplify()
+				if node.Func == nil {
+					raw = append(raw, calcNodeBytes(node))
+					continue
+				}
+				t.Calc = node.Func
+			}
+			t.Index = len(raw) + len(tokens)
+			tokens = append(tokens, t)
+		}
+	}
+
+	v.ValueSpace, v.Tokens = raw, tokens
+}
+
+func simplifyMathToken(token TextBytes) TextBytes {
+	if !IsMathFunction(token) {
+		return token
+	}
+	c, err := ParseCalc(token)
+	if err != nil {
+		return token
+	}
+	return calcNodeBytes(c.Simplify())
+}
+
+// calcNodeBytes writes node as a value, wrapping operations in calc()
+func calcNodeBytes(v *CalcNode) TextBytes {
+	if len(v.Operator) > 0 {
+		v = &CalcNode{Func: &Calc{Func: TextBytes("calc"), Args: []*CalcNode{v}}}
+	}
+	return TextBytes(encodeBytes(v))
+}
+
+func formatNumber(f float64) string {
+	f = math.Round(f*1e6) / 1e6
+	if f == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type calcParser struct {
+	src []byte
+	pos int
+}
+
+func (p *calcParser) skipSpaces() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *calcParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *calcParser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *calcParser) ident() []byte {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.src) && isIdentByte(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// function parses arguments of a math function after its parenthesis
+func (p *calcParser) function(name []byte) (*Calc, error) {
+	ret := &Calc{Func: TextBytes(bytes.ToLower(name))}
+
+	for {
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		ret.Args = append(ret.Args, arg)
+
+		if p.consume(comma) {
+			continue
+		}
+		if !p.consume(rightParenthesis) {
+			return nil, ErrInvalidCalc
+		}
+		break
+	}
+
+	switch string(ret.Func) {
+	case "calc":
+		if len(ret.Args) != 1 {
+			return nil, ErrInvalidCalc
+		}
+	case "clamp":
+		if len(ret.Args) != 3 {
+			return nil, ErrInvalidCalc
+		}
+	}
+
+	return ret, nil
+}
+
+func (p *calcParser) sum() (*CalcNode, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+
+		// "+" and "-" need whitespace on both sides, "1px -2px" is two values
+		if !isSpace(p.src[p.pos-2]) || p.pos >= len(p.src) || !isSpace(p.src[p.pos]) {
+			return nil, ErrInvalidCalc
+		}
+
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		left = &CalcNode{Operator: TextBytes{op}, Left: left, Right: right}
+	}
+}
+
+func (p *calcParser) product() (*CalcNode, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &CalcNode{Operator: TextBytes{op}, Left: left, Right: right}
+	}
+}
+
+func (p *calcParser) operand() (*CalcNode, error) {
+	c := p.peek()
+
+	switch {
+	case c == leftParenthesis:
+		p.pos++
+		ret, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(rightParenthesis) {
+			return nil, ErrInvalidCalc
+		}
+		return ret, nil
+	case isDigit(c) || c == period || ((c == '+' || c == '-') && p.pos+1 < len(p.src) && (isDigit(p.src[p.pos+1]) || p.src[p.pos+1] == period)):
+		return p.number()
+	}
+
+	start := p.pos
+	name := p.ident()
+	if len(name) == 0 {
+		return nil, ErrInvalidCalc
+	}
+
+	if p.pos >= len(p.src) || p.src[p.pos] != leftParenthesis {
+		return &CalcNode{Raw: TextBytes(name)}, nil
+	}
+
+	if mathFunctions[string(bytes.ToLower(name))] {
+		p.pos++
+		f, err := p.function(name)
+		if err != nil {
+			return nil, err
+		}
+		return &CalcNode{Func: f}, nil
+	}
+
+	end := closingParenthesis(p.src, p.pos)
+	if end < 0 {
+		return nil, ErrInvalidCalc
+	}
+	p.pos = end + 1
+
+	return &CalcNode{Raw: TextBytes(p.src[start:p.pos])}, nil
+}
+
+func (p *calcParser) number() (*CalcNode, error) {
+	start := p.pos
+	if c := p.src[p.pos]; c == '+' || c == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == period) {
+		p.pos++
+	}
+	if p.pos+1 < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') &&
+		(isDigit(p.src[p.pos+1]) || ((p.src[p.pos+1] == '+' || p.src[p.pos+1] == '-') && p.pos+2 < len(p.src) && isDigit(p.src[p.pos+2]))) {
+		p.pos += 2
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+	}
+
+	n, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+	if err != nil {
+		return nil, ErrInvalidCalc
+	}
+
+	unitStart := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '%' {
+		p.pos++
+	} else {
+		for p.pos < len(p.src) && isLetter(p.src[p.pos]) {
+			p.pos++
+		}
+	}
+
+	ret := &CalcNode{Number: n}
+	if p.pos > unitStart {
+		ret.Unit = TextBytes(bytes.ToLower(p.src[unitStart:p.pos]))
+	}
+
+	return ret, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentByte(c byte) bool {
+	return isLetter(c) || isDigit(c) || c == '-' || c == '_' || c >= 0x80
+}
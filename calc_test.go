@@ -0,0 +1,166 @@
+package css2json
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseCalc(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *Calc
+		wantErr bool
+	}{
+		{
+			in: "calc(10px + 2 * 3em)",
+			want: &Calc{
+				Func: TextBytes("calc"),
+				Args: []*CalcNode{
+					{
+						Operator: TextBytes("+"),
+						Left:     &CalcNode{Number: 10, Unit: TextBytes("px")},
+						Right: &CalcNode{
+							Operator: TextBytes("*"),
+							Left:     &CalcNode{Number: 2},
+							Right:    &CalcNode{Number: 3, Unit: TextBytes("em")},
+						},
+					},
+				},
+			},
+		},
+		{
+			in: "min(var(--a, 1px), -.5rem)",
+			want: &Calc{
+				Func: TextBytes("min"),
+				Args: []*CalcNode{
+					{Raw: TextBytes("var(--a, 1px)")},
+					{Number: -0.5, Unit: TextBytes("rem")},
+				},
+			},
+		},
+		{
+			in:      "clamp(1px, 2px)",
+			wantErr: true,
+		},
+		{
+			in:      "calc(1px +)",
+			wantErr: true,
+		},
+		{
+			in:      "calc(1px+2px)",
+			wantErr: true,
+		},
+		{
+			in:      "calc(1px -2px)",
+			wantErr: true,
+		},
+		{
+			in:      "rgb(1, 2, 3)",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCalc([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCalc() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCalc() = %s, want %s", encodeBytes(got), encodeBytes(tt.want))
+			}
+		})
+	}
+}
+
+func TestCalc_Simplify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "calc(10px + 2px)", want: "12px"},
+		{in: "calc(10px - 2px - 8px)", want: "0px"},
+		{in: "calc(1in + 4px)", want: "100px"},
+		{in: "calc(1s + 500ms)", want: "1.5s"},
+		{in: "calc(10px + 5% + 2px)", want: "calc(12px + 5%)"},
+		{in: "calc(100% - 2 * 8px)", want: "calc(100% - 16px)"},
+		{in: "calc(100% - (10px + 6px))", want: "calc(100% - 16px)"},
+		{in: "calc((100% - 10px) / 3)", want: "calc((100% - 10px)/3)"},
+		{in: "calc(var(--gap) * 2 + 4px - 1px)", want: "calc(3px + var(--gap)*2)"},
+		{in: "calc(-1 * var(--x))", want: "calc(-1*var(--x))"},
+		{in: "min(10px, 20px, 5vw)", want: "min(10px,5vw)"},
+		{in: "max(10px, calc(5px * 4))", want: "20px"},
+		{in: "clamp(1rem, 3rem, 2rem)", want: "2rem"},
+		{in: "clamp(1rem, 5vw, 2rem)", want: "clamp(1rem,5vw,2rem)"},
+		{in: "calc(1px * 2px)", want: "calc(1px*2px)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := ParseCalc([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ParseCalc() error = %v", err)
+			}
+			if got := string(calcNodeBytes(c.Simplify())); got != tt.want {
+				t.Errorf("Calc.Simplify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimplifyMath(t *testing.T) {
+	s := Statements{
+		testRule([]string{"p"}, "margin:calc(4px * 2) calc(100% - 1px - 1px)", "padding:1px 2px", "width:calc(1px+2px) calc(1px -2px)"),
+	}
+	max, _ := ParseCalc([]byte("max(2px, 1px + 3px)"))
+	calc, _ := ParseCalc([]byte("calc(100% - 1px - 1px)"))
+	s[0].Ruleset.Declarations[1].Values[0].Tokens = []Token{{Index: 3, Calc: calc}, {Index: 1, Calc: max}}
+
+	SimplifyMath(s)
+
+	got, err := Encode(s)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := "p{margin:8px calc(100% - 2px);padding:1px 4px 2px calc(100% - 2px);width:calc(1px+2px) calc(1px -2px)}"
+	if string(got) != want {
+		t.Errorf("SimplifyMath() = %s, want %s", got, want)
+	}
+}
+
+func TestValue_Calc_JSON(t *testing.T) {
+	js := `{"values":["1px","auto"],"tokens":[{"index":1,"calc":{"func":"calc","args":[{"operator":"-","left":{"number":100,"unit":"%"},"right":{"raw":"var(--a)"}}]}}]}`
+
+	var v Value
+	if err := json.Unmarshal([]byte(js), &v); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if got, want := string(encodeBytes(&v)), "1px calc(100% - var(--a)) auto"; got != want {
+		t.Errorf("Value.encode() = %v, want %v", got, want)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(b) != js {
+		t.Errorf("json.Marshal() = %s, want %s", b, js)
+	}
+}
+
+func TestValue_Tokens_important(t *testing.T) {
+	calc, _ := ParseCalc([]byte("calc(100% - var(--a))"))
+	values := []Value{{ValueSpace: []TextBytes{TextBytes("1px")}, Tokens: []Token{{Index: 5, Calc: calc}}}}
+
+	imp := withImportant(values, true)
+	if got, want := string(valuesBytes(imp)), "1px calc(100% - var(--a)) !important"; got != want {
+		t.Errorf("withImportant() = %v, want %v", got, want)
+	}
+
+	got, ok := splitImportant(imp)
+	if !ok || string(valuesBytes(got)) != "1px calc(100% - var(--a))" {
+		t.Errorf("splitImportant() = %s, %v", valuesBytes(got), ok)
+	}
+}
@@ -0,0 +1,503 @@
+package css2json
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Origin of a style sheet https://www.w3.org/TR/css-cascade-5/#cascading-origins
+type Origin int
+
+// Origins
+const (
+	UserAgent Origin = iota
+	User
+	Author
+)
+
+// StyleSheet is statements of an origin
+type StyleSheet struct {
+	Origin     Origin
+	Statements Statements
+}
+
+// Style holds cascaded values by property
+type Style map[string][]Value
+
+// Declarations returns declarations of style sorted by property
+func (s Style) Declarations() []Declaration {
+	ret := make([]Declaration, 0, len(s))
+	for k, v := range s {
+		ret = append(ret, Declaration{Property: TextBytes(k), Values: v})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return string(ret[i].Property) < string(ret[j].Property)
+	})
+	return ret
+}
+
+// inheritedProperties inherit by default
+var inheritedProperties = keywords(
+	"border-collapse", "border-spacing", "caption-side", "color", "cursor", "direction", "empty-cells",
+	"font", "font-family", "font-feature-settings", "font-kerning", "font-size", "font-size-adjust",
+	"font-stretch", "font-style", "font-variant", "font-weight", "font-language-override",
+	"font-optical-sizing", "font-palette", "font-variation-settings", "font-variant-ligatures",
+	"font-variant-caps", "font-variant-alternates", "font-variant-numeric", "font-variant-east-asian",
+	"font-variant-position", "font-variant-emoji", "hyphens", "letter-spacing",
+	"line-height", "list-style", "list-style-image", "list-style-position", "list-style-type",
+	"orphans", "overflow-wrap", "quotes", "tab-size", "text-align", "text-align-last", "text-indent",
+	"text-justify", "text-shadow", "text-transform", "visibility", "white-space", "widows",
+	"word-break", "word-spacing", "word-wrap", "writing-mode", "-webkit-text-size-adjust",
+)
+
+// Cascade computes styles of elements from style sheets
+type Cascade struct {
+	Sheets []StyleSheet
+	// Media reports whether rules of @media apply, they are skipped when
+	// Media is nil
+	Media func(*MediaInformation) bool
+}
+
+// ComputeStyle returns cascaded values of element from author statements
+// and optional inline style, see Cascade.Compute
+func ComputeStyle(s Statements, node *html.Node, inline []Declaration) Style {
+	c := &Cascade{Sheets: []StyleSheet{{Origin: Author, Statements: s}}}
+	return c.Compute(node, inline)
+}
+
+// Compute returns cascaded values of element. Declarations are sorted by
+// origin and importance, inline style, layer, specificity and order of
+// appearance. Shorthands are expanded, so every property is a longhand with
+// one value, and logical properties like margin-inline-start are set as
+// physical ones by writing-mode and direction. Inherited properties, custom
+// properties and the inherit and unset keywords take values of the parent
+// element. Values are returned without !important. Nested rules match as
+// flattened by Flatten.
+func (c *Cascade) Compute(node *html.Node, inline []Declaration) Style {
+	cc := &cascadeContext{cascade: c, styles: map[*html.Node]Style{}}
+	cc.collect()
+
+	return cc.compute(node, inline)
+}
+
+type cascadeContext struct {
+	cascade *Cascade
+	rules   []cascadeRule
+	styles  map[*html.Node]Style
+}
+
+// cascadeRule is a ruleset with its place in the cascade
+type cascadeRule struct {
+	origin  Origin
+	layer   []int
+	order   int
+	ruleset *Ruleset
+}
+
+// cascadeRank orders declarations, a greater rank wins
+type cascadeRank struct {
+	important   bool
+	origin      Origin
+	inline      bool
+	layer       []int
+	specificity specificity
+	order       int
+}
+
+// originWeight https://www.w3.org/TR/css-cascade-5/#cascade-origin
+func (r *cascadeRank) originWeight() int {
+	if r.important {
+		return 5 - int(r.origin)
+	}
+	return int(r.origin)
+}
+
+func (r *cascadeRank) less(o *cascadeRank) bool {
+	if a, b := r.originWeight(), o.originWeight(); a != b {
+		return a < b
+	}
+	if r.inline != o.inline {
+		return o.inline
+	}
+	if cmp := compareLayers(r.layer, o.layer); cmp != 0 {
+		if r.important {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	if r.specificity != o.specificity {
+		return r.specificity.less(o.specificity)
+	}
+	return r.order < o.order
+}
+
+func compareLayers(a, b []int) int {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			if a[k] < b[k] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// layerTree orders layers by the first declaration, rules of a layer are
+// placed after its sublayers, unlayered rules after all layers
+type layerTree struct {
+	children map[string]*layerTree
+	index    int
+	count    int
+}
+
+func (t *layerTree) child(name string) *layerTree {
+	if t.children == nil {
+		t.children = map[string]*layerTree{}
+	}
+	if c, ok := t.children[name]; ok {
+		return c
+	}
+	c := &layerTree{index: t.count}
+	t.count++
+	t.children[name] = c
+	return c
+}
+
+type layerScope struct {
+	tree *layerTree
+	path []int
+}
+
+func (s layerScope) enter(name string) layerScope {
+	tree := s.tree
+	path := append([]int{}, s.path...)
+	for _, i := range strings.Split(name, ".") {
+		tree = tree.child(i)
+		path = append(path, tree.index)
+	}
+	return layerScope{tree: tree, path: path}
+}
+
+// rank returns path of rules placed directly in the layer
+func (s layerScope) rank() []int {
+	return append(append([]int{}, s.path...), math.MaxInt32)
+}
+
+func (cc *cascadeContext) collect() {
+	anonymous := 0
+	order := 0
+
+	var walk func(s []*Statement, origin Origin, scope layerScope)
+	walk = func(s []*Statement, origin Origin, scope layerScope) {
+		for _, v := range s {
+			if v.AtRule != nil {
+				switch info := v.AtRule.Identifier.Information.(type) {
+				case *LayerInformation:
+					if v.AtRule.Nested == nil {
+						for _, name := range info.Names {
+							scope.enter(string(name))
+						}
+						break
+					}
+					name := "\x00" + strconv.Itoa(anonymous)
+					if len(info.Names) > 0 {
+						name = string(info.Names[0])
+					} else {
+						anonymous++
+					}
+					walk(v.AtRule.Nested, origin, scope.enter(name))
+				case *MediaInformation:
+					if cc.cascade.Media != nil && cc.cascade.Media(info) {
+						walk(v.AtRule.Nested, origin, scope)
+					}
+				}
+			}
+			if v.Ruleset != nil {
+				cc.rules = append(cc.rules, cascadeRule{origin: origin, layer: scope.rank(), order: order, ruleset: v.Ruleset})
+				order++
+			}
+		}
+	}
+
+	for _, sheet := range cc.cascade.Sheets {
+		statements := withoutNesting(sheet.Statements)
+		nested := make([]*Statement, len(statements))
+		for k := range statements {
+			nested[k] = &statements[k]
+		}
+		walk(nested, sheet.Origin, layerScope{tree: &layerTree{}})
+	}
+}
+
+// cascadeValue is a declaration of a longhand in the cascade, index is
+// its place among declarations of the element, source is the declared
+// shorthand or longhand and logical is set when name was mapped to a
+// physical one
+type cascadeValue struct {
+	name    string
+	rank    cascadeRank
+	index   int
+	values  []Value
+	source  *Declaration
+	logical bool
+}
+
+func (cc *cascadeContext) compute(node *html.Node, inline []Declaration) Style {
+	winners := cc.declared(node, inline)
+
+	ret := Style{}
+	var parent Style
+	parentStyle := func() Style {
+		if parent == nil {
+			parent = Style{}
+			if p := parentElement(node); p != nil {
+				parent = cc.parentStyle(p)
+			}
+		}
+		return parent
+	}
+
+	for name, w := range winners {
+		switch keyword := cascadeKeyword(w.values); {
+		case keyword == "inherit" || keyword == "unset" && isInherited(name):
+			if v, ok := parentStyle()[name]; ok {
+				ret[name] = v
+			}
+		case keyword == "unset":
+		default:
+			ret[name] = w.values
+		}
+	}
+
+	for name, v := range parentStyle() {
+		if _, ok := winners[name]; !ok && isInherited(name) {
+			ret[name] = v
+		}
+	}
+
+	return ret
+}
+
+// declared returns the winning declarations of element by physical
+// longhand without inherited values
+func (cc *cascadeContext) declared(node *html.Node, inline []Declaration) map[string]*cascadeValue {
+	var declared []cascadeValue
+
+	apply := func(decls []Declaration, rank cascadeRank) {
+		for k := range decls {
+			expanded := &Ruleset{Declarations: []Declaration{decls[k]}}
+			expanded.ExpandShorthands()
+			for _, d := range expanded.Declarations {
+				values, important := splitImportant(d.Values)
+				r := rank
+				r.important = important
+				name := string(d.Property)
+				if !isCustomProperty(d.Property) {
+					name = strings.ToLower(name)
+				}
+				declared = append(declared, cascadeValue{name: name, rank: r, index: len(declared), values: values, source: &decls[k]})
+			}
+		}
+	}
+
+	for _, rule := range cc.rules {
+		var (
+			best    specificity
+			matched bool
+		)
+		for k := range rule.ruleset.Selectors {
+			if matchSelector(&rule.ruleset.Selectors[k], node, nil) {
+				var s specificity
+				s[0], s[1], s[2] = rule.ruleset.Selectors[k].Specificity()
+				if !matched || best.less(s) {
+					best = s
+				}
+				matched = true
+			}
+		}
+		if matched {
+			apply(rule.ruleset.Declarations, cascadeRank{origin: rule.origin, layer: rule.layer, specificity: best, order: rule.order})
+		}
+	}
+
+	if len(inline) > 0 {
+		apply(inline, cascadeRank{origin: Author, inline: true, layer: []int{math.MaxInt32}})
+	}
+
+	f := cc.flow(node, declared)
+	winners := map[string]*cascadeValue{}
+	for k := range declared {
+		d := &declared[k]
+		if name := f.physical(d.name); name != d.name {
+			d.name, d.logical = name, true
+		}
+		if w, ok := winners[d.name]; ok && d.rank.less(&w.rank) {
+			continue
+		}
+		winners[d.name] = d
+	}
+
+	return winners
+}
+
+// flow returns writing-mode and direction of element, they are inherited
+func (cc *cascadeContext) flow(node *html.Node, declared []cascadeValue) flow {
+	values := map[string]string{"writing-mode": "", "direction": ""}
+	for name := range values {
+		var winner *cascadeValue
+		for k := range declared {
+			if declared[k].name == name && (winner == nil || !declared[k].rank.less(&winner.rank)) {
+				winner = &declared[k]
+			}
+		}
+
+		keyword := "inherit"
+		if winner != nil {
+			keyword = strings.ToLower(string(valuesBytes(winner.values)))
+		}
+		if keyword == "inherit" || keyword == "unset" {
+			keyword = ""
+			if p := parentElement(node); p != nil {
+				keyword = strings.ToLower(string(valuesBytes(cc.parentStyle(p)[name])))
+			}
+		}
+		values[name] = keyword
+	}
+
+	return newFlow(values["writing-mode"], values["direction"])
+}
+
+// flow maps logical sides of an element to physical ones
+// https://www.w3.org/TR/css-writing-modes-4/#logical-to-physical
+type flow struct {
+	vertical bool
+	// block and inline are physical start and end sides
+	block, inline [2]string
+}
+
+func newFlow(writingMode, direction string) flow {
+	ret := flow{block: [2]string{"top", "bottom"}, inline: [2]string{"left", "right"}}
+	switch writingMode {
+	case "vertical-rl", "sideways-rl", "tb", "tb-rl":
+		ret = flow{vertical: true, block: [2]string{"right", "left"}, inline: [2]string{"top", "bottom"}}
+	case "vertical-lr":
+		ret = flow{vertical: true, block: [2]string{"left", "right"}, inline: [2]string{"top", "bottom"}}
+	case "sideways-lr":
+		ret = flow{vertical: true, block: [2]string{"left", "right"}, inline: [2]string{"bottom", "top"}}
+	}
+	if direction == "rtl" {
+		ret.inline[0], ret.inline[1] = ret.inline[1], ret.inline[0]
+	}
+	return ret
+}
+
+// flows are all writing modes with both directions
+var flows = func() []flow {
+	var ret []flow
+	for _, m := range []string{"horizontal-tb", "vertical-rl", "vertical-lr", "sideways-lr"} {
+		for _, d := range []string{"ltr", "rtl"} {
+			ret = append(ret, newFlow(m, d))
+		}
+	}
+	return ret
+}()
+
+var (
+	logicalSidePattern   = regexp.MustCompile(`^(margin|padding|inset|border)-(block|inline)-(start|end)(-width|-style|-color)?$`)
+	logicalCornerPattern = regexp.MustCompile(`^border-(start|end)-(start|end)-radius$`)
+	logicalSizePattern   = regexp.MustCompile(`^(min-|max-)?(block|inline)-size$`)
+)
+
+// physical returns the physical property set by a logical one, other
+// properties are returned as is
+func (f flow) physical(name string) string {
+	side := func(axis, edge string) string {
+		sides := f.inline
+		if axis == "block" {
+			sides = f.block
+		}
+		if edge == "end" {
+			return sides[1]
+		}
+		return sides[0]
+	}
+
+	if m := logicalSidePattern.FindStringSubmatch(name); m != nil {
+		if m[1] == "inset" {
+			return side(m[2], m[3])
+		}
+		return m[1] + "-" + side(m[2], m[3]) + m[4]
+	}
+
+	if m := logicalCornerPattern.FindStringSubmatch(name); m != nil {
+		vertical, horizontal := side("block", m[1]), side("inline", m[2])
+		if f.vertical {
+			vertical, horizontal = horizontal, vertical
+		}
+		return "border-" + vertical + "-" + horizontal + "-radius"
+	}
+
+	if m := logicalSizePattern.FindStringSubmatch(name); m != nil {
+		if (m[2] == "inline") != f.vertical {
+			return m[1] + "width"
+		}
+		return m[1] + "height"
+	}
+
+	switch name {
+	case "overflow-inline", "overflow-block":
+		if (name == "overflow-inline") != f.vertical {
+			return "overflow-x"
+		}
+		return "overflow-y"
+	}
+
+	return name
+}
+
+// physicalProperties returns every physical property a logical one may set
+func physicalProperties(name string) []string {
+	var ret []string
+	for _, f := range flows {
+		if p := f.physical(name); p != name && !containsString(ret, p) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// parentStyle computes style of ancestor with inline style from its style
+// attribute
+func (cc *cascadeContext) parentStyle(node *html.Node) Style {
+	if s, ok := cc.styles[node]; ok {
+		return s
+	}
+
+	var inline []Declaration
+	if style, ok := attribute(node, "style"); ok {
+		inline, _ = ParseDeclarations(style)
+	}
+
+	s := cc.compute(node, inline)
+	cc.styles[node] = s
+
+	return s
+}
+
+// cascadeKeyword returns lower case CSS-wide keyword of values or empty
+func cascadeKeyword(values []Value) string {
+	if _, ok := cssWideKeyword(values); !ok {
+		return ""
+	}
+	return strings.ToLower(string(values[0].ValueSpace[0]))
+}
+
+func isInherited(name string) bool {
+	return inheritedProperties[name] || isCustomProperty(TextBytes(name))
+}
@@ -0,0 +1,179 @@
+package css2json
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// testStyle writes style as "property:value" declarations sorted by property
+func testStyle(s Style) string {
+	b, err := EncodeDeclarations(s.Declarations())
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+func testLayer(names []string, nested ...Statement) Statement {
+	info := &LayerInformation{}
+	for _, n := range names {
+		info.Names = append(info.Names, TextBytes(n))
+	}
+	at := &AtRule{Identifier: Identifier{Type: TextBytes("layer"), Information: info}}
+	if len(nested) > 0 {
+		at.Nested = []*Statement{}
+	}
+	for k := range nested {
+		at.Nested = append(at.Nested, &nested[k])
+	}
+	return Statement{AtRule: at}
+}
+
+func TestCascade_Compute(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(
+		`<div id="main" class="box" style="color:navy;--gap:4px"><p class="note" lang="en">Hi</p></div>`,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := QueryAll(doc, []Selector{{Simple: Simple{Classes: []TextBytes{TextBytes("note")}}}})[0]
+
+	tests := []struct {
+		name   string
+		sheets []StyleSheet
+		media  func(*MediaInformation) bool
+		inline []Declaration
+		want   string
+	}{
+		{
+			name: "specificity and order",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testRule([]string{"div .note"}, "margin:1px"),
+				testRule([]string{"p"}, "margin:2px", "padding:0"),
+				testRule([]string{".note"}, "padding:1px"),
+			}}},
+			want: "--gap:4px;color:navy;margin-bottom:1px;margin-left:1px;margin-right:1px;margin-top:1px;padding-bottom:1px;padding-left:1px;padding-right:1px;padding-top:1px",
+		},
+		{
+			name: "origins and importance",
+			sheets: []StyleSheet{
+				{Origin: UserAgent, Statements: Statements{
+					testRule([]string{"p"}, "display:block !important", "margin-top:1em"),
+				}},
+				{Origin: User, Statements: Statements{
+					testRule([]string{"p"}, "font-size:20px !important"),
+				}},
+				{Origin: Author, Statements: Statements{
+					testRule([]string{"#main p"}, "display:none", "font-size:12px !important", "margin-top:0"),
+				}},
+			},
+			want: "--gap:4px;color:navy;display:block;font-size:20px;margin-top:0",
+		},
+		{
+			name: "inline style",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testRule([]string{"#main .note"}, "color:red", "width:10px !important"),
+			}}},
+			inline: testDeclarations("color:blue", "width:20px"),
+			want:   "--gap:4px;color:blue;width:10px",
+		},
+		{
+			name: "layers",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testLayer([]string{"base", "theme"}),
+				testLayer([]string{"theme"},
+					testRule([]string{"p"}, "color:green", "top:1px !important"),
+				),
+				testLayer([]string{"base"},
+					testRule([]string{"#main p.note"}, "color:red", "top:2px !important"),
+				),
+				testRule([]string{"p"}, "left:0"),
+				testLayer([]string{"theme"},
+					testRule([]string{"#main .note"}, "left:1px"),
+				),
+			}}},
+			want: "--gap:4px;color:green;left:0;top:2px",
+		},
+		{
+			name: "media",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testMedia("min-width", "800px", testRule([]string{"p"}, "width:50%")),
+				testMedia("print", "", testRule([]string{"p"}, "height:0")),
+			}}},
+			media: func(m *MediaInformation) bool {
+				return string(m.Queries[0].Conditions[0].Feature) == "min-width"
+			},
+			want: "--gap:4px;color:navy;width:50%",
+		},
+		{
+			name: "sub-shorthands",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testRule([]string{".note"}, "border-top:1px solid"),
+				testRule([]string{"p"}, "border-width:2px"),
+			}}},
+			want: "--gap:4px;border-bottom-width:2px;border-left-width:2px;border-right-width:2px;" +
+				"border-top-color:currentcolor;border-top-style:solid;border-top-width:1px;color:navy",
+		},
+		{
+			name: "logical properties",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testRule([]string{"div"}, "direction:rtl"),
+				testRule([]string{".note"}, "margin-inline:1px 2px"),
+				testRule([]string{"p"}, "margin-right:3px", "margin-top:3px", "inline-size:10px"),
+			}}},
+			want: "--gap:4px;color:navy;direction:rtl;margin-left:2px;margin-right:1px;margin-top:3px;width:10px",
+		},
+		{
+			name: "inheritance",
+			sheets: []StyleSheet{{Origin: Author, Statements: Statements{
+				testRule([]string{"div"}, "font:italic 12px serif", "border:1px solid", "--gap:8px"),
+				testRule([]string{"p"}, "border-top-style:inherit", "color:unset", "font-style:unset", "font-size:initial"),
+			}}},
+			want: "--gap:4px;border-top-style:solid;color:navy;font-family:serif;font-kerning:initial;" +
+				"font-language-override:initial;font-optical-sizing:initial;font-palette:initial;font-size:initial;" +
+				"font-size-adjust:initial;font-stretch:normal;font-style:italic;font-variant-alternates:normal;" +
+				"font-variant-caps:normal;font-variant-east-asian:normal;font-variant-emoji:normal;" +
+				"font-variant-ligatures:normal;font-variant-numeric:normal;font-variant-position:normal;" +
+				"font-variation-settings:initial;font-weight:normal;line-height:normal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cascade{Sheets: tt.sheets, Media: tt.media}
+			if got := testStyle(c.Compute(p, tt.inline)); got != tt.want {
+				t.Errorf("Cascade.Compute() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeStyle(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<ul><li>a</li><li class="last">b</li></ul>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	li := QueryAll(doc, []Selector{{Simple: Simple{Element: TextBytes("li")}}})
+
+	s := Statements{
+		testRule([]string{"ul"}, "list-style-type:square", "margin:0"),
+		testRule([]string{"li:last-child", "li.last"}, "opacity:0.5"),
+		testNested(testRule([]string{"ul"}),
+			testRule([]string{"> .last"}, "color:red"),
+		),
+	}
+
+	tests := []struct {
+		node *html.Node
+		want string
+	}{
+		{node: li[0], want: "list-style-type:square"},
+		{node: li[1], want: "color:red;list-style-type:square;opacity:0.5"},
+	}
+	for _, tt := range tests {
+		if got := testStyle(ComputeStyle(s, tt.node, nil)); got != tt.want {
+			t.Errorf("ComputeStyle() = %s, want %s", got, tt.want)
+		}
+	}
+}
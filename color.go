@@ -0,0 +1,831 @@
+package css2json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidColor
+	ErrInvalidColor = errors.New("invalid color")
+)
+
+// ColorSpace is a color space of Color channels
+type ColorSpace string
+
+// Color spaces https://www.w3.org/TR/css-color-4/
+const (
+	SRGB        ColorSpace = "srgb"
+	SRGBLinear  ColorSpace = "srgb-linear"
+	DisplayP3   ColorSpace = "display-p3"
+	A98RGB      ColorSpace = "a98-rgb"
+	ProPhotoRGB ColorSpace = "prophoto-rgb"
+	Rec2020     ColorSpace = "rec2020"
+	XYZD50      ColorSpace = "xyz-d50"
+	XYZD65      ColorSpace = "xyz-d65"
+	HSL         ColorSpace = "hsl"
+	HWB         ColorSpace = "hwb"
+	Lab         ColorSpace = "lab"
+	LCH         ColorSpace = "lch"
+	OKLab       ColorSpace = "oklab"
+	OKLCH       ColorSpace = "oklch"
+)
+
+// Color https://developer.mozilla.org/en-US/docs/Web/CSS/color_value
+//
+// Channels of RGB spaces are in 0..1, saturation, lightness, whiteness and
+// blackness of hsl and hwb and lightness of lab and lch are in 0..100,
+// hues are in degrees. Alpha is in 0..1, the color without it is opaque.
+type Color struct {
+	Space    ColorSpace
+	Channels [3]float64
+	Alpha    *float64
+}
+
+// alpha returns opacity of the color
+func (v Color) alpha() float64 {
+	if v.Alpha == nil {
+		return 1
+	}
+	return *v.Alpha
+}
+
+// explicitAlpha returns alpha for Color, nil when it is opaque
+func explicitAlpha(alpha float64) *float64 {
+	if alpha >= 1 {
+		return nil
+	}
+	return &alpha
+}
+
+type matrix [3][3]float64
+
+func (m matrix) mul(v [3]float64) [3]float64 {
+	return [3]float64{
+		m[0][0]*v[0] + m[0][1]*v[1] + m[0][2]*v[2],
+		m[1][0]*v[0] + m[1][1]*v[1] + m[1][2]*v[2],
+		m[2][0]*v[0] + m[2][1]*v[1] + m[2][2]*v[2],
+	}
+}
+
+func (m matrix) inverse() matrix {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+
+	return matrix{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det,
+		},
+	}
+}
+
+// linear RGB to XYZ matrices, prophoto-rgb is relative to D50
+var rgbToXYZ = map[ColorSpace]matrix{
+	SRGB: {
+		{0.41239079926595934, 0.357584339383878, 0.1804807884018343},
+		{0.21263900587151027, 0.715168678767756, 0.07219231536073371},
+		{0.01933081871559182, 0.11919477979462598, 0.9505321522496607},
+	},
+	DisplayP3: {
+		{0.4865709486482162, 0.26566769316909306, 0.1982172852343625},
+		{0.2289745640697488, 0.6917385218365064, 0.079286914093745},
+		{0, 0.04511338185890264, 1.043944368900976},
+	},
+	A98RGB: {
+		{0.5766690429101305, 0.1855582379065463, 0.1882286462349947},
+		{0.29734497525053605, 0.6273635662554661, 0.07529145849399788},
+		{0.02703136138641234, 0.07068885253582723, 0.9913375368376388},
+	},
+	ProPhotoRGB: {
+		{0.7977604896723027, 0.13518583717574031, 0.0313493495815248},
+		{0.2880711282292934, 0.7118432178101014, 0.00008565396060525902},
+		{0, 0, 0.8251046025104601},
+	},
+	Rec2020: {
+		{0.6369580483012914, 0.14461690358620832, 0.1688809751641721},
+		{0.2627002120112671, 0.6779980715188708, 0.05930171646986196},
+		{0, 0.028072693049087428, 1.060985057710791},
+	},
+}
+
+var xyzToRGB = map[ColorSpace]matrix{}
+
+var (
+	d65ToD50 = matrix{
+		{1.0479298208405488, 0.022946793341019088, -0.05019222954313557},
+		{0.029627815688159344, 0.990434484573249, -0.01707382502938514},
+		{-0.009243058152591178, 0.015055144896577895, 0.7518742899580008},
+	}
+	d50ToD65 = d65ToD50.inverse()
+
+	xyzToLMS = matrix{
+		{0.8190224379967030, 0.3619062600528904, -0.1288737815209879},
+		{0.0329836539323885, 0.9292868615863434, 0.0361446663506424},
+		{0.0481771893596242, 0.2642395317527308, 0.6335478284694309},
+	}
+	lmsToXYZ = xyzToLMS.inverse()
+
+	lmsToOKLab = matrix{
+		{0.2104542683093140, 0.7936177747023054, -0.0040720430116193},
+		{1.9779985324311684, -2.4285922420485799, 0.4505937096174110},
+		{0.0259040424655478, 0.7827717124575296, -0.8086757549230774},
+	}
+	okLabToLMS = lmsToOKLab.inverse()
+
+	whiteD50 = [3]float64{0.3457 / 0.3585, 1, (1 - 0.3457 - 0.3585) / 0.3585}
+)
+
+// colorNames is the shortest name of a rgb color
+var colorNames = map[uint32]string{}
+
+func init() {
+	for k, m := range rgbToXYZ {
+		xyzToRGB[k] = m.inverse()
+	}
+	rgbToXYZ[SRGBLinear] = rgbToXYZ[SRGB]
+	xyzToRGB[SRGBLinear] = xyzToRGB[SRGB]
+
+	names := make([]string, 0, len(namedColors))
+	for k := range namedColors {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rgb := namedColors[name]
+		if old, ok := colorNames[rgb]; !ok || len(name) < len(old) {
+			colorNames[rgb] = name
+		}
+	}
+}
+
+// ParseColor parses hex, named, rgb(), rgba(), hsl(), hsla(), hwb(), lab(),
+// lch(), oklab(), oklch() and color() colors
+func ParseColor(b []byte) (*Color, error) {
+	s := strings.ToLower(string(bytes.TrimSpace(b)))
+
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s[1:])
+	}
+
+	if s == "transparent" {
+		return &Color{Space: SRGB, Alpha: explicitAlpha(0)}, nil
+	}
+
+	if rgb, ok := namedColors[s]; ok {
+		return rgbColor(rgb, 1), nil
+	}
+
+	open := strings.IndexByte(s, leftParenthesis)
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return nil, ErrInvalidColor
+	}
+
+	args, err := colorArguments(s[open+1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Color{}
+	if len(args) == 4 {
+		var alpha float64
+		if alpha, err = parseAlpha(args[3]); err != nil {
+			return nil, err
+		}
+		ret.Alpha = explicitAlpha(alpha)
+		args = args[:3]
+	}
+
+	switch name := s[:open]; name {
+	case "rgb", "rgba":
+		ret.Space = SRGB
+		err = parseChannels(ret, args, 255, 255, 255)
+		for k := range ret.Channels {
+			ret.Channels[k] /= 255
+		}
+	case "hsl", "hsla":
+		ret.Space = HSL
+		err = parseChannels(ret, args, -1, 100, 100)
+	case "hwb":
+		ret.Space = HWB
+		err = parseChannels(ret, args, -1, 100, 100)
+	case "lab":
+		ret.Space = Lab
+		err = parseChannels(ret, args, 100, 125, 125)
+	case "lch":
+		ret.Space = LCH
+		err = parseChannels(ret, args, 100, 150, -1)
+	case "oklab":
+		ret.Space = OKLab
+		err = parseChannels(ret, args, 1, 0.4, 0.4)
+	case "oklch":
+		ret.Space = OKLCH
+		err = parseChannels(ret, args, 1, 0.4, -1)
+	case "color":
+		fields := strings.Fields(args[0])
+		if len(fields) != 2 {
+			return nil, ErrInvalidColor
+		}
+		ret.Space = ColorSpace(fields[0])
+		if ret.Space == "xyz" {
+			ret.Space = XYZD65
+		}
+		if _, ok := rgbToXYZ[ret.Space]; !ok && ret.Space != XYZD50 && ret.Space != XYZD65 {
+			return nil, ErrInvalidColor
+		}
+		args[0] = fields[1]
+		err = parseChannels(ret, args, 1, 1, 1)
+	default:
+		return nil, ErrInvalidColor
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func parseHexColor(s string) (*Color, error) {
+	switch len(s) {
+	case 3, 4:
+		var long []byte
+		for k := range s {
+			long = append(long, s[k], s[k])
+		}
+		s = string(long)
+	case 6, 8:
+	default:
+		return nil, ErrInvalidColor
+	}
+
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, ErrInvalidColor
+	}
+
+	if len(s) == 6 {
+		return rgbColor(uint32(n), 1), nil
+	}
+
+	return rgbColor(uint32(n>>8), float64(n&0xff)/255), nil
+}
+
+func rgbColor(rgb uint32, alpha float64) *Color {
+	return &Color{
+		Space: SRGB,
+		Channels: [3]float64{
+			float64(rgb>>16&0xff) / 255,
+			float64(rgb>>8&0xff) / 255,
+			float64(rgb&0xff) / 255,
+		},
+		Alpha: explicitAlpha(alpha),
+	}
+}
+
+// colorArguments splits arguments of a color function in three channels
+// and an optional alpha, the first argument of color() keeps the space name
+func colorArguments(s string) ([]string, error) {
+	var ret []string
+
+	if strings.Contains(s, ",") {
+		for _, i := range strings.Split(s, ",") {
+			ret = append(ret, strings.TrimSpace(i))
+		}
+	} else {
+		parts := strings.Split(s, "/")
+		if len(parts) > 2 {
+			return nil, ErrInvalidColor
+		}
+		ret = strings.Fields(parts[0])
+		if len(ret) == 4 {
+			ret = append([]string{ret[0] + " " + ret[1]}, ret[2:]...)
+		}
+		if len(parts) == 2 {
+			ret = append(ret, strings.TrimSpace(parts[1]))
+		}
+	}
+
+	if len(ret) < 3 || len(ret) > 4 {
+		return nil, ErrInvalidColor
+	}
+
+	return ret, nil
+}
+
+// parseChannels parses numbers or percentages scaled by percent,
+// negative percent means a hue
+func parseChannels(c *Color, args []string, percent ...float64) error {
+	for k, i := range args {
+		var err error
+		if percent[k] < 0 {
+			c.Channels[k], err = parseHue(i)
+		} else {
+			c.Channels[k], err = parseColorNumber(i, percent[k])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseColorNumber(s string, percent float64) (float64, error) {
+	if s == "none" {
+		return 0, nil
+	}
+
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = s[:len(s)-1]
+		scale = percent / 100
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrInvalidColor
+	}
+
+	return n * scale, nil
+}
+
+func parseAlpha(s string) (float64, error) {
+	n, err := parseColorNumber(s, 1)
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(0, math.Min(1, n)), nil
+}
+
+func parseHue(s string) (float64, error) {
+	if s == "none" {
+		return 0, nil
+	}
+
+	scale := 1.0
+	for _, unit := range []string{"deg", "grad", "rad", "turn"} {
+		if strings.HasSuffix(s, unit) {
+			s = s[:len(s)-len(unit)]
+			scale = canonicalUnits[unit].ratio
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrInvalidColor
+	}
+
+	return normalizeHue(n * scale), nil
+}
+
+func normalizeHue(h float64) float64 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// Convert returns the color in another color space, channels are not
+// clipped to the gamut of the space
+func (v Color) Convert(space ColorSpace) Color {
+	if v.Space == space {
+		return v
+	}
+
+	ret := Color{Space: space, Alpha: v.Alpha}
+
+	switch {
+	case space == SRGB && (v.Space == HSL || v.Space == HWB):
+		ret.Channels = v.srgb()
+	case v.Space == SRGB && space == HSL:
+		ret.Channels = srgbToHSL(v.Channels)
+	case v.Space == SRGB && space == HWB:
+		ret.Channels = srgbToHWB(v.Channels)
+	default:
+		ret.Channels = fromXYZ(space, v.xyz())
+	}
+
+	return ret
+}
+
+// srgb returns channels of hsl or hwb color in srgb space
+func (v Color) srgb() [3]float64 {
+	switch v.Space {
+	case HSL:
+		return hslToSRGB(v.Channels[0], v.Channels[1]/100, v.Channels[2]/100)
+	case HWB:
+		white, black := v.Channels[1]/100, v.Channels[2]/100
+		if white+black >= 1 {
+			gray := white / (white + black)
+			return [3]float64{gray, gray, gray}
+		}
+		ret := hslToSRGB(v.Channels[0], 1, 0.5)
+		for k := range ret {
+			ret[k] = ret[k]*(1-white-black) + white
+		}
+		return ret
+	}
+	return v.Convert(SRGB).Channels
+}
+
+// xyz returns the color in xyz-d65
+func (v Color) xyz() [3]float64 {
+	c := v.Channels
+
+	switch v.Space {
+	case XYZD65:
+		return c
+	case XYZD50:
+		return d50ToD65.mul(c)
+	case HSL, HWB:
+		return rgbToXYZ[SRGB].mul(mapChannels(v.srgb(), srgbToLinear))
+	case SRGB, DisplayP3:
+		return rgbToXYZ[v.Space].mul(mapChannels(c, srgbToLinear))
+	case SRGBLinear:
+		return rgbToXYZ[SRGB].mul(c)
+	case A98RGB:
+		return rgbToXYZ[A98RGB].mul(mapChannels(c, func(x float64) float64 {
+			return signedPow(x, 563.0/256)
+		}))
+	case ProPhotoRGB:
+		return d50ToD65.mul(rgbToXYZ[ProPhotoRGB].mul(mapChannels(c, func(x float64) float64 {
+			if math.Abs(x) <= 16.0/512 {
+				return x / 16
+			}
+			return signedPow(x, 1.8)
+		})))
+	case Rec2020:
+		return rgbToXYZ[Rec2020].mul(mapChannels(c, func(x float64) float64 {
+			const a, b = 1.09929682680944, 0.018053968510807
+			if math.Abs(x) < b*4.5 {
+				return x / 4.5
+			}
+			return math.Copysign(math.Pow((math.Abs(x)+a-1)/a, 1/0.45), x)
+		}))
+	case Lab, LCH:
+		lab := c
+		if v.Space == LCH {
+			lab = polarToLab(c)
+		}
+		return d50ToD65.mul(labToXYZD50(lab))
+	case OKLab, OKLCH:
+		lab := c
+		if v.Space == OKLCH {
+			lab = polarToLab(c)
+		}
+		lms := okLabToLMS.mul(lab)
+		return lmsToXYZ.mul(mapChannels(lms, func(x float64) float64 {
+			return x * x * x
+		}))
+	}
+
+	return c
+}
+
+func fromXYZ(space ColorSpace, xyz [3]float64) [3]float64 {
+	switch space {
+	case XYZD65:
+		return xyz
+	case XYZD50:
+		return d65ToD50.mul(xyz)
+	case HSL, HWB:
+		rgb := mapChannels(xyzToRGB[SRGB].mul(xyz), linearToSRGB)
+		if space == HSL {
+			return srgbToHSL(rgb)
+		}
+		return srgbToHWB(rgb)
+	case SRGB, DisplayP3:
+		return mapChannels(xyzToRGB[space].mul(xyz), linearToSRGB)
+	case SRGBLinear:
+		return xyzToRGB[SRGB].mul(xyz)
+	case A98RGB:
+		return mapChannels(xyzToRGB[A98RGB].mul(xyz), func(x float64) float64 {
+			return signedPow(x, 256.0/563)
+		})
+	case ProPhotoRGB:
+		return mapChannels(xyzToRGB[ProPhotoRGB].mul(d65ToD50.mul(xyz)), func(x float64) float64 {
+			if math.Abs(x) >= 1.0/512 {
+				return signedPow(x, 1/1.8)
+			}
+			return 16 * x
+		})
+	case Rec2020:
+		return mapChannels(xyzToRGB[Rec2020].mul(xyz), func(x float64) float64 {
+			const a, b = 1.09929682680944, 0.018053968510807
+			if math.Abs(x) > b {
+				return math.Copysign(a*math.Pow(math.Abs(x), 0.45)-(a-1), x)
+			}
+			return 4.5 * x
+		})
+	case Lab, LCH:
+		lab := xyzD50ToLab(d65ToD50.mul(xyz))
+		if space == LCH {
+			return labToPolar(lab, 0.0015)
+		}
+		return lab
+	case OKLab, OKLCH:
+		lab := lmsToOKLab.mul(mapChannels(xyzToLMS.mul(xyz), math.Cbrt))
+		if space == OKLCH {
+			return labToPolar(lab, 0.000004)
+		}
+		return lab
+	}
+
+	return xyz
+}
+
+func mapChannels(c [3]float64, f func(float64) float64) [3]float64 {
+	return [3]float64{f(c[0]), f(c[1]), f(c[2])}
+}
+
+func signedPow(x, p float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(x), p), x)
+}
+
+func srgbToLinear(x float64) float64 {
+	if math.Abs(x) <= 0.04045 {
+		return x / 12.92
+	}
+	return math.Copysign(math.Pow((math.Abs(x)+0.055)/1.055, 2.4), x)
+}
+
+func linearToSRGB(x float64) float64 {
+	if math.Abs(x) > 0.0031308 {
+		return math.Copysign(1.055*math.Pow(math.Abs(x), 1/2.4)-0.055, x)
+	}
+	return 12.92 * x
+}
+
+func labToXYZD50(lab [3]float64) [3]float64 {
+	const kappa, epsilon = 24389.0 / 27, 216.0 / 24389
+
+	f1 := (lab[0] + 16) / 116
+	f0 := lab[1]/500 + f1
+	f2 := f1 - lab[2]/200
+
+	xyz := [3]float64{f0 * f0 * f0, 0, f2 * f2 * f2}
+	if xyz[0] <= epsilon {
+		xyz[0] = (116*f0 - 16) / kappa
+	}
+	if lab[0] > kappa*epsilon {
+		xyz[1] = math.Pow((lab[0]+16)/116, 3)
+	} else {
+		xyz[1] = lab[0] / kappa
+	}
+	if xyz[2] <= epsilon {
+		xyz[2] = (116*f2 - 16) / kappa
+	}
+
+	for k := range xyz {
+		xyz[k] *= whiteD50[k]
+	}
+
+	return xyz
+}
+
+func xyzD50ToLab(xyz [3]float64) [3]float64 {
+	const kappa, epsilon = 24389.0 / 27, 216.0 / 24389
+
+	var f [3]float64
+	for k := range xyz {
+		x := xyz[k] / whiteD50[k]
+		if x > epsilon {
+			f[k] = math.Cbrt(x)
+		} else {
+			f[k] = (kappa*x + 16) / 116
+		}
+	}
+
+	return [3]float64{116*f[1] - 16, 500 * (f[0] - f[1]), 200 * (f[1] - f[2])}
+}
+
+func labToPolar(lab [3]float64, achromatic float64) [3]float64 {
+	chroma := math.Hypot(lab[1], lab[2])
+	hue := 0.0
+	if chroma > achromatic {
+		hue = normalizeHue(math.Atan2(lab[2], lab[1]) * 180 / math.Pi)
+	}
+	return [3]float64{lab[0], chroma, hue}
+}
+
+func polarToLab(lch [3]float64) [3]float64 {
+	hue := lch[2] * math.Pi / 180
+	return [3]float64{lch[0], lch[1] * math.Cos(hue), lch[1] * math.Sin(hue)}
+}
+
+func hslToSRGB(hue, sat, light float64) [3]float64 {
+	f := func(n float64) float64 {
+		k := math.Mod(n+hue/30, 12)
+		a := sat * math.Min(light, 1-light)
+		return light - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+	}
+	return [3]float64{f(0), f(8), f(4)}
+}
+
+func srgbToHSL(rgb [3]float64) [3]float64 {
+	r, g, b := rgb[0], rgb[1], rgb[2]
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	hue, sat, light := 0.0, 0.0, (min+max)/2
+	d := max - min
+
+	if d != 0 {
+		if light != 0 && light != 1 {
+			sat = (max - light) / math.Min(light, 1-light)
+		}
+		switch max {
+		case r:
+			hue = (g - b) / d
+			if g < b {
+				hue += 6
+			}
+		case g:
+			hue = (b-r)/d + 2
+		default:
+			hue = (r-g)/d + 4
+		}
+		hue *= 60
+	}
+
+	if sat < 0 {
+		hue += 180
+		sat = math.Abs(sat)
+	}
+
+	return [3]float64{normalizeHue(hue), sat * 100, light * 100}
+}
+
+func srgbToHWB(rgb [3]float64) [3]float64 {
+	hsl := srgbToHSL(rgb)
+	white := math.Min(rgb[0], math.Min(rgb[1], rgb[2]))
+	black := 1 - math.Max(rgb[0], math.Max(rgb[1], rgb[2]))
+	return [3]float64{hsl[0], white * 100, black * 100}
+}
+
+// String serializes the color in the shortest equivalent form
+func (v Color) String() string {
+	rgb := v.Convert(SRGB)
+
+	bytes8, exact := to8bit(rgb.Channels)
+	if !exact {
+		return v.functional()
+	}
+
+	n := uint32(bytes8[0])<<16 | uint32(bytes8[1])<<8 | uint32(bytes8[2])
+	alpha := math.Round(v.alpha() * 255)
+
+	if math.Abs(v.alpha()*255-alpha) > colorTolerance {
+		return rgb.functional()
+	}
+
+	var candidates []string
+	if alpha == 255 {
+		candidates = append(candidates, fmt.Sprintf("#%06x", n))
+		if name, ok := colorNames[n]; ok {
+			candidates = append(candidates, name)
+		}
+	} else {
+		candidates = append(candidates, fmt.Sprintf("#%06x%02x", n, int(alpha)))
+	}
+
+	ret := candidates[0]
+	if short := shortHex(ret); short != "" {
+		ret = short
+	}
+	for _, i := range candidates[1:] {
+		if len(i) < len(ret) {
+			ret = i
+		}
+	}
+
+	return ret
+}
+
+// colorTolerance is a maximum difference on 0..255 scale of equivalent colors
+const colorTolerance = 1e-4
+
+func to8bit(c [3]float64) ([3]uint8, bool) {
+	var ret [3]uint8
+	for k, i := range c {
+		x := i * 255
+		r := math.Round(x)
+		if r < 0 || r > 255 || math.Abs(x-r) > colorTolerance {
+			return ret, false
+		}
+		ret[k] = uint8(r)
+	}
+	return ret, true
+}
+
+// shortHex returns #rgb form of #rrggbb or #rgba of #rrggbbaa if possible
+func shortHex(s string) string {
+	var ret = []byte{'#'}
+	for i := 1; i < len(s); i += 2 {
+		if s[i] != s[i+1] {
+			return ""
+		}
+		ret = append(ret, s[i])
+	}
+	return string(ret)
+}
+
+// functional serializes the color as a function of its space
+func (v Color) functional() string {
+	var (
+		name   string
+		format = []string{"", "", ""}
+		scale  = []float64{1, 1, 1}
+	)
+
+	switch v.Space {
+	case SRGB:
+		name = "rgb"
+		scale = []float64{255, 255, 255}
+	case HSL, HWB:
+		name = string(v.Space)
+		format = []string{"", "%", "%"}
+	case Lab, LCH, OKLab, OKLCH:
+		name = string(v.Space)
+	default:
+		name = "color"
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(name)
+	buf.WriteByte(leftParenthesis)
+	if name == "color" {
+		buf.WriteString(string(v.Space))
+		buf.WriteByte(space)
+	}
+	for k, i := range v.Channels {
+		if k > 0 {
+			buf.WriteByte(space)
+		}
+		buf.WriteString(shortNumber(i * scale[k]))
+		buf.WriteString(format[k])
+	}
+	if v.alpha() < 1 {
+		buf.WriteByte('/')
+		buf.WriteString(shortNumber(v.alpha()))
+	}
+	buf.WriteByte(rightParenthesis)
+
+	return buf.String()
+}
+
+// shortNumber formats a number without leading zero
+func shortNumber(f float64) string {
+	s := formatNumber(f)
+	switch {
+	case strings.HasPrefix(s, "0."):
+		return s[1:]
+	case strings.HasPrefix(s, "-0."):
+		return "-" + s[2:]
+	}
+	return s
+}
+
+func (v *Color) encode(dst *bytes.Buffer) error {
+	dst.WriteString(v.String())
+	return nil
+}
+
+// MarshalJSON marshal Color
+func (v Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON unmarshal Color
+func (v *Color) UnmarshalJSON(b []byte) error {
+	var a string
+	if err := json.Unmarshal(b, &a); err != nil {
+		return err
+	}
+
+	c, err := ParseColor([]byte(a))
+	if err != nil {
+		return err
+	}
+	*v = *c
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package css2json
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "#FF0000", want: "red"},
+		{in: "#abc", want: "#abc"},
+		{in: "#aabbcc80", want: "#aabbcc80"},
+		{in: "#ffffff", want: "#fff"},
+		{in: "DarkSlateGrey", want: "#2f4f4f"},
+		{in: "aqua", want: "#0ff"},
+		{in: "transparent", want: "#0000"},
+		{in: "rgb(210, 180, 140)", want: "tan"},
+		{in: "rgba(0, 0, 255, 0.5)", want: "rgb(0 0 255/.5)"},
+		{in: "rgb(100% 0% 0% / 20%)", want: "#f003"},
+		{in: "rgb(10.5 20 30)", want: "rgb(10.5 20 30)"},
+		{in: "hsl(0, 100%, 50%)", want: "red"},
+		{in: "hsla(120deg, 100%, 50%, 1)", want: "#0f0"},
+		{in: "hsl(.5turn 100% 50%)", want: "#0ff"},
+		{in: "hsl(120 50% 33%)", want: "hsl(120 50% 33%)"},
+		{in: "hwb(240 0% 0%)", want: "#00f"},
+		{in: "hwb(0 40% 60%)", want: "#666"},
+		{in: "lab(29.2345% 39.3825 20.0664)", want: "lab(29.2345 39.3825 20.0664)"},
+		{in: "lab(100 0 0)", want: "#fff"},
+		{in: "lch(0 0 0)", want: "#000"},
+		{in: "oklab(100% 0 0)", want: "#fff"},
+		{in: "oklch(0.7 0.1 200 / 50%)", want: "oklch(.7 .1 200/.5)"},
+		{in: "color(srgb 1 0 0)", want: "red"},
+		{in: "color(display-p3 1 1 1)", want: "#fff"},
+		{in: "color(display-p3 1 0 0)", want: "color(display-p3 1 0 0)"},
+		{in: "color(xyz 0.5 0.5 0.5)", want: "color(xyz-d65 .5 .5 .5)"},
+		{in: "color(foo 1 0 0)", wantErr: true},
+		{in: "rgb(1, 2)", wantErr: true},
+		{in: "#abcde", wantErr: true},
+		{in: "currentcolor", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColor([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseColor() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_Convert(t *testing.T) {
+	red, _ := ParseColor([]byte("red"))
+
+	tests := []struct {
+		space ColorSpace
+		want  [3]float64
+	}{
+		{space: SRGB, want: [3]float64{1, 0, 0}},
+		{space: SRGBLinear, want: [3]float64{1, 0, 0}},
+		{space: HSL, want: [3]float64{0, 100, 50}},
+		{space: HWB, want: [3]float64{0, 0, 0}},
+		{space: Lab, want: [3]float64{54.2905, 80.8049, 69.8910}},
+		{space: LCH, want: [3]float64{54.2905, 106.8372, 40.8577}},
+		{space: OKLab, want: [3]float64{0.6280, 0.2249, 0.1258}},
+		{space: OKLCH, want: [3]float64{0.6280, 0.2577, 29.2339}},
+		{space: DisplayP3, want: [3]float64{0.9175, 0.2003, 0.1386}},
+		{space: XYZD65, want: [3]float64{0.4124, 0.2126, 0.0193}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.space), func(t *testing.T) {
+			got := red.Convert(tt.space)
+			for k := range got.Channels {
+				if math.Abs(got.Channels[k]-tt.want[k]) > 1e-3 {
+					t.Fatalf("Color.Convert() = %v, want %v", got.Channels, tt.want)
+				}
+			}
+
+			back := got.Convert(SRGB)
+			if back.String() != "red" {
+				t.Errorf("Color.Convert() round trip = %v, want red", back)
+			}
+		})
+	}
+}
+
+func TestValue_Color(t *testing.T) {
+	c, _ := ParseColor([]byte("hsl(0 0% 100%)"))
+	d := Declaration{
+		Property: TextBytes("border"),
+		Values: []Value{
+			{
+				ValueSpace: []TextBytes{TextBytes("1px"), TextBytes("solid")},
+				Tokens:     []Token{{Index: 1, Color: c}},
+			},
+		},
+	}
+
+	if got, want := string(encodeBytes(&d)), "border:1px #fff solid"; got != want {
+		t.Errorf("Declaration.encode() = %v, want %v", got, want)
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"property":"border","values":[{"values":["1px","solid"],"tokens":[{"index":1,"color":"#fff"}]}]}`
+	if string(b) != want {
+		t.Errorf("json.Marshal() = %s, want %s", b, want)
+	}
+
+	var got Declaration
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got, want := string(encodeBytes(&got)), "border:1px #fff solid"; got != want {
+		t.Errorf("json.Unmarshal() = %v, want %v", got, want)
+	}
+}
+
+func TestColor_opaque(t *testing.T) {
+	c := Color{Space: SRGB, Channels: [3]float64{1, 0, 0}}
+	if got := c.String(); got != "red" {
+		t.Errorf("Color.String() = %v, want red", got)
+	}
+}
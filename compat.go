@@ -0,0 +1,200 @@
+package css2json
+
+// never is a version before which a browser needs a prefix it never dropped
+const never = 1e9
+
+// prefixed is a vendor prefixed name of a property, keyword, pseudo-class,
+// pseudo-element or at-rule with the first version of browsers supporting
+// the feature unprefixed. A browser missing in until doesn't need the name.
+type prefixed struct {
+	name  string
+	until map[string]float64
+}
+
+// prefixedKeyword is a keyword with prefixed variants in values of
+// properties
+type prefixedKeyword struct {
+	properties map[string]bool
+	variants   []prefixed
+}
+
+var (
+	transitionPrefixes = []prefixed{
+		{"-webkit-", map[string]float64{"chrome": 26, "safari": 7, "ios_saf": 7}},
+		{"-moz-", map[string]float64{"firefox": 16}},
+	}
+	transformPrefixes = []prefixed{
+		{"-webkit-", map[string]float64{"chrome": 36, "safari": 9, "ios_saf": 9}},
+		{"-ms-", map[string]float64{"ie": 10}},
+	}
+	animationPrefixes = []prefixed{
+		{"-webkit-", map[string]float64{"chrome": 43, "safari": 9, "ios_saf": 9}},
+		{"-moz-", map[string]float64{"firefox": 16}},
+	}
+	flexPrefixes = []prefixed{
+		{"-webkit-", map[string]float64{"chrome": 29, "safari": 9, "ios_saf": 9}},
+	}
+	columnPrefixes = []prefixed{
+		{"-webkit-", map[string]float64{"chrome": 50, "safari": 9, "ios_saf": 9}},
+		{"-moz-", map[string]float64{"firefox": 52}},
+	}
+	maskPrefixes = []prefixed{
+		{"-webkit-", map[string]float64{"chrome": 120, "edge": 120, "safari": 15.4, "ios_saf": 15.4}},
+	}
+	sizeProperties = keywords(
+		"width", "min-width", "max-width", "height", "min-height", "max-height",
+		"inline-size", "min-inline-size", "max-inline-size", "block-size", "min-block-size", "max-block-size",
+		"flex-basis",
+	)
+	webkitIntrinsicSize = map[string]float64{"chrome": 46, "safari": 11, "ios_saf": 11}
+	mozIntrinsicSize    = map[string]float64{"firefox": 66}
+)
+
+// prefixedProperties are properties with prefixed names, names of the
+// prefixed properties are the prefixes
+var prefixedProperties = map[string][]prefixed{
+	"transition":                 transitionPrefixes,
+	"transition-property":        transitionPrefixes,
+	"transition-duration":        transitionPrefixes,
+	"transition-timing-function": transitionPrefixes,
+	"transition-delay":           transitionPrefixes,
+	"transform":                  transformPrefixes,
+	"transform-origin":           transformPrefixes,
+	"animation":                  animationPrefixes,
+	"animation-name":             animationPrefixes,
+	"animation-duration":         animationPrefixes,
+	"animation-timing-function":  animationPrefixes,
+	"animation-delay":            animationPrefixes,
+	"animation-iteration-count":  animationPrefixes,
+	"animation-direction":        animationPrefixes,
+	"animation-fill-mode":        animationPrefixes,
+	"animation-play-state":       animationPrefixes,
+	"flex":                       flexPrefixes,
+	"flex-direction":             flexPrefixes,
+	"flex-wrap":                  flexPrefixes,
+	"flex-flow":                  flexPrefixes,
+	"flex-grow":                  flexPrefixes,
+	"flex-shrink":                flexPrefixes,
+	"flex-basis":                 flexPrefixes,
+	"order":                      flexPrefixes,
+	"justify-content":            flexPrefixes,
+	"align-items":                flexPrefixes,
+	"align-self":                 flexPrefixes,
+	"align-content":              flexPrefixes,
+	"columns":                    columnPrefixes,
+	"column-count":               columnPrefixes,
+	"column-gap":                 columnPrefixes,
+	"column-rule":                columnPrefixes,
+	"column-width":               columnPrefixes,
+	"mask":                       maskPrefixes,
+	"mask-image":                 maskPrefixes,
+	"mask-size":                  maskPrefixes,
+	"mask-position":              maskPrefixes,
+	"mask-repeat":                maskPrefixes,
+	"box-sizing": {
+		{"-webkit-", map[string]float64{"chrome": 10, "safari": 5.1, "ios_saf": 5}},
+		{"-moz-", map[string]float64{"firefox": 29}},
+	},
+	"appearance": {
+		{"-webkit-", map[string]float64{"chrome": 84, "edge": 84, "safari": 15.4, "ios_saf": 15.4}},
+		{"-moz-", map[string]float64{"firefox": 80}},
+	},
+	"user-select": {
+		{"-webkit-", map[string]float64{"chrome": 54, "safari": never, "ios_saf": never}},
+		{"-moz-", map[string]float64{"firefox": 69}},
+		{"-ms-", map[string]float64{"ie": never, "edge": 79}},
+	},
+	"backdrop-filter": {
+		{"-webkit-", map[string]float64{"safari": 18, "ios_saf": 18}},
+	},
+	"filter": {
+		{"-webkit-", map[string]float64{"chrome": 53, "safari": 9.1, "ios_saf": 9.3}},
+	},
+	"clip-path": {
+		{"-webkit-", map[string]float64{"chrome": 55, "edge": 79, "safari": 13.1, "ios_saf": 13.4}},
+	},
+	"hyphens": {
+		{"-webkit-", map[string]float64{"safari": 17, "ios_saf": 17}},
+		{"-ms-", map[string]float64{"ie": never, "edge": 79}},
+	},
+	"text-size-adjust": {
+		{"-webkit-", map[string]float64{"ios_saf": never}},
+	},
+	"box-decoration-break": {
+		{"-webkit-", map[string]float64{"chrome": 130, "edge": 130, "safari": never, "ios_saf": never}},
+	},
+	"font-feature-settings": {
+		{"-webkit-", map[string]float64{"chrome": 48}},
+		{"-moz-", map[string]float64{"firefox": 34}},
+	},
+	"tab-size": {
+		{"-moz-", map[string]float64{"firefox": 91}},
+	},
+}
+
+// prefixedKeywords are keywords with prefixed variants in values
+var prefixedKeywords = map[string]prefixedKeyword{
+	"flex": {keywords("display"), []prefixed{
+		{"-webkit-flex", map[string]float64{"chrome": 29, "safari": 9, "ios_saf": 9}},
+		{"-ms-flexbox", map[string]float64{"ie": 11}},
+	}},
+	"inline-flex": {keywords("display"), []prefixed{
+		{"-webkit-inline-flex", map[string]float64{"chrome": 29, "safari": 9, "ios_saf": 9}},
+		{"-ms-inline-flexbox", map[string]float64{"ie": 11}},
+	}},
+	"sticky": {keywords("position"), []prefixed{
+		{"-webkit-sticky", map[string]float64{"safari": 13, "ios_saf": 13}},
+	}},
+	"fit-content": {sizeProperties, []prefixed{
+		{"-webkit-fit-content", webkitIntrinsicSize},
+		{"-moz-fit-content", map[string]float64{"firefox": 94}},
+	}},
+	"min-content": {sizeProperties, []prefixed{
+		{"-webkit-min-content", webkitIntrinsicSize},
+		{"-moz-min-content", mozIntrinsicSize},
+	}},
+	"max-content": {sizeProperties, []prefixed{
+		{"-webkit-max-content", webkitIntrinsicSize},
+		{"-moz-max-content", mozIntrinsicSize},
+	}},
+}
+
+// prefixedPseudos are pseudo-elements and pseudo-classes written with
+// colons, a prefixed variant may be a pseudo-class of a pseudo-element
+var prefixedPseudos = map[string][]prefixed{
+	"::placeholder": {
+		{"::-webkit-input-placeholder", map[string]float64{"chrome": 57, "safari": 10.1, "ios_saf": 10.3}},
+		{"::-moz-placeholder", map[string]float64{"firefox": 51}},
+		{":-ms-input-placeholder", map[string]float64{"ie": never}},
+		{"::-ms-input-placeholder", map[string]float64{"edge": 79}},
+	},
+	"::selection": {
+		{"::-moz-selection", map[string]float64{"firefox": 62}},
+	},
+	"::file-selector-button": {
+		{"::-webkit-file-upload-button", map[string]float64{"chrome": 89, "edge": 89, "safari": 14.1, "ios_saf": 14.5}},
+	},
+	":fullscreen": {
+		{":-webkit-full-screen", map[string]float64{"chrome": 71, "safari": 16.4, "ios_saf": 16.4}},
+		{":-moz-full-screen", map[string]float64{"firefox": 64}},
+		{":-ms-fullscreen", map[string]float64{"ie": never, "edge": 79}},
+	},
+	":any-link": {
+		{":-webkit-any-link", map[string]float64{"chrome": 65, "safari": 9, "ios_saf": 9}},
+		{":-moz-any-link", map[string]float64{"firefox": 50}},
+	},
+	":read-only": {
+		{":-moz-read-only", map[string]float64{"firefox": 78}},
+	},
+	":read-write": {
+		{":-moz-read-write", map[string]float64{"firefox": 78}},
+	},
+}
+
+// prefixedAtRules are at-rules with prefixed names
+var prefixedAtRules = map[string][]prefixed{
+	"keyframes": {
+		{"-webkit-keyframes", map[string]float64{"chrome": 43, "safari": 9, "ios_saf": 9}},
+		{"-moz-keyframes", map[string]float64{"firefox": 16}},
+	},
+}
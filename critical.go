@@ -0,0 +1,85 @@
+package css2json
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// animationProperties may reference @keyframes by name
+var animationProperties = keywords("animation", "animation-name", "-webkit-animation", "-webkit-animation-name")
+
+// Critical returns the subset of statements needed for the first render of
+// document: rules with selectors matching its elements, @media blocks
+// holding such rules, @charset, @font-face and @keyframes used by the kept
+// rules. Selectors needing user interaction like :hover are removed,
+// pseudo-elements are matched by their element. Nested rules are returned
+// flattened.
+func Critical(s Statements, document *html.Node) Statements {
+	p := &purger{
+		used: func(sel *Selector) bool {
+			initial := Selector{Simple: withoutPseudoElements(sel.Simple)}
+			for _, c := range sel.Combinates {
+				initial.Combinates = append(initial.Combinates, Combinate{Combinator: c.Combinator, Simple: withoutPseudoElements(c.Simple)})
+			}
+			return matchDocument(&initial, document)
+		},
+	}
+
+	ret := p.statements(withoutNesting(s))
+
+	names := map[string]bool{}
+	collectAnimationNames(ret, names)
+
+	return withKeyframes(ret, names)
+}
+
+func withoutPseudoElements(v Simple) Simple {
+	v.PseudoElements = nil
+	return v
+}
+
+func collectAnimationNames(s Statements, dst map[string]bool) {
+	Walk(s, Visitor{
+		EnterDeclaration: func(d *Declaration) Action {
+			if animationProperties[strings.ToLower(string(d.Property))] {
+				for _, v := range d.Values {
+					for _, i := range v.ValueSpace {
+						dst[string(i)] = true
+					}
+				}
+			}
+			return Skip
+		},
+	})
+}
+
+// withKeyframes removes @keyframes not in names, @media left empty is
+// removed too
+func withKeyframes(s Statements, names map[string]bool) Statements {
+	var ret Statements
+
+	for _, v := range s {
+		if v.AtRule != nil {
+			if info, ok := v.AtRule.Identifier.Information.(*KeyframesInformation); ok && !names[string(info.Value)] {
+				v.AtRule = nil
+			} else if v.AtRule.Nested != nil && !ok {
+				at := &AtRule{Identifier: v.AtRule.Identifier, Nested: []*Statement{}}
+				for _, i := range v.AtRule.Nested {
+					for _, n := range withKeyframes(Statements{*i}, names) {
+						at.Nested = append(at.Nested, &n)
+					}
+				}
+				v.AtRule = at
+				if len(at.Nested) == 0 {
+					v.AtRule = nil
+				}
+			}
+		}
+		if v.AtRule != nil || v.Ruleset != nil {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
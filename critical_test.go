@@ -0,0 +1,76 @@
+package css2json
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestCritical(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<header class="hero"><h1>Hi</h1><a class="cta" href="/">Go</a></header>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyframes := func(name string) Statement {
+		from := testRule([]string{"from"}, "opacity:0")
+		return Statement{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type:        TextBytes("keyframes"),
+					Information: &KeyframesInformation{Value: TextBytes(name)},
+				},
+				Nested: []*Statement{&from},
+			},
+		}
+	}
+
+	s := Statements{
+		{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type:        TextBytes("charset"),
+					Information: &CharsetInformation{Value: TextBytes("utf-8")},
+				},
+			},
+		},
+		{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type: TextBytes("font-face"),
+					Information: &FontFaceInformation{
+						Declarations: testDeclarations(`font-family:"Inter"`, "src:url(inter.woff2)"),
+					},
+				},
+			},
+		},
+		testRule([]string{".hero h1", ".footer"}, "animation:fade-in 1s ease"),
+		testRule([]string{".cta:hover", ".cta::after"}, "content:'>'"),
+		testRule([]string{".modal"}, "animation-name:slide"),
+		testMedia("min-width", "800px",
+			testRule([]string{".hero"}, "padding:4rem"),
+		),
+		testMedia("print",
+			"",
+			testRule([]string{".footer"}, "display:none"),
+		),
+		keyframes("fade-in"),
+		keyframes("slide"),
+	}
+
+	got, err := Encode(Critical(s, doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `@charset "utf-8";` +
+		`@font-face {font-family:"Inter";src:url(inter.woff2)}` +
+		`.hero h1{animation:fade-in 1s ease}` +
+		`.cta::after{content:'>'}` +
+		`@media (min-width:800px){.hero{padding:4rem}}` +
+		`@keyframes fade-in{from{opacity:0}}`
+	if string(got) != want {
+		t.Errorf("Critical() = %s, want %s", got, want)
+	}
+}
@@ -4,13 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 const (
 	space              = 32
 	doubleQuote        = 34
+	numberSign         = 35
+	ampersand          = 38
 	leftParenthesis    = 40
 	rightParenthesis   = 41
+	asterisk           = 42
 	comma              = 44
 	period             = 46
 	colon              = 58
@@ -30,6 +37,14 @@ var (
 	ErrNotExistsDeclaration = errors.New("not exists declaration")
 	// ErrNotExistsTypeIdentifier
 	ErrNotExistsTypeIdentifier = errors.New("not exists type of identifier")
+	// ErrInvalidCombinator
+	ErrInvalidCombinator = errors.New("invalid combinator")
+	// ErrInvalidAttribute
+	ErrInvalidAttribute = errors.New("attribute selector without name")
+	// ErrInvalidAttributeOperator
+	ErrInvalidAttributeOperator = errors.New("invalid attribute operator")
+	// ErrInvalidAttributeModifier
+	ErrInvalidAttributeModifier = errors.New("invalid attribute modifier")
 )
 
 // Statements sets Statement
@@ -87,19 +102,26 @@ func (v *AtRule) encode(dst *bytes.Buffer) error {
 			}
 		}
 		dst.WriteByte(rightCurlyBracket)
+	} else if _, ok := v.Identifier.Information.(*LayerInformation); ok {
+		dst.WriteByte(semicolon)
 	}
 
 	return nil
 }
 
-// Ruleset is a collection of CSS declarations
+// Ruleset is a collection of CSS declarations. Nested holds nested rules
+// and at-rules of CSS Nesting, their selectors are relative to the rule or
+// use the nesting selector "&". Declarations directly in a nested at-rule
+// are kept in a ruleset with "&" selector.
+// https://www.w3.org/TR/css-nesting-1/
 type Ruleset struct {
 	Selectors    []Selector    `json:"selectors"`
 	Declarations []Declaration `json:"declarations"`
+	Nested       []*Statement  `json:"nested,omitempty"`
 }
 
 func (v *Ruleset) encode(dst *bytes.Buffer) error {
-	if len(v.Declarations) == 0 {
+	if len(v.Declarations) == 0 && len(v.Nested) == 0 {
 		return ErrNotExistsDeclaration
 	}
 
@@ -113,13 +135,16 @@ func (v *Ruleset) encode(dst *bytes.Buffer) error {
 	}
 
 	dst.WriteByte(leftCurlyBracket)
-	for idx, d := range v.Declarations {
-		if err := d.encode(dst); err != nil {
+	if err := encodeDeclarations(dst, v.Declarations); err != nil {
+		return err
+	}
+	if len(v.Declarations) > 0 && len(v.Nested) > 0 {
+		dst.WriteByte(semicolon)
+	}
+	for _, i := range v.Nested {
+		if err := i.encode(dst); err != nil {
 			return err
 		}
-		if len(v.Declarations)-1 > idx {
-			dst.WriteByte(semicolon)
-		}
 	}
 	dst.WriteByte(rightCurlyBracket)
 
@@ -148,9 +173,18 @@ func (v *Selector) encode(dst *bytes.Buffer) error {
 	return nil
 }
 
-// Simple is a simple selector
+// Simple is a simple selector. ID, universal and nesting selectors have
+// their own fields, IDs keeps one value per "#" as an ID may contain an
+// escaped "#", Element keeps the unescaped type selector with optional
+// namespace prefix ("svg|" for "svg|*"). Names are escaped when written to
+// CSS and "\", "#", "&" and "*" of Element are escaped in JSON, so Element
+// written by older versions like "#sidebar" or "*" is split in the fields by
+// UnmarshalJSON.
 type Simple struct {
 	Element        TextBytes   `json:"element,omitempty"`
+	Universal      bool        `json:"universal,omitempty"`
+	Nesting        bool        `json:"nesting,omitempty"`
+	IDs            []TextBytes `json:"ids,omitempty"`
 	Classes        []TextBytes `json:"classes,omitempty"`
 	Attributes     []Attribute `json:"attributes,omitempty"`
 	PseudoElements []Pseudo    `json:"pseudo_elements,omitempty"`
@@ -158,58 +192,157 @@ type Simple struct {
 	Negations      []Simple    `json:"negations,omitempty"`
 }
 
-// Encode to CSS
-func (v *Simple) encode(dst *bytes.Buffer) error {
-	if _, err := dst.Write(v.Element); err != nil {
+// MarshalJSON marshal Simple with escaped Element
+func (v Simple) MarshalJSON() ([]byte, error) {
+	type simple Simple
+	s := simple(v)
+	s.Element = escapeElement(v.Element)
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON unmarshal Simple
+func (v *Simple) UnmarshalJSON(b []byte) error {
+	type simple Simple
+	if err := json.Unmarshal(b, (*simple)(v)); err != nil {
 		return err
 	}
 
-	if len(v.Classes) > 0 {
+	element, universal, nesting, ids := splitElement(v.Element)
+	v.Element, v.Universal, v.Nesting = element, v.Universal || universal, v.Nesting || nesting
+	if len(ids) > 0 {
+		v.IDs = append(ids, v.IDs...)
+	}
+
+	return nil
+}
+
+// splitElement splits escaped Element like "div#main", "*" or "&" in type
+// selector, universal, nesting and IDs. Escaped characters are a part of the
+// name, returned names are unescaped.
+func splitElement(element TextBytes) (TextBytes, bool, bool, []TextBytes) {
+	var (
+		universal, nesting bool
+		ids                []TextBytes
+	)
+
+	for k := len(element) - 1; k >= 0; k-- {
+		if element[k] == numberSign && !isEscaped(element, k) {
+			ids = append([]TextBytes{unescape(element[k+1:])}, ids...)
+			element = element[:k]
+		}
+	}
+
+	if len(element) > 0 && element[0] == '&' {
+		nesting, element = true, element[1:]
+	} else if n := len(element) - 1; n >= 0 && element[n] == '&' && !isEscaped(element, n) {
+		nesting, element = true, element[:n]
+	}
+
+	if n := len(element) - 1; n >= 0 && element[n] == '*' && !isEscaped(element, n) {
+		universal, element = true, element[:n]
+	}
+
+	if len(element) == 0 {
+		return nil, universal, nesting, ids
+	}
+
+	return unescape(element), universal, nesting, ids
+}
+
+// Encode to CSS, names are escaped and keyframe selectors like "50%" in
+// Element are written as is
+func (v *Simple) encode(dst *bytes.Buffer) error {
+	if isKeyframeSelector(v.Element) {
+		dst.Write(v.Element)
+	} else if len(v.Element) > 0 {
+		serializeName(dst, v.Element)
+	}
+
+	if v.Universal {
+		dst.WriteByte(asterisk)
+	}
+
+	if v.Nesting {
+		dst.WriteByte(ampersand)
+	}
+
+	for _, i := range v.IDs {
+		dst.WriteByte(numberSign)
+		serializeIdentifier(dst, i)
+	}
+
+	for _, c := range v.Classes {
 		dst.WriteByte(period)
-		dst.Write(bytes.Join(sliceValuesRaw(v.Classes), []byte{period}))
+		serializeIdentifier(dst, c)
 	}
 
-	if len(v.Attributes) > 0 {
-		for _, a := range v.Attributes {
-			a.encode(dst)
+	for _, a := range v.Attributes {
+		if err := a.encode(dst); err != nil {
+			return err
 		}
 	}
 
-	if len(v.PseudoElements) > 0 {
-		for _, p := range v.PseudoElements {
-			dst.Write([]byte{colon, colon})
-			p.encode(dst)
+	for _, p := range v.PseudoElements {
+		dst.Write([]byte{colon, colon})
+		if err := p.encode(dst); err != nil {
+			return err
 		}
 	}
 
-	if len(v.PseudoClasses) > 0 {
-		for _, p := range v.PseudoClasses {
-			dst.WriteByte(colon)
-			p.encode(dst)
+	for _, p := range v.PseudoClasses {
+		dst.WriteByte(colon)
+		if err := p.encode(dst); err != nil {
+			return err
 		}
 	}
 
-	if len(v.Negations) > 0 {
-		for _, s := range v.Negations {
-			dst.Write([]byte{colon, smallN, smallO, smallT, leftParenthesis})
-			s.encode(dst)
-			dst.WriteByte(rightParenthesis)
+	for _, s := range v.Negations {
+		dst.Write([]byte{colon, smallN, smallO, smallT, leftParenthesis})
+		if err := s.encode(dst); err != nil {
+			return err
 		}
+		dst.WriteByte(rightParenthesis)
 	}
 
 	return nil
 }
 
-// Pseudo is a pseudo-class
+// Pseudo is a pseudo-class. Functional pseudo-classes taking a selector
+// list like :is(), :where(), :has() and :not() keep the arguments in
+// Selectors, :nth-child() and the like keep An+B in Nth and "of S" in
+// Selectors, other arguments are kept raw in Func.
 type Pseudo struct {
-	Ident TextBytes `json:"ident,omitempty"`
-	Func  TextBytes `json:"func,omitempty"`
+	Ident     TextBytes  `json:"ident,omitempty"`
+	Func      TextBytes  `json:"func,omitempty"`
+	Nth       *Nth       `json:"nth,omitempty"`
+	Selectors []Selector `json:"selectors,omitempty"`
 }
 
 // Encode to CSS
 func (v *Pseudo) encode(dst *bytes.Buffer) error {
-	if _, err := dst.Write(v.Ident); err != nil {
-		return err
+	serializeIdentifier(dst, v.Ident)
+
+	if v.Nth != nil || len(v.Selectors) > 0 {
+		dst.WriteByte(leftParenthesis)
+		if v.Nth != nil {
+			if err := v.Nth.encode(dst); err != nil {
+				return err
+			}
+			if len(v.Selectors) > 0 {
+				dst.WriteString(" of ")
+			}
+		}
+		for idx, s := range v.Selectors {
+			if err := s.encode(dst); err != nil {
+				return err
+			}
+			if len(v.Selectors)-1 > idx {
+				dst.WriteByte(comma)
+			}
+		}
+		dst.WriteByte(rightParenthesis)
+
+		return nil
 	}
 
 	if len(v.Func) > 0 {
@@ -231,30 +364,54 @@ type Attribute struct {
 	Modifier TextBytes `json:"modifier,omitempty"`
 }
 
-func (v *Attribute) encode(dst *bytes.Buffer) error {
-	dst.WriteByte(leftSquareBracket)
+// attributeOperators https://www.w3.org/TR/selectors-4/#attribute-selectors
+var attributeOperators = keywords("=", "~=", "|=", "^=", "$=", "*=")
 
-	if _, err := dst.Write(v.Attr); err != nil {
-		return err
+// Validate checks name, operator and modifier of attribute
+func (v *Attribute) Validate() error {
+	if len(v.Attr) == 0 {
+		return ErrInvalidAttribute
 	}
 
-	if _, err := dst.Write(v.Operator); err != nil {
-		return err
+	if len(v.Operator) > 0 && !attributeOperators[string(v.Operator)] {
+		return fmt.Errorf("%w %q in [%s], want one of = ~= |= ^= $= *=", ErrInvalidAttributeOperator, v.Operator, v.Attr)
 	}
 
-	if len(v.Value) > 0 {
-		dst.WriteByte(doubleQuote)
-		if _, err := dst.Write(v.Value); err != nil {
-			return err
+	if len(v.Operator) == 0 && len(v.Value) > 0 {
+		return fmt.Errorf("%w: value %q of [%s] without operator", ErrInvalidAttributeOperator, v.Value, v.Attr)
+	}
+
+	if len(v.Modifier) > 0 {
+		if len(v.Operator) == 0 {
+			return fmt.Errorf("%w: %q of [%s] without operator", ErrInvalidAttributeModifier, v.Modifier, v.Attr)
+		}
+		if m := strings.ToLower(string(v.Modifier)); m != "i" && m != "s" {
+			return fmt.Errorf("%w %q in [%s], want i or s", ErrInvalidAttributeModifier, v.Modifier, v.Attr)
 		}
-		dst.WriteByte(doubleQuote)
+	}
+
+	return nil
+}
+
+// encode writes attribute, a value of operator is always quoted, so an
+// empty Value is written as ""
+func (v *Attribute) encode(dst *bytes.Buffer) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+
+	dst.WriteByte(leftSquareBracket)
+
+	serializeName(dst, v.Attr)
+
+	if len(v.Operator) > 0 {
+		dst.Write(v.Operator)
+		serializeString(dst, v.Value)
 	}
 
 	if len(v.Modifier) > 0 {
 		dst.WriteByte(space)
-		if _, err := dst.Write(v.Modifier); err != nil {
-			return err
-		}
+		dst.Write(v.Modifier)
 	}
 
 	dst.WriteByte(rightSquareBracket)
@@ -264,45 +421,259 @@ func (v *Attribute) encode(dst *bytes.Buffer) error {
 
 // Combinate the relationship between the selectors
 type Combinate struct {
-	Combinator TextBytes `json:"combinator"`
-	Simple     Simple    `json:"simple"`
+	Combinator Combinator `json:"combinator"`
+	Simple     Simple     `json:"simple"`
 }
 
 func (v *Combinate) encode(dst *bytes.Buffer) error {
-	if _, err := dst.Write(v.Combinator); err != nil {
+	if err := v.Combinator.encode(dst); err != nil {
 		return err
 	}
 
 	return v.Simple.encode(dst)
 }
 
-// Value of property
+// Combinator https://www.w3.org/TR/selectors-4/#combinators
+type Combinator int
+
+// Combinators
+const (
+	Descendant Combinator = iota
+	Child
+	NextSibling
+	SubsequentSibling
+	Column
+)
+
+var combinators = []struct {
+	name, symbol string
+}{
+	Descendant:        {name: "descendant", symbol: " "},
+	Child:             {name: "child", symbol: ">"},
+	NextSibling:       {name: "next-sibling", symbol: "+"},
+	SubsequentSibling: {name: "subsequent-sibling", symbol: "~"},
+	Column:            {name: "column", symbol: "||"},
+}
+
+// Validate returns ErrInvalidCombinator for unknown combinator
+func (v Combinator) Validate() error {
+	if v < Descendant || int(v) >= len(combinators) {
+		return ErrInvalidCombinator
+	}
+	return nil
+}
+
+// String returns the JSON name of combinator
+func (v Combinator) String() string {
+	if v.Validate() != nil {
+		return "combinator(" + strconv.Itoa(int(v)) + ")"
+	}
+	return combinators[v].name
+}
+
+// Symbol returns combinator as written in CSS
+func (v Combinator) Symbol() string {
+	if v.Validate() != nil {
+		return ""
+	}
+	return combinators[v].symbol
+}
+
+// encode writes minified combinator, only descendant is a space
+func (v Combinator) encode(dst *bytes.Buffer) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	dst.WriteString(combinators[v].symbol)
+
+	return nil
+}
+
+// encodeIndent writes valid combinator surrounded by spaces
+func (v Combinator) encodeIndent(dst *bytes.Buffer) {
+	if v != Descendant {
+		dst.WriteByte(space)
+		dst.WriteString(combinators[v].symbol)
+	}
+	dst.WriteByte(space)
+}
+
+// MarshalJSON marshal Combinator by name
+func (v Combinator) MarshalJSON() ([]byte, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(combinators[v].name)
+}
+
+// UnmarshalJSON unmarshal Combinator by name or by symbol as written by
+// older versions
+func (v *Combinator) UnmarshalJSON(b []byte) error {
+	var a string
+	if err := json.Unmarshal(b, &a); err != nil {
+		return err
+	}
+
+	c, err := ParseCombinator(a)
+	if err != nil {
+		return err
+	}
+	*v = c
+
+	return nil
+}
+
+// ParseCombinator returns combinator by name or by symbol, surrounding
+// spaces of symbol are ignored
+func ParseCombinator(s string) (Combinator, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" && s != "" {
+		return Descendant, nil
+	}
+	for k, c := range combinators {
+		if s == c.name || trimmed == c.symbol {
+			return Combinator(k), nil
+		}
+	}
+	return 0, ErrInvalidCombinator
+}
+
+// Value of property is a list of space separated tokens. Typed components
+// keep their position among raw tokens of ValueSpace in Tokens.
 type Value struct {
 	ValueSpace []TextBytes `json:"values,omitempty"`
+	Tokens     []Token     `json:"tokens,omitempty"`
+}
+
+// Token is a typed component of Value at Index among all tokens of the
+// value, a token with the index out of range follows the others
+type Token struct {
+	Index int    `json:"index"`
+	Calc  *Calc  `json:"calc,omitempty"`
+	Color *Color `json:"color,omitempty"`
+}
+
+func (v *Token) encode(dst *bytes.Buffer) error {
+	switch {
+	case v.Calc != nil:
+		return v.Calc.encode(dst)
+	case v.Color != nil:
+		return v.Color.encode(dst)
+	}
+	return nil
 }
 
 func (v *Value) encode(dst *bytes.Buffer) error {
-	data := bytes.Join(sliceValuesRaw(v.ValueSpace), []byte{space})
-	if _, err := dst.Write(data); err != nil {
-		return err
+	for k, i := range v.components() {
+		if k > 0 {
+			dst.WriteByte(space)
+		}
+		if err := i.encode(dst); err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
 
-func sliceValuesRaw(v []TextBytes) [][]byte {
-	ret := make([][]byte, len(v))
-	for k, i := range v {
-		ret[k] = i
+// components returns raw and typed tokens of the value in order
+func (v *Value) components() []encoder {
+	var (
+		ret   []encoder
+		raw   = v.ValueSpace
+		typed = v.sortedTokens()
+	)
+
+	for len(raw) > 0 || len(typed) > 0 {
+		if len(typed) > 0 && (typed[0].Index <= len(ret) || len(raw) == 0) {
+			ret = append(ret, &typed[0])
+			typed = typed[1:]
+			continue
+		}
+		ret = append(ret, rawToken(raw[0]))
+		raw = raw[1:]
+	}
+
+	return ret
+}
+
+// sortedTokens returns a copy of Tokens ordered by Index
+func (v *Value) sortedTokens() []Token {
+	ret := append([]Token{}, v.Tokens...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Index < ret[j].Index
+	})
+	return ret
+}
+
+// positionedTokens returns a copy of Tokens with indexes of their positions,
+// so raw tokens appended to ValueSpace follow them
+func (v *Value) positionedTokens() []Token {
+	var ret []Token
+	for k, i := range v.components() {
+		if t, ok := i.(*Token); ok {
+			t.Index = k
+			ret = append(ret, *t)
+		}
 	}
 	return ret
 }
 
+// hasTyped reports whether the value has typed components
+func (v *Value) hasTyped() bool {
+	return len(v.Tokens) > 0
+}
+
+// rawToken is a token of ValueSpace written as is
+type rawToken TextBytes
+
+func (v rawToken) encode(dst *bytes.Buffer) error {
+	_, err := dst.Write(v)
+	return err
+}
+
 // Declaration is setting CSS properties
 type Declaration struct {
 	Property TextBytes `json:"property"`
 	Values   []Value   `json:"values,omitempty"`
 }
 
+// EncodeDeclarations encodes declarations without selectors and braces like
+// the value of a style attribute
+func EncodeDeclarations(decls []Declaration) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if err := encodeDeclarations(buf, decls); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeDeclarations(dst *bytes.Buffer, decls []Declaration) error {
+	for idx, d := range decls {
+		if err := d.validate(); err != nil {
+			return err
+		}
+		if err := d.encode(dst); err != nil {
+			return err
+		}
+		if len(decls)-1 > idx {
+			dst.WriteByte(semicolon)
+		}
+	}
+
+	return nil
+}
+
+// validate checks what both compact and indented encoding need
+func (v *Declaration) validate() error {
+	if len(v.Property) == 0 {
+		return ErrInvalidDeclaration
+	}
+	return nil
+}
+
 func (v *Declaration) encode(dst *bytes.Buffer) error {
 	if _, err := dst.Write(v.Property); err != nil {
 		return err
@@ -368,6 +739,14 @@ func encodeItemsIfExists(items []encoder, dst *bytes.Buffer, before, after write
 	return nil
 }
 
+func encodeBytes(e encoder) []byte {
+	buf := &bytes.Buffer{}
+	if err := e.encode(buf); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
 // Identifier is a at-rule
 type Identifier struct {
 	Type        TextBytes   `json:"type"`
@@ -395,12 +774,14 @@ func (v *Identifier) UnmarshalJSON(b []byte) error {
 	switch ident {
 	case "charset":
 		localEncoder = &CharsetInformation{}
-	case "keyframes":
+	case "keyframes", "-webkit-keyframes", "-moz-keyframes", "-o-keyframes":
 		localEncoder = &KeyframesInformation{}
 	case "media":
 		localEncoder = &MediaInformation{}
 	case "font-face":
 		localEncoder = &FontFaceInformation{}
+	case "layer":
+		localEncoder = &LayerInformation{}
 	default:
 		return ErrNotExistsTypeIdentifier
 	}
@@ -428,7 +809,7 @@ func (v *Identifier) encode(dst *bytes.Buffer) error {
 		return err
 	}
 
-	if v.Information != nil {
+	if info, ok := v.Information.(*LayerInformation); v.Information != nil && (!ok || len(info.Names) > 0) {
 		dst.WriteByte(space)
 	}
 
@@ -445,10 +826,14 @@ type Information interface {
 }
 
 var identifierTypes = map[string]interface{}{
-	"charset":   &CharsetInformation{},
-	"keyframes": &KeyframesInformation{},
-	"media":     &MediaInformation{},
-	"font-face": &FontFaceInformation{},
+	"charset":           &CharsetInformation{},
+	"keyframes":         &KeyframesInformation{},
+	"-webkit-keyframes": &KeyframesInformation{},
+	"-moz-keyframes":    &KeyframesInformation{},
+	"-o-keyframes":      &KeyframesInformation{},
+	"media":             &MediaInformation{},
+	"font-face":         &FontFaceInformation{},
+	"layer":             &LayerInformation{},
 }
 
 // CharsetInformation https://developer.mozilla.org/en-US/docs/Web/CSS/@charset
@@ -457,26 +842,49 @@ type CharsetInformation struct {
 }
 
 func (v *CharsetInformation) encode(dst *bytes.Buffer) error {
-	dst.WriteByte(doubleQuote)
-	if _, err := dst.Write(v.Value); err != nil {
-		return err
-	}
-	dst.WriteByte(doubleQuote)
+	serializeString(dst, v.Value)
 	dst.WriteByte(semicolon)
 
 	return nil
 }
 
+// LayerInformation https://developer.mozilla.org/en-US/docs/Web/CSS/@layer
+// Names are dotted like "framework.base", a block without names is an
+// anonymous layer
+type LayerInformation struct {
+	Names []TextBytes `json:"names,omitempty"`
+}
+
+func (v *LayerInformation) encode(dst *bytes.Buffer) error {
+	for idx, name := range v.Names {
+		for k, i := range bytes.Split(name, []byte{period}) {
+			if k > 0 {
+				dst.WriteByte(period)
+			}
+			serializeIdentifier(dst, i)
+		}
+		if len(v.Names)-1 > idx {
+			dst.WriteByte(comma)
+		}
+	}
+
+	return nil
+}
+
 // KeyframesInformation https://developer.mozilla.org/en-US/docs/Web/CSS/@keyframes
 type KeyframesInformation struct {
 	Value TextBytes `json:"value"`
 }
 
+// Encode to CSS, a name written as a string like "my anim" is kept as is
 func (v *KeyframesInformation) encode(dst *bytes.Buffer) error {
-	if _, err := dst.Write(v.Value); err != nil {
-		return err
+	if n := len(v.Value); n >= 2 && (v.Value[0] == doubleQuote || v.Value[0] == '\'') && v.Value[n-1] == v.Value[0] {
+		dst.Write(v.Value)
+		return nil
 	}
 
+	serializeIdentifier(dst, v.Value)
+
 	return nil
 }
 
@@ -543,9 +951,7 @@ func (v *Type) encode(dst *bytes.Buffer) error {
 		dst.WriteByte(space)
 	}
 
-	if _, err := dst.Write(v.Value); err != nil {
-		return err
-	}
+	serializeIdentifier(dst, v.Value)
 
 	return nil
 }
@@ -567,9 +973,7 @@ func (v *Condition) encode(dst *bytes.Buffer) error {
 
 	dst.WriteByte(leftParenthesis)
 
-	if _, err := dst.Write(v.Feature); err != nil {
-		return err
-	}
+	serializeIdentifier(dst, v.Feature)
 
 	dst.WriteByte(colon)
 
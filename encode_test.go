@@ -3,6 +3,7 @@ package css2json
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -50,23 +51,23 @@ func TestA(t *testing.T) {
 							Selectors: []Selector{
 								{
 									Simple: Simple{
-										Element: TextBytes("#sidebar"),
+										IDs: []TextBytes{TextBytes("sidebar")},
 									},
 									Combinates: []Combinate{
 										{
-											Combinator: TextBytes(" "),
+											Combinator: Descendant,
 											Simple: Simple{
 												Element: TextBytes("ul"),
 											},
 										},
 										{
-											Combinator: TextBytes(" "),
+											Combinator: Descendant,
 											Simple: Simple{
 												Element: TextBytes("li"),
 											},
 										},
 										{
-											Combinator: TextBytes(" "),
+											Combinator: Descendant,
 											Simple: Simple{
 												Element: TextBytes("a"),
 											},
@@ -329,8 +330,9 @@ func TestMarshalJSON(t *testing.T) {
 
 func TestPseudo_encode(t *testing.T) {
 	type fields struct {
-		Ident []byte
-		Func  []byte
+		Ident     []byte
+		Func      []byte
+		Selectors []Selector
 	}
 	type args struct {
 		dst *bytes.Buffer
@@ -353,12 +355,37 @@ func TestPseudo_encode(t *testing.T) {
 			want:    "nth-child(4n)",
 			wantErr: false,
 		},
+		{
+			name: "selector list",
+			fields: fields{
+				Ident: []byte("has"),
+				Func:  []byte("ignored"),
+				Selectors: []Selector{
+					{
+						Combinates: []Combinate{
+							{Combinator: Child, Simple: Simple{Element: TextBytes("img")}},
+						},
+					},
+					{
+						Simple: Simple{Classes: []TextBytes{TextBytes("icon")}},
+						Combinates: []Combinate{
+							{Combinator: Descendant, Simple: Simple{Element: TextBytes("svg")}},
+						},
+					},
+				},
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: "has(>img,.icon svg)",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := &Pseudo{
-				Ident: tt.fields.Ident,
-				Func:  tt.fields.Func,
+				Ident:     tt.fields.Ident,
+				Func:      tt.fields.Func,
+				Selectors: tt.fields.Selectors,
 			}
 			if err := v.encode(tt.args.dst); (err != nil) != tt.wantErr {
 				t.Errorf("Pseudo.encode() error = %v, wantErr %v", err, tt.wantErr)
@@ -399,6 +426,85 @@ func TestAttribute_encode(t *testing.T) {
 			},
 			want: `[a*=".com" s]`,
 		},
+		{
+			name: "empty value",
+			fields: fields{
+				Attr:     []byte("alt"),
+				Operator: []byte("="),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `[alt=""]`,
+		},
+		{
+			name: "only attribute",
+			fields: fields{
+				Attr: []byte("hidden"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `[hidden]`,
+		},
+		{
+			name: "invalid operator",
+			fields: fields{
+				Attr:     []byte("a"),
+				Operator: []byte("=="),
+				Value:    []byte("b"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid modifier",
+			fields: fields{
+				Attr:     []byte("a"),
+				Operator: []byte("="),
+				Value:    []byte("b"),
+				Modifier: []byte("x"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "modifier without operator",
+			fields: fields{
+				Attr:     []byte("a"),
+				Modifier: []byte("i"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "value without operator",
+			fields: fields{
+				Attr:  []byte("a"),
+				Value: []byte("b"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "without name",
+			fields: fields{
+				Operator: []byte("="),
+				Value:    []byte("b"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -421,6 +527,9 @@ func TestAttribute_encode(t *testing.T) {
 func TestSimple_encode(t *testing.T) {
 	type fields struct {
 		Element        TextBytes
+		Universal      bool
+		Nesting        bool
+		IDs            []TextBytes
 		Classes        []TextBytes
 		Attributes     []Attribute
 		PseudoElements []Pseudo
@@ -437,6 +546,18 @@ func TestSimple_encode(t *testing.T) {
 		want    string
 		wantErr bool
 	}{
+		{
+			name: "id, universal and nesting",
+			fields: fields{
+				Element:   []byte("svg|"),
+				Universal: true,
+				Nesting:   true,
+				IDs:       []TextBytes{TextBytes("logo")},
+				Classes:   []TextBytes{TextBytes("dark")},
+			},
+			args: args{dst: &bytes.Buffer{}},
+			want: "svg|*&#logo.dark",
+		},
 		{
 			fields: fields{
 				Element: []byte("a"),
@@ -552,6 +673,9 @@ func TestSimple_encode(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			v := &Simple{
 				Element:        tt.fields.Element,
+				Universal:      tt.fields.Universal,
+				Nesting:        tt.fields.Nesting,
+				IDs:            tt.fields.IDs,
 				Classes:        tt.fields.Classes,
 				Attributes:     tt.fields.Attributes,
 				PseudoElements: tt.fields.PseudoElements,
@@ -568,6 +692,51 @@ func TestSimple_encode(t *testing.T) {
 	}
 }
 
+func TestSimple_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		js   string
+		want Simple
+		css  string
+	}{
+		{js: `{"element":"#sidebar"}`, want: Simple{IDs: []TextBytes{TextBytes("sidebar")}}},
+		{js: `{"element":"div#main"}`, want: Simple{Element: TextBytes("div"), IDs: []TextBytes{TextBytes("main")}}},
+		{js: `{"element":"*"}`, want: Simple{Universal: true}},
+		{js: `{"element":"html|*"}`, want: Simple{Element: TextBytes("html|"), Universal: true}},
+		{js: `{"element":"&"}`, want: Simple{Nesting: true}},
+		{js: `{"element":"50%"}`, want: Simple{Element: TextBytes("50%")}},
+		{js: `{"element":"a\\#b"}`, want: Simple{Element: TextBytes("a#b")}, css: `a\#b`},
+		{js: `{"element":"a\\#b#c\\&"}`, want: Simple{Element: TextBytes("a#b"), IDs: []TextBytes{TextBytes("c&")}}, css: `a\#b#c\&`},
+		{js: `{"universal":true,"ids":["x"]}`, want: Simple{Universal: true, IDs: []TextBytes{TextBytes("x")}}},
+		{
+			js:   `{"negations":[{"element":"#x"}]}`,
+			want: Simple{Negations: []Simple{{IDs: []TextBytes{TextBytes("x")}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.js, func(t *testing.T) {
+			var got Simple
+			if err := json.Unmarshal([]byte(tt.js), &got); err != nil {
+				t.Fatalf("Simple.UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Simple.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+			if encoded := string(encodeBytes(&got)); tt.css != "" && encoded != tt.css {
+				t.Errorf("Simple.encode() = %s, want %s", encoded, tt.css)
+			}
+
+			b, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Simple.MarshalJSON() error = %v", err)
+			}
+			var again Simple
+			if err := json.Unmarshal(b, &again); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("Simple.MarshalJSON() = %s, unmarshal to %+v, want %+v", b, again, got)
+			}
+		})
+	}
+}
+
 func TestDeclaration_encode(t *testing.T) {
 	type fields struct {
 		Property TextBytes
@@ -677,6 +846,41 @@ func TestDeclaration_encode(t *testing.T) {
 	}
 }
 
+func TestEncodeDeclarations(t *testing.T) {
+	tests := []struct {
+		name    string
+		decls   []Declaration
+		want    string
+		wantErr bool
+	}{
+		{
+			decls: testDeclarations("color:red", "margin:0 auto !important", `font-family:"A, B",serif`),
+			want:  `color:red;margin:0 auto !important;font-family:"A, B",serif`,
+		},
+		{
+			decls: []Declaration{{Property: TextBytes("--empty")}},
+			want:  "--empty:",
+		},
+		{},
+		{
+			name:    "without property",
+			decls:   []Declaration{{Values: parseValues([]byte("red"))}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeDeclarations(tt.decls)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EncodeDeclarations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeDeclarations() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSelector_encode(t *testing.T) {
 	type fields struct {
 		Simple     Simple
@@ -711,7 +915,7 @@ func TestSelector_encode(t *testing.T) {
 				},
 				Combinates: []Combinate{
 					{
-						Combinator: []byte(">"),
+						Combinator: Child,
 						Simple: Simple{
 							Element: []byte("p"),
 						},
@@ -731,25 +935,25 @@ func TestSelector_encode(t *testing.T) {
 				},
 				Combinates: []Combinate{
 					{
-						Combinator: []byte(">"),
+						Combinator: Child,
 						Simple: Simple{
 							Element: []byte("p"),
 						},
 					},
 					{
-						Combinator: []byte("~"),
+						Combinator: SubsequentSibling,
 						Simple: Simple{
 							Element: []byte("a"),
 						},
 					},
 					{
-						Combinator: []byte(" "),
+						Combinator: Descendant,
 						Simple: Simple{
 							Element: []byte("span"),
 						},
 					},
 					{
-						Combinator: []byte("+"),
+						Combinator: NextSibling,
 						Simple: Simple{
 							Element: []byte("b"),
 						},
@@ -781,7 +985,7 @@ func TestSelector_encode(t *testing.T) {
 
 func TestCombinate_encode(t *testing.T) {
 	type fields struct {
-		Combinator []byte
+		Combinator Combinator
 		Simple     Simple
 	}
 	type args struct {
@@ -796,7 +1000,7 @@ func TestCombinate_encode(t *testing.T) {
 	}{
 		{
 			fields: fields{
-				Combinator: []byte(">"),
+				Combinator: Child,
 				Simple: Simple{
 					Element: []byte("p"),
 				},
@@ -1008,23 +1212,23 @@ func TestEncode(t *testing.T) {
 										Selectors: []Selector{
 											{
 												Simple: Simple{
-													Element: TextBytes("#sidebar"),
+													IDs: []TextBytes{TextBytes("sidebar")},
 												},
 												Combinates: []Combinate{
 													{
-														Combinator: TextBytes(" "),
+														Combinator: Descendant,
 														Simple: Simple{
 															Element: TextBytes("ul"),
 														},
 													},
 													{
-														Combinator: TextBytes(" "),
+														Combinator: Descendant,
 														Simple: Simple{
 															Element: TextBytes("li"),
 														},
 													},
 													{
-														Combinator: TextBytes(" "),
+														Combinator: Descendant,
 														Simple: Simple{
 															Element: TextBytes("a"),
 														},
@@ -1347,6 +1551,42 @@ func TestAtRule_encode(t *testing.T) {
 			},
 			want: `@keyframes slide-right{from{margin-left:0px}50%{margin-left:110px;opacity:0.9}to{margin-left:200px}}`,
 		},
+		{
+			name: "layer statement",
+			fields: fields{
+				Identifier: Identifier{
+					Type: TextBytes("layer"),
+					Information: &LayerInformation{
+						Names: []TextBytes{TextBytes("reset"), TextBytes("theme.dark")},
+					},
+				},
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `@layer reset,theme.dark;`,
+		},
+		{
+			name: "anonymous layer block",
+			fields: fields{
+				Identifier: Identifier{
+					Type:        TextBytes("layer"),
+					Information: &LayerInformation{},
+				},
+				Nested: []*Statement{
+					{
+						Ruleset: &Ruleset{
+							Selectors:    []Selector{{Simple: Simple{Element: TextBytes("p")}}},
+							Declarations: testDeclarations("margin:0"),
+						},
+					},
+				},
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `@layer{p{margin:0}}`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1484,6 +1724,33 @@ func TestKeyframesInformation_encode(t *testing.T) {
 			want:    `name`,
 			wantErr: false,
 		},
+		{
+			fields: fields{
+				Value: TextBytes(`"my anim"`),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `"my anim"`,
+		},
+		{
+			fields: fields{
+				Value: TextBytes(`'it\'s'`),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `'it\'s'`,
+		},
+		{
+			fields: fields{
+				Value: TextBytes("my anim"),
+			},
+			args: args{
+				dst: &bytes.Buffer{},
+			},
+			want: `my\ anim`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1740,3 +2007,28 @@ func TestFontFaceInformation_encode(t *testing.T) {
 		})
 	}
 }
+
+func TestAttribute_Validate(t *testing.T) {
+	tests := []struct {
+		attr Attribute
+		want error
+	}{
+		{attr: Attribute{Attr: TextBytes("a"), Operator: TextBytes("|="), Value: TextBytes("en")}},
+		{attr: Attribute{Attr: TextBytes("a"), Operator: TextBytes("!="), Value: TextBytes("en")}, want: ErrInvalidAttributeOperator},
+		{attr: Attribute{Attr: TextBytes("a"), Operator: TextBytes("="), Modifier: TextBytes("S")}},
+		{attr: Attribute{Attr: TextBytes("a"), Operator: TextBytes("="), Modifier: TextBytes("u")}, want: ErrInvalidAttributeModifier},
+		{attr: Attribute{}, want: ErrInvalidAttribute},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%s%s", tt.attr.Attr, tt.attr.Operator, tt.attr.Modifier), func(t *testing.T) {
+			if err := tt.attr.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Attribute.Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	err := (&Attribute{Attr: TextBytes("href"), Operator: TextBytes("==")}).Validate()
+	if want := `invalid attribute operator "==" in [href], want one of = ~= |= ^= $= *=`; err == nil || err.Error() != want {
+		t.Errorf("Attribute.Validate() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,182 @@
+package css2json
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+const replacementCharacter = "�"
+
+// serializeIdentifier writes identifier escaped as defined by CSSOM
+// https://drafts.csswg.org/cssom/#serialize-an-identifier
+func serializeIdentifier(dst *bytes.Buffer, b []byte) {
+	for k := 0; k < len(b); {
+		r, size := utf8.DecodeRune(b[k:])
+
+		switch {
+		case r == 0:
+			dst.WriteString(replacementCharacter)
+		case r < 0x20 || r == 0x7f:
+			escapeCodePoint(dst, r)
+		case k == 0 && r >= '0' && r <= '9':
+			escapeCodePoint(dst, r)
+		case k == 1 && r >= '0' && r <= '9' && b[0] == '-':
+			escapeCodePoint(dst, r)
+		case k == 0 && r == '-' && len(b) == 1:
+			dst.WriteString(`\-`)
+		case r >= 0x80 || r == '-' || r == '_' || r < utf8.RuneSelf && isIdentByte(byte(r)):
+			dst.Write(b[k : k+size])
+		default:
+			dst.WriteByte('\\')
+			dst.Write(b[k : k+size])
+		}
+
+		k += size
+	}
+}
+
+// serializeString writes string in double quotes escaped as defined by
+// CSSOM https://drafts.csswg.org/cssom/#serialize-a-string
+func serializeString(dst *bytes.Buffer, b []byte) {
+	dst.WriteByte(doubleQuote)
+
+	for k := 0; k < len(b); {
+		r, size := utf8.DecodeRune(b[k:])
+
+		switch {
+		case r == 0:
+			dst.WriteString(replacementCharacter)
+		case r < 0x20 || r == 0x7f:
+			escapeCodePoint(dst, r)
+		case r == doubleQuote || r == '\\':
+			dst.WriteByte('\\')
+			dst.WriteRune(r)
+		default:
+			dst.Write(b[k : k+size])
+		}
+
+		k += size
+	}
+
+	dst.WriteByte(doubleQuote)
+}
+
+func escapeCodePoint(dst *bytes.Buffer, r rune) {
+	dst.WriteByte('\\')
+	dst.WriteString(strconv.FormatInt(int64(r), 16))
+	dst.WriteByte(space)
+}
+
+// unescape replaces CSS escapes in identifiers and strings, an escaped
+// newline is removed as in strings
+// https://www.w3.org/TR/css-syntax-3/#consume-escaped-code-point
+func unescape(b []byte) []byte {
+	if bytes.IndexByte(b, '\\') < 0 {
+		return b
+	}
+
+	ret := make([]byte, 0, len(b))
+	for k := 0; k < len(b); k++ {
+		if b[k] != '\\' {
+			ret = append(ret, b[k])
+			continue
+		}
+
+		k++
+		if k >= len(b) {
+			ret = append(ret, replacementCharacter...)
+			break
+		}
+
+		if b[k] == '\n' {
+			continue
+		}
+
+		end := k
+		for end < len(b) && end-k < 6 && isHexDigit(b[end]) {
+			end++
+		}
+		if end == k {
+			ret = append(ret, b[k])
+			continue
+		}
+
+		n, _ := strconv.ParseUint(string(b[k:end]), 16, 32)
+		r := rune(n)
+		if r == 0 || r > utf8.MaxRune || r >= 0xd800 && r <= 0xdfff {
+			r = utf8.RuneError
+		}
+		ret = append(ret, string(r)...)
+
+		k = end - 1
+		if end < len(b) && isSpace(b[end]) {
+			k++
+		}
+	}
+
+	return ret
+}
+
+// isEscaped reports whether the byte at i is a part of an escape
+func isEscaped(b []byte, i int) bool {
+	for k := 0; k <= i && k < len(b); {
+		if b[k] != '\\' {
+			k++
+			continue
+		}
+		end := skipEscape(b, k)
+		if i < end {
+			return true
+		}
+		k = end
+	}
+	return false
+}
+
+// escapeElement escapes a type selector name in which splitElement would
+// find an ID, nesting or universal selector, a namespace "*|" is kept
+func escapeElement(name []byte) TextBytes {
+	if !bytes.ContainsAny(name, `\#&*`) {
+		return name
+	}
+
+	ret := make(TextBytes, 0, len(name)+2)
+	for k, c := range name {
+		if c == '\\' || c == numberSign || c == '&' || c == asterisk && (k+1 == len(name) || name[k+1] != '|') {
+			ret = append(ret, '\\')
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// serializeName writes type or attribute name with optional namespace
+// prefix, "*" is kept as is
+func serializeName(dst *bytes.Buffer, b []byte) {
+	idx := bytes.IndexByte(b, '|')
+	if idx >= 0 {
+		serializeNamePart(dst, b[:idx])
+		dst.WriteByte('|')
+		b = b[idx+1:]
+	}
+	serializeNamePart(dst, b)
+}
+
+func serializeNamePart(dst *bytes.Buffer, b []byte) {
+	if len(b) == 1 && b[0] == asterisk {
+		dst.WriteByte(asterisk)
+		return
+	}
+	serializeIdentifier(dst, b)
+}
+
+// isKeyframeSelector reports whether element is a percentage of @keyframes
+func isKeyframeSelector(element []byte) bool {
+	_, unit, ok := splitDimension(string(element))
+	return ok && unit == "%"
+}
@@ -0,0 +1,115 @@
+package css2json
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_serializeIdentifier(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "foo", want: "foo"},
+		{in: "1col", want: `\31 col`},
+		{in: "-2x", want: `-\32 x`},
+		{in: "-", want: `\-`},
+		{in: "--var", want: "--var"},
+		{in: "a b", want: `a\ b`},
+		{in: "a:b.c", want: `a\:b\.c`},
+		{in: "tab\there", want: `tab\9 here`},
+		{in: "nul\x00", want: "nul\uFFFD"},
+		{in: "ünï", want: "ünï"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			dst := &bytes.Buffer{}
+			serializeIdentifier(dst, []byte(tt.in))
+			if got := dst.String(); got != tt.want {
+				t.Errorf("serializeIdentifier() = %v, want %v", got, tt.want)
+			}
+			if got := string(unescape(dst.Bytes())); got != tt.in && tt.in != "nul\x00" {
+				t.Errorf("unescape() = %q, want %q", got, tt.in)
+			}
+		})
+	}
+}
+
+func Test_serializeString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "utf-8", want: `"utf-8"`},
+		{in: `say "hi"`, want: `"say \"hi\""`},
+		{in: `c:\dir`, want: `"c:\\dir"`},
+		{in: "line\nbreak", want: `"line\a break"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			dst := &bytes.Buffer{}
+			serializeString(dst, []byte(tt.in))
+			if got := dst.String(); got != tt.want {
+				t.Errorf("serializeString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_unescape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: `plain`, want: "plain"},
+		{in: `\31 0`, want: "10"},
+		{in: `\000031x`, want: "1x"},
+		{in: `\26 B`, want: "&B"},
+		{in: `a\"b`, want: `a"b`},
+		{in: "a\\\nb", want: "ab"},
+		{in: `\0`, want: "\uFFFD"},
+		{in: `\D800`, want: "\uFFFD"},
+		{in: `end\`, want: "end\uFFFD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := string(unescape([]byte(tt.in))); got != tt.want {
+				t.Errorf("unescape() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode_escaping(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{selector: `.\31 0col`, want: `.\31 0col`},
+		{selector: `#a\:b`, want: `#a\:b`},
+		{selector: `a[title="say \"hi\""]`, want: `a[title="say \"hi\""]`},
+		{selector: `a[title='it\'s']`, want: `a[title="it's"]`},
+		{selector: `.\@sm\:flex`, want: `.\@sm\:flex`},
+		{selector: `.a\2c b`, want: `.a\,b`},
+		{selector: `a\#b#c`, want: `a\#b#c`},
+		{selector: `#a\#b#c`, want: `#a\#b#c`},
+		{selector: `\&a\*`, want: `\&a\*`},
+		{selector: `a\\b`, want: `a\\b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			s, err := parseSelectors([]byte(tt.selector))
+			if err != nil {
+				t.Fatalf("parseSelectors() error = %v", err)
+			}
+			if got := string(encodeBytes(&s[0])); got != tt.want {
+				t.Errorf("Selector.encode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	charset := &CharsetInformation{Value: TextBytes(`ut"f`)}
+	if got := string(encodeBytes(charset)); got != `"ut\"f";` {
+		t.Errorf("CharsetInformation.encode() = %v", got)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/imega/css2json
+
+go 1.26.0
+
+require golang.org/x/net v0.60.0
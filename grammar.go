@@ -0,0 +1,767 @@
+package css2json
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidGrammar
+	ErrInvalidGrammar = errors.New("invalid value definition syntax")
+)
+
+type grammarKind int
+
+const (
+	grammarGroup grammarKind = iota
+	grammarKeyword
+	grammarLiteral
+	grammarType
+	grammarProperty
+	grammarFunction
+)
+
+type grammarJoin int
+
+const (
+	joinJuxtapose grammarJoin = iota
+	joinAllOf
+	joinAnyOf
+	joinOneOf
+)
+
+// grammar is a node of CSS value definition syntax
+// https://developer.mozilla.org/en-US/docs/Web/CSS/Value_definition_syntax
+type grammar struct {
+	kind     grammarKind
+	value    string
+	join     grammarJoin
+	children []*grammar
+	min, max int
+	comma    bool
+	required bool
+	bounds   []rangeBound
+}
+
+// rangeBound is a bound of a numeric type range like <number [0,1]>
+type rangeBound struct {
+	number float64
+	unit   string
+}
+
+// Grammar is a parsed value definition syntax
+// https://www.w3.org/TR/css-values-4/#value-defs
+type Grammar struct {
+	syntax string
+	root   *grammar
+}
+
+// ParseGrammar parses value definition syntax like "<length> | auto" or
+// "[ <length> || <color> ]{1,4}". Named types and properties are looked up
+// when matching, so they may be registered later. A range of a numeric type
+// like "<number [0,1]>" is checked unless a unit can't be compared to it.
+func ParseGrammar(syntax string) (*Grammar, error) {
+	g, err := parseGrammar(syntax)
+	if err != nil {
+		return nil, err
+	}
+	return &Grammar{syntax: syntax, root: g}, nil
+}
+
+// String returns the syntax of grammar
+func (g *Grammar) String() string {
+	return g.syntax
+}
+
+// Match reports whether values match grammar
+func (g *Grammar) Match(values []Value) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	m := &grammarMatcher{types: types, properties: properties}
+	return m.matches(g.root, grammarTokens(values))
+}
+
+// registry guards types and properties tables against Register calls
+var registry sync.RWMutex
+
+// RegisterType registers a named data type used as <name> in grammars
+func RegisterType(name, syntax string) error {
+	g, err := parseGrammar(syntax)
+	if err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	types[strings.ToLower(name)] = g
+	return nil
+}
+
+// RegisterProperty registers or replaces the grammar of a property, the
+// registered custom properties are validated by Validate and
+// ValidateDeclaration.
+func RegisterProperty(name, syntax string) error {
+	g, err := parseGrammar(syntax)
+	if err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if !isCustomProperty(TextBytes(name)) {
+		name = strings.ToLower(name)
+	}
+	properties[name] = g
+	return nil
+}
+
+// parseGrammar parses value definition syntax like "<length> | auto"
+func parseGrammar(s string) (*grammar, error) {
+	p := &grammarParser{tokens: grammarLexer(s)}
+
+	ret, err := p.oneOf()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, ErrInvalidGrammar
+	}
+
+	return ret, nil
+}
+
+// mustParseGrammar is parseGrammar for built-in tables
+func mustParseGrammar(s string) *grammar {
+	g, err := parseGrammar(s)
+	if err != nil {
+		panic(s + ": " + err.Error())
+	}
+	return g
+}
+
+// grammarLexer splits syntax in tokens, function names keep the parenthesis
+func grammarLexer(s string) []string {
+	var ret []string
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '<':
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				end = len(s) - i - 1
+			}
+			ret = append(ret, s[i:i+end+1])
+			i += end + 1
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				end = len(s) - i - 1
+			}
+			ret = append(ret, s[i:i+end+1])
+			i += end + 1
+		case (c == '|' || c == '&') && i+1 < len(s) && s[i+1] == c:
+			ret = append(ret, s[i:i+2])
+			i += 2
+		case strings.IndexByte("[]|?*+#!,/)", c) >= 0:
+			ret = append(ret, s[i:i+1])
+			i++
+		default:
+			start := i
+			for i < len(s) && !isSpace(s[i]) && strings.IndexByte("[]|&?*+#!,/<>{}()", s[i]) < 0 {
+				i++
+			}
+			if i < len(s) && s[i] == leftParenthesis {
+				i++
+			}
+			if i == start {
+				i++
+			}
+			ret = append(ret, s[start:i])
+		}
+	}
+
+	return ret
+}
+
+type grammarParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *grammarParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *grammarParser) oneOf() (*grammar, error) {
+	return p.joined(joinOneOf, "|", p.anyOf)
+}
+
+func (p *grammarParser) anyOf() (*grammar, error) {
+	return p.joined(joinAnyOf, "||", p.allOf)
+}
+
+func (p *grammarParser) allOf() (*grammar, error) {
+	return p.joined(joinAllOf, "&&", p.juxtapose)
+}
+
+func (p *grammarParser) joined(join grammarJoin, sep string, next func() (*grammar, error)) (*grammar, error) {
+	first, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []*grammar{first}
+	for p.peek() == sep {
+		p.pos++
+		g, err := next()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, g)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+
+	return &grammar{kind: grammarGroup, join: join, children: children, min: 1, max: 1}, nil
+}
+
+func (p *grammarParser) juxtapose() (*grammar, error) {
+	var children []*grammar
+
+	for {
+		switch p.peek() {
+		case "", "]", ")", "|", "||", "&&":
+			if len(children) == 0 {
+				return nil, ErrInvalidGrammar
+			}
+			if len(children) == 1 {
+				return children[0], nil
+			}
+			return &grammar{kind: grammarGroup, join: joinJuxtapose, children: children, min: 1, max: 1}, nil
+		}
+
+		g, err := p.multiplied()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, g)
+	}
+}
+
+func (p *grammarParser) multiplied() (*grammar, error) {
+	g, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for multiplied := false; ; multiplied = true {
+		tok := p.peek()
+		if tok == "" || strings.IndexByte("?*+#{!", tok[0]) < 0 {
+			return g, nil
+		}
+		if tok == "!" {
+			if g.kind != grammarGroup || g.required {
+				return nil, ErrInvalidGrammar
+			}
+			p.pos++
+			g.required = true
+			continue
+		}
+		if multiplied && !(g.comma && tok[0] == '{') {
+			g = &grammar{kind: grammarGroup, join: joinJuxtapose, children: []*grammar{g}, min: 1, max: 1}
+		}
+		p.pos++
+
+		switch tok[0] {
+		case '?':
+			g.min, g.max = 0, 1
+		case '*':
+			g.min, g.max = 0, -1
+		case '+':
+			g.min, g.max = 1, -1
+		case '#':
+			g.min, g.max, g.comma = 1, -1, true
+		case '{':
+			if g.min, g.max, err = parseRange(tok); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// parseRange parses {m}, {m,} and {m,n}
+func parseRange(tok string) (int, int, error) {
+	parts := strings.Split(strings.Trim(tok, "{}"), ",")
+
+	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || len(parts) > 2 {
+		return 0, 0, ErrInvalidGrammar
+	}
+	if len(parts) == 1 {
+		return min, min, nil
+	}
+	if strings.TrimSpace(parts[1]) == "" {
+		return min, -1, nil
+	}
+
+	max, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || max < min {
+		return 0, 0, ErrInvalidGrammar
+	}
+
+	return min, max, nil
+}
+
+// rangeTypes are numeric types taking a range
+var rangeTypes = keywords(
+	"number", "integer", "length", "percentage", "length-percentage", "angle", "time", "frequency",
+	"resolution", "flex",
+)
+
+// parseBounds parses a range like [0,∞] of a numeric type
+func parseBounds(s string) ([]rangeBound, error) {
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, ErrInvalidGrammar
+	}
+
+	parts := strings.Split(s[1:len(s)-1], ",")
+	if len(parts) != 2 {
+		return nil, ErrInvalidGrammar
+	}
+
+	ret := make([]rangeBound, 2)
+	for k, i := range parts {
+		switch i = strings.TrimSpace(i); i {
+		case "∞", "+∞":
+			ret[k].number = math.Inf(1)
+		case "-∞":
+			ret[k].number = math.Inf(-1)
+		default:
+			n, unit, ok := splitDimension(i)
+			if !ok {
+				return nil, ErrInvalidGrammar
+			}
+			ret[k] = rangeBound{number: n, unit: unit}
+		}
+	}
+	if compareBound(ret[1], ret[0].number, ret[0].unit) < 0 {
+		return nil, ErrInvalidGrammar
+	}
+
+	return ret, nil
+}
+
+// inBounds reports whether a numeric token is in the range, math
+// functions and units which can't be compared to a bound are checked at
+// computed-value time
+func inBounds(bounds []rangeBound, tok string) bool {
+	if len(bounds) == 0 || IsMathFunction([]byte(tok)) {
+		return true
+	}
+	n, unit, ok := splitDimension(tok)
+	if !ok {
+		return true
+	}
+	return compareBound(bounds[0], n, unit) <= 0 && compareBound(bounds[1], n, unit) >= 0
+}
+
+// compareBound returns -1, 0 or 1 when the bound is less, equal or greater
+// than number with unit, 0 when they can't be compared
+func compareBound(b rangeBound, n float64, unit string) int {
+	if b.number != 0 && !math.IsInf(b.number, 0) && n != 0 && b.unit != unit {
+		bc, bok := canonicalUnits[b.unit]
+		c, ok := canonicalUnits[unit]
+		if !bok || !ok || bc.unit != c.unit {
+			return 0
+		}
+		b.number, n = b.number*bc.ratio, n*c.ratio
+	}
+
+	switch {
+	case b.number < n:
+		return -1
+	case b.number > n:
+		return 1
+	}
+	return 0
+}
+
+func (p *grammarParser) term() (*grammar, error) {
+	tok := p.peek()
+	p.pos++
+
+	switch {
+	case tok == "[":
+		g, err := p.oneOf()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != "]" {
+			return nil, ErrInvalidGrammar
+		}
+		p.pos++
+		return &grammar{kind: grammarGroup, join: joinJuxtapose, children: []*grammar{g}, min: 1, max: 1}, nil
+	case strings.HasPrefix(tok, "<'") && strings.HasSuffix(tok, "'>"):
+		return &grammar{kind: grammarProperty, value: tok[2 : len(tok)-2], min: 1, max: 1}, nil
+	case strings.HasPrefix(tok, "<") && strings.HasSuffix(tok, ">"):
+		g := &grammar{kind: grammarType, value: tok[1 : len(tok)-1], min: 1, max: 1}
+		if idx := strings.IndexByte(g.value, '['); idx >= 0 {
+			bounds, err := parseBounds(g.value[idx:])
+			if err != nil {
+				return nil, err
+			}
+			g.value, g.bounds = strings.TrimSpace(g.value[:idx]), bounds
+			if !rangeTypes[g.value] {
+				return nil, ErrInvalidGrammar
+			}
+		}
+		return g, nil
+	case strings.HasSuffix(tok, "("):
+		g := &grammar{kind: grammarFunction, value: strings.ToLower(tok[:len(tok)-1]), min: 1, max: 1}
+		if p.peek() != ")" {
+			inner, err := p.oneOf()
+			if err != nil {
+				return nil, err
+			}
+			g.children = []*grammar{inner}
+		}
+		if p.peek() != ")" {
+			return nil, ErrInvalidGrammar
+		}
+		p.pos++
+		return g, nil
+	case tok == "," || tok == "/":
+		return &grammar{kind: grammarLiteral, value: tok, min: 1, max: 1}, nil
+	case tok == "" || strings.IndexByte("[]|&?*+#!{}<>()", tok[0]) >= 0:
+		return nil, ErrInvalidGrammar
+	}
+
+	return &grammar{kind: grammarKeyword, value: strings.ToLower(tok), min: 1, max: 1}, nil
+}
+
+// grammarTokens flattens values in tokens matched by grammar, comma
+// separated values are divided by "," token
+func grammarTokens(values []Value) []string {
+	var ret []string
+
+	for idx, v := range values {
+		if idx > 0 {
+			ret = append(ret, ",")
+		}
+		var tokens []TextBytes
+		for _, i := range v.components() {
+			tokens = append(tokens, encodeBytes(i))
+		}
+		ret = append(ret, splitSlash(tokens)...)
+	}
+
+	return ret
+}
+
+// grammarMatcher looks up named types and properties of a grammar
+type grammarMatcher struct {
+	types      map[string]*grammar
+	properties map[string]*grammar
+	depth      int
+}
+
+// matches reports whether all tokens match g
+func (m *grammarMatcher) matches(g *grammar, tokens []string) bool {
+	for _, end := range m.match(g, tokens, 0) {
+		if end == len(tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns every position where a match of g started at pos may end
+func (m *grammarMatcher) match(g *grammar, tokens []string, pos int) []int {
+	var (
+		ret     positions
+		current = positions{pos}
+	)
+
+	if g.min == 0 {
+		ret = ret.add(pos)
+	}
+
+	for count := 1; (g.max < 0 || count <= g.max) && len(current) > 0; count++ {
+		var next positions
+		for _, p := range current {
+			start := p
+			if count > 1 && g.comma {
+				if p >= len(tokens) || tokens[p] != "," {
+					continue
+				}
+				start++
+			}
+			for _, end := range m.matchOnce(g, tokens, start) {
+				if g.required && end == start {
+					continue
+				}
+				if end > p || count <= g.min {
+					next = next.add(end)
+				}
+			}
+		}
+		if count >= g.min {
+			for _, i := range next {
+				ret = ret.add(i)
+			}
+		}
+		if count > len(tokens) && count >= g.min {
+			break
+		}
+		current = next
+	}
+
+	return ret
+}
+
+func (m *grammarMatcher) matchOnce(g *grammar, tokens []string, pos int) []int {
+	switch g.kind {
+	case grammarKeyword:
+		if pos < len(tokens) && strings.ToLower(tokens[pos]) == g.value {
+			return []int{pos + 1}
+		}
+	case grammarLiteral:
+		if pos < len(tokens) && tokens[pos] == g.value {
+			return []int{pos + 1}
+		}
+	case grammarType:
+		if named, ok := m.types[g.value]; ok {
+			return m.matchNamed(named, tokens, pos)
+		}
+		// commas are separate tokens, only <any-value> takes them
+		if check, ok := dataTypes[g.value]; ok && pos < len(tokens) && (tokens[pos] != "," || g.value == "any-value") && check(tokens[pos]) &&
+			inBounds(g.bounds, tokens[pos]) {
+			return []int{pos + 1}
+		}
+	case grammarProperty:
+		if named, ok := m.properties[g.value]; ok {
+			return m.matchNamed(named, tokens, pos)
+		}
+	case grammarFunction:
+		if pos >= len(tokens) {
+			return nil
+		}
+		name, args, ok := splitFunction(tokens[pos])
+		if !ok || name != g.value {
+			return nil
+		}
+		inner := grammarTokens(parseValues([]byte(args)))
+		if len(g.children) == 0 && len(inner) == 0 || len(g.children) > 0 && m.matches(g.children[0], inner) {
+			return []int{pos + 1}
+		}
+	case grammarGroup:
+		return m.matchGroup(g, tokens, pos)
+	}
+
+	return nil
+}
+
+// matchNamed matches a type or property definition, depth limits
+// recursive definitions
+func (m *grammarMatcher) matchNamed(g *grammar, tokens []string, pos int) []int {
+	if m.depth > 32 {
+		return nil
+	}
+	m.depth++
+	defer func() { m.depth-- }()
+
+	return m.match(g, tokens, pos)
+}
+
+func (m *grammarMatcher) matchGroup(g *grammar, tokens []string, pos int) []int {
+	switch g.join {
+	case joinOneOf:
+		var ret positions
+		for _, c := range g.children {
+			for _, i := range m.match(c, tokens, pos) {
+				ret = ret.add(i)
+			}
+		}
+		return ret
+	case joinAllOf, joinAnyOf:
+		return m.matchSet(g, tokens, pos, 0)
+	}
+
+	current := positions{pos}
+	for _, c := range g.children {
+		var next positions
+		for _, p := range current {
+			for _, i := range m.match(c, tokens, p) {
+				next = next.add(i)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// matchSet matches children in any order, used is a mask of matched ones
+func (m *grammarMatcher) matchSet(g *grammar, tokens []string, pos int, used uint) []int {
+	var ret positions
+
+	all := uint(1)<<uint(len(g.children)) - 1
+	if used == all || (g.join == joinAnyOf && used != 0) {
+		ret = ret.add(pos)
+	}
+
+	for k, c := range g.children {
+		if used&(1<<uint(k)) != 0 {
+			continue
+		}
+		for _, end := range m.match(c, tokens, pos) {
+			if end == pos && g.join == joinAnyOf {
+				continue
+			}
+			for _, i := range m.matchSet(g, tokens, end, used|1<<uint(k)) {
+				ret = ret.add(i)
+			}
+		}
+	}
+
+	return ret
+}
+
+type positions []int
+
+func (p positions) add(i int) positions {
+	for _, j := range p {
+		if i == j {
+			return p
+		}
+	}
+	return append(p, i)
+}
+
+// splitFunction splits "name(args)" in lower case name and args
+func splitFunction(s string) (string, string, bool) {
+	open := strings.IndexByte(s, leftParenthesis)
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", "", false
+	}
+	return strings.ToLower(s[:open]), s[open+1 : len(s)-1], true
+}
+
+var lengthUnits = keywords(
+	"px", "em", "rem", "ex", "rex", "ch", "rch", "cap", "rcap", "ic", "ric", "lh", "rlh",
+	"vw", "vh", "vi", "vb", "vmin", "vmax", "svw", "svh", "svi", "svb", "svmin", "svmax",
+	"lvw", "lvh", "lvi", "lvb", "lvmin", "lvmax", "dvw", "dvh", "dvi", "dvb", "dvmin", "dvmax",
+	"cqw", "cqh", "cqi", "cqb", "cqmin", "cqmax", "cm", "mm", "q", "in", "pt", "pc",
+)
+
+// splitDimension splits a number and its unit
+func splitDimension(s string) (float64, string, bool) {
+	s = strings.ToLower(s)
+	idx := len(s)
+	for idx > 0 && (isLetter(s[idx-1]) || s[idx-1] == '%') {
+		idx--
+	}
+	n, err := strconv.ParseFloat(s[:idx], 64)
+	if err != nil || idx == 0 {
+		return 0, "", false
+	}
+	return n, s[idx:], true
+}
+
+func dimensionType(units ...string) func(string) bool {
+	set := keywords(units...)
+	return func(s string) bool {
+		if IsMathFunction([]byte(s)) {
+			return true
+		}
+		_, unit, ok := splitDimension(s)
+		return ok && set[unit]
+	}
+}
+
+func isLengthToken(s string) bool {
+	if IsMathFunction([]byte(s)) {
+		return true
+	}
+	n, unit, ok := splitDimension(s)
+	return ok && (lengthUnits[unit] || (unit == "" && n == 0))
+}
+
+func isPercentage(s string) bool {
+	if IsMathFunction([]byte(s)) {
+		return true
+	}
+	_, unit, ok := splitDimension(s)
+	return ok && unit == "%"
+}
+
+var (
+	identPattern  = regexp.MustCompile(`^-?([a-zA-Z_]|[^\x00-\x7f]|\\.)([a-zA-Z0-9_-]|[^\x00-\x7f]|\\.)*$`)
+	urangePattern = regexp.MustCompile(`(?i)^u\+[0-9a-f?]{1,6}(-[0-9a-f]{1,6})?$`)
+)
+
+func isIdent(s string) bool {
+	return identPattern.MatchString(s)
+}
+
+// dataTypes are built-in basic data types
+var dataTypes = map[string]func(string) bool{
+	"length":            isLengthToken,
+	"percentage":        isPercentage,
+	"length-percentage": func(s string) bool { return isLengthToken(s) || isPercentage(s) },
+	"number": func(s string) bool {
+		return isNumber(s) || IsMathFunction([]byte(s))
+	},
+	"integer": func(s string) bool {
+		_, err := strconv.Atoi(s)
+		return err == nil || IsMathFunction([]byte(s))
+	},
+	"angle":      dimensionType("deg", "grad", "rad", "turn"),
+	"time":       dimensionType("s", "ms"),
+	"frequency":  dimensionType("hz", "khz"),
+	"resolution": dimensionType("dpi", "dpcm", "dppx", "x"),
+	"flex":       dimensionType("fr"),
+	"color": func(s string) bool {
+		if strings.EqualFold(s, "currentcolor") {
+			return true
+		}
+		_, err := ParseColor([]byte(s))
+		return err == nil
+	},
+	"image": func(s string) bool {
+		return !strings.EqualFold(s, "none") && isImage(strings.ToLower(s))
+	},
+	"url": func(s string) bool {
+		return strings.HasPrefix(strings.ToLower(s), "url(") && strings.HasSuffix(s, ")")
+	},
+	"string": func(s string) bool {
+		return len(s) >= 2 && (s[0] == doubleQuote || s[0] == '\'') && s[len(s)-1] == s[0]
+	},
+	"ident": isIdent,
+	"custom-ident": func(s string) bool {
+		l := strings.ToLower(s)
+		return isIdent(s) && !cssWideKeywords[l] && l != "default"
+	},
+	"dashed-ident": func(s string) bool {
+		return strings.HasPrefix(s, "--") && isIdent(s)
+	},
+	"line-names": func(s string) bool {
+		return strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")
+	},
+	"urange":    urangePattern.MatchString,
+	"any-value": func(string) bool { return true },
+}
@@ -0,0 +1,149 @@
+package css2json
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_parseGrammar(t *testing.T) {
+	tests := []struct {
+		syntax  string
+		wantErr bool
+	}{
+		{syntax: "<length> | auto"},
+		{syntax: "[ a || b ]{1,4}"},
+		{syntax: "<color>#{2,}"},
+		{syntax: "fit-content( <length-percentage> )"},
+		{syntax: "<length [0,∞]>"},
+		{syntax: "<number [1,0]>", wantErr: true},
+		{syntax: "<color [0,1]>", wantErr: true},
+		{syntax: "<number [a,1]>", wantErr: true},
+		{syntax: "[ a | b", wantErr: true},
+		{syntax: "a | | b", wantErr: true},
+		{syntax: "a{2,1}", wantErr: true},
+		{syntax: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.syntax, func(t *testing.T) {
+			if _, err := parseGrammar(tt.syntax); (err != nil) != tt.wantErr {
+				t.Errorf("parseGrammar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_grammarMatcher_matches(t *testing.T) {
+	tests := []struct {
+		syntax string
+		value  string
+		want   bool
+	}{
+		{syntax: "<length> | auto", value: "10px", want: true},
+		{syntax: "<length> | auto", value: "AUTO", want: true},
+		{syntax: "<length> | auto", value: "10", want: false},
+		{syntax: "<length> | auto", value: "0", want: true},
+		{syntax: "<length> | auto", value: "calc(1px + 2em)", want: true},
+		{syntax: "[ a || b ]{1,4}", value: "b a a", want: true},
+		{syntax: "a || b", value: "a a", want: false},
+		{syntax: "a && b && c", value: "c a b", want: true},
+		{syntax: "a && b && c", value: "c a", want: false},
+		{syntax: "a b? c", value: "a c", want: true},
+		{syntax: "a b* c", value: "a b b b c", want: true},
+		{syntax: "a b+ c", value: "a c", want: false},
+		{syntax: "<number>#", value: "1,2, 3", want: true},
+		{syntax: "<number>#", value: "1 2", want: false},
+		{syntax: "<number>#{2}", value: "1,2,3", want: false},
+		{syntax: "<integer>{2,3}", value: "1 2 3", want: true},
+		{syntax: "<integer>{2,3}", value: "1", want: false},
+		{syntax: "<length> [ / <length> ]?", value: "1px/2px", want: true},
+		{syntax: "rotate( <angle> )", value: "rotate(45deg)", want: true},
+		{syntax: "rotate( <angle> )", value: "rotate(45px)", want: false},
+		{syntax: "<color>", value: "rgb(0 0 0 / 50%)", want: true},
+		{syntax: "<custom-ident>", value: "inherit", want: false},
+		{syntax: "<string>+", value: `"a" 'b'`, want: true},
+		{syntax: "<urange>#", value: "U+0025-00FF, u+4??", want: true},
+		{syntax: "<track-size>", value: "minmax(0, 1fr)", want: true},
+		{syntax: "<'grid-template-columns'>", value: "repeat(3, 1fr)", want: true},
+		{syntax: "<'clip-path'>", value: "polygon(0 0, 100% 0, 50% 100%)", want: true},
+		{syntax: "<'content'>", value: "counter(item, upper-roman)", want: true},
+		{syntax: "<'clip'>", value: "rect(0, 0, 0, 0)", want: true},
+		{syntax: "<easing-function>", value: "linear(0, .5, 1)", want: true},
+		{syntax: "<any-value>+", value: "a, b", want: true},
+		{syntax: "<ident>+", value: "a, b", want: false},
+		{syntax: "<number [0,1]>", value: "2", want: false},
+		{syntax: "<number [0,1]>", value: ".5", want: true},
+		{syntax: "<length [0,∞]>", value: "-1px", want: false},
+		{syntax: "<length [0,∞]>", value: "1em", want: true},
+		{syntax: "<angle [0deg,1turn]>", value: "400deg", want: false},
+		{syntax: "<angle [0deg,1turn]>", value: "calc(400deg)", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.syntax+" "+tt.value, func(t *testing.T) {
+			m := &grammarMatcher{types: types, properties: properties}
+			got := m.matches(mustParseGrammar(tt.syntax), grammarTokens(parseValues([]byte(tt.value))))
+			if got != tt.want {
+				t.Errorf("grammarMatcher.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrammar_Match(t *testing.T) {
+	tests := []struct {
+		syntax string
+		value  string
+		want   bool
+	}{
+		{syntax: "[ a? b? ]!", value: "b", want: true},
+		{syntax: "[ a? b? ]! c", value: "c", want: false},
+		{syntax: "[ a? b? ] c", value: "c", want: true},
+		{syntax: "<length> | auto", value: "1em", want: true},
+		{syntax: "<'margin-top'>{1,2}", value: "auto 1px", want: true},
+		{syntax: "<color>#", value: "red,blue", want: true},
+		{syntax: "<number [0,1]>", value: "2", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.syntax+" "+tt.value, func(t *testing.T) {
+			g, err := ParseGrammar(tt.syntax)
+			if err != nil {
+				t.Fatalf("ParseGrammar() error = %v", err)
+			}
+			if got := g.Match(parseValues([]byte(tt.value))); got != tt.want {
+				t.Errorf("Grammar.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterProperty(t *testing.T) {
+	if err := RegisterType("test-size", "small | large | <length>"); err != nil {
+		t.Fatalf("RegisterType() error = %v", err)
+	}
+	if err := RegisterProperty("--test-sizes", "<test-size>{1,2}"); err != nil {
+		t.Fatalf("RegisterProperty() error = %v", err)
+	}
+	if err := RegisterProperty("--test-broken", "[ a"); !errors.Is(err, ErrInvalidGrammar) {
+		t.Fatalf("RegisterProperty() error = %v, want %v", err, ErrInvalidGrammar)
+	}
+
+	tests := []struct {
+		decl string
+		want error
+	}{
+		{decl: "--test-sizes:small 1px"},
+		{decl: "--test-sizes:var(--a)"},
+		{decl: "--test-sizes:huge", want: ErrInvalidValue},
+		{decl: "--test-sizes:small small small", want: ErrInvalidValue},
+		{decl: "--test-other:anything"},
+		{decl: "color:blue"},
+		{decl: "colour:blue", want: ErrUnknownProperty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.decl, func(t *testing.T) {
+			err := ValidateDeclaration(testDeclarations(tt.decl)[0])
+			if tt.want == nil && err != nil || !errors.Is(err, tt.want) {
+				t.Errorf("ValidateDeclaration() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
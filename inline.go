@@ -0,0 +1,241 @@
+package css2json
+
+import (
+	"sort"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Inline writes declarations of rulesets matching elements of document to
+// their style attributes, like it is needed for HTML emails. Declarations
+// are cascaded with the existing style attribute and written as declared
+// in cascade order, leaving out those overridden for every property they
+// set. Logical properties are written as physical ones, shorthands are
+// collapsed where possible and !important is kept.
+// Rules which can't be inlined, like @media, @font-face and selectors with
+// pseudo-elements or dynamic pseudo-classes, are encoded to a style element
+// appended to head. Nested rules are flattened.
+func Inline(s Statements, document *html.Node) error {
+	inlined, rest := splitInlinable(withoutNesting(s))
+
+	cc := &cascadeContext{
+		cascade: &Cascade{Sheets: []StyleSheet{{Origin: Author, Statements: inlined}}},
+		styles:  map[*html.Node]Style{},
+	}
+	cc.collect()
+
+	var walk func(n *html.Node) error
+	walk = func(n *html.Node) error {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || unstyledElements[c.DataAtom] {
+				continue
+			}
+			if err := cc.inline(c); err != nil {
+				return err
+			}
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(document); err != nil {
+		return err
+	}
+
+	if len(rest) == 0 {
+		return nil
+	}
+
+	css, err := Encode(rest)
+	if err != nil {
+		return err
+	}
+
+	style := &html.Node{Type: html.ElementNode, DataAtom: atom.Style, Data: "style"}
+	style.AppendChild(&html.Node{Type: html.TextNode, Data: string(css)})
+	headElement(document).AppendChild(style)
+
+	return nil
+}
+
+// unstyledElements and their children are not rendered
+var unstyledElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Title: true, atom.Meta: true, atom.Link: true, atom.Base: true,
+	atom.Style: true, atom.Script: true, atom.Noscript: true, atom.Template: true,
+}
+
+// inline replaces style attribute of element matched by any rule
+func (cc *cascadeContext) inline(node *html.Node) error {
+	var inline []Declaration
+	style, ok := attribute(node, "style")
+	if ok {
+		inline, _ = ParseDeclarations(style)
+	}
+
+	winners := cc.declared(node, inline)
+
+	logical := map[*Declaration]bool{}
+	for _, w := range winners {
+		logical[w.source] = logical[w.source] || w.logical
+	}
+
+	// a declaration setting any winning longhand is written as declared,
+	// a shorthand overridden in part is followed by the overriding ones
+	matched := false
+	sources := map[*Declaration]*cascadeValue{}
+	ordered := make([]*cascadeValue, 0, len(winners))
+	for _, w := range winners {
+		matched = matched || !w.rank.inline
+		if logical[w.source] {
+			ordered = append(ordered, w)
+			continue
+		}
+		if first, ok := sources[w.source]; !ok || w.index < first.index {
+			sources[w.source] = w
+		}
+	}
+	if !matched {
+		return nil
+	}
+	for _, w := range sources {
+		ordered = append(ordered, w)
+	}
+
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.rank.less(&b.rank) || b.rank.less(&a.rank) {
+			return a.rank.less(&b.rank)
+		}
+		return a.index < b.index
+	})
+
+	rs := &Ruleset{}
+	for _, w := range ordered {
+		if !logical[w.source] {
+			rs.Declarations = append(rs.Declarations, *w.source)
+			continue
+		}
+		// logical properties are written as physical ones
+		rs.Declarations = append(rs.Declarations, Declaration{
+			Property: TextBytes(w.name),
+			Values:   withImportant(w.values, w.rank.important),
+		})
+	}
+	rs.CollapseShorthands()
+
+	value, err := EncodeDeclarations(rs.Declarations)
+	if err != nil {
+		return err
+	}
+
+	for k := range node.Attr {
+		if node.Attr[k].Namespace == "" && node.Attr[k].Key == "style" {
+			node.Attr[k].Val = string(value)
+			return nil
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "style", Val: string(value)})
+
+	return nil
+}
+
+// splitInlinable splits statements to rulesets which can be inlined and the
+// rest, rules of @layer are split keeping the layer, @charset is dropped
+func splitInlinable(s Statements) (inlined, rest Statements) {
+	for k := range s {
+		v := &s[k]
+
+		if v.AtRule != nil {
+			switch info := v.AtRule.Identifier.Information.(type) {
+			case *CharsetInformation:
+			case *LayerInformation:
+				if v.AtRule.Nested == nil {
+					inlined = append(inlined, Statement{AtRule: v.AtRule})
+					rest = append(rest, Statement{AtRule: v.AtRule})
+					break
+				}
+				nested := make(Statements, len(v.AtRule.Nested))
+				for k, i := range v.AtRule.Nested {
+					nested[k] = *i
+				}
+				i, r := splitInlinable(nested)
+				if len(i) > 0 {
+					inlined = append(inlined, layerStatement(info, i))
+				}
+				if len(r) > 0 {
+					rest = append(rest, layerStatement(info, r))
+				}
+			default:
+				rest = append(rest, Statement{AtRule: v.AtRule})
+			}
+		}
+
+		if v.Ruleset != nil {
+			var static, dynamic []Selector
+			for _, sel := range v.Ruleset.Selectors {
+				if isInlinable(&sel) {
+					static = append(static, sel)
+				} else {
+					dynamic = append(dynamic, sel)
+				}
+			}
+			if len(static) > 0 {
+				inlined = append(inlined, Statement{Ruleset: &Ruleset{Selectors: static, Declarations: v.Ruleset.Declarations}})
+			}
+			if len(dynamic) > 0 {
+				rest = append(rest, Statement{Ruleset: &Ruleset{Selectors: dynamic, Declarations: v.Ruleset.Declarations}})
+			}
+		}
+	}
+
+	return inlined, rest
+}
+
+func isInlinable(sel *Selector) bool {
+	if !isStaticSimple(&sel.Simple) {
+		return false
+	}
+	for k := range sel.Combinates {
+		if !isStaticSimple(&sel.Combinates[k].Simple) {
+			return false
+		}
+	}
+	return true
+}
+
+func layerStatement(info *LayerInformation, s Statements) Statement {
+	at := &AtRule{
+		Identifier: Identifier{Type: TextBytes("layer"), Information: info},
+		Nested:     make([]*Statement, len(s)),
+	}
+	for k := range s {
+		at.Nested[k] = &s[k]
+	}
+	return Statement{AtRule: at}
+}
+
+// headElement returns head of document or document itself
+func headElement(document *html.Node) *html.Node {
+	var find func(n *html.Node) *html.Node
+	find = func(n *html.Node) *html.Node {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom == atom.Head {
+				return c
+			}
+			if h := find(c); h != nil {
+				return h
+			}
+		}
+		return nil
+	}
+
+	if h := find(document); h != nil {
+		return h
+	}
+	return document
+}
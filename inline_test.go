@@ -0,0 +1,127 @@
+package css2json
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Statements
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "cascade with style attribute",
+			s: Statements{
+				testRule([]string{"p"}, "color:red", "margin:0 auto"),
+				testRule([]string{".lead"}, "color:blue !important", "font-size:18px"),
+				testRule([]string{"*"}, "box-sizing:border-box"),
+			},
+			in: `<p class="lead" style="font-size:20px;color:green">a</p><p>b</p><span>c</span>`,
+			want: `<html style="box-sizing:border-box"><head></head><body style="box-sizing:border-box">` +
+				`<p class="lead" style="box-sizing:border-box;margin:0 auto;font-size:20px;color:blue !important">a</p>` +
+				`<p style="box-sizing:border-box;color:red;margin:0 auto">b</p>` +
+				`<span style="box-sizing:border-box">c</span>` +
+				`</body></html>`,
+		},
+		{
+			name: "media and dynamic pseudo-classes stay in style",
+			s: Statements{
+				{
+					AtRule: &AtRule{
+						Identifier: Identifier{
+							Type:        TextBytes("charset"),
+							Information: &CharsetInformation{Value: TextBytes("utf-8")},
+						},
+					},
+				},
+				testRule([]string{"a", "a:hover"}, "color:red"),
+				testRule([]string{"a::after"}, "content:'!'"),
+				testMedia("max-width", "600px",
+					testRule([]string{"a"}, "display:block"),
+				),
+			},
+			in: `<title>t</title><a href="/" style="padding:0">x</a><b>y</b>`,
+			want: `<html><head><title>t</title><style>a:hover{color:red}a::after{content:'!'}@media (max-width:600px){a{display:block}}</style></head><body>` +
+				`<a href="/" style="color:red;padding:0">x</a><b>y</b>` +
+				`</body></html>`,
+		},
+		{
+			name: "layers",
+			s: Statements{
+				testLayer([]string{"base"},
+					testRule([]string{"td"}, "padding:4px"),
+					testRule([]string{"td:first-child"}, "padding:0"),
+					testRule([]string{"td:hover"}, "padding:2px"),
+				),
+				testRule([]string{"td"}, "padding:8px"),
+			},
+			in: `<table><tr><td>a</td></tr></table>`,
+			want: `<html><head><style>@layer base{td:hover{padding:2px}}</style></head><body>` +
+				`<table><tbody><tr><td style="padding:8px">a</td></tr></tbody></table>` +
+				`</body></html>`,
+		},
+		{
+			name: "shorthands and longhands",
+			s: Statements{
+				testRule([]string{"p.x"}, "border:1px solid red", "font:12px serif", "font-kerning:none"),
+				testRule([]string{"p"}, "border-width:2px", "margin-inline:0 auto"),
+				testRule([]string{"i"}, "border:1px solid"),
+				testRule([]string{".y"}, "border-top-width:2px"),
+			},
+			in: `<p class="x">a</p><i class="y">b</i>`,
+			want: `<html><head></head><body>` +
+				`<p class="x" style="margin-left:0;margin-right:auto;border:1px solid red;font:12px serif;font-kerning:none">a</p>` +
+				`<i class="y" style="border:1px solid;border-top-width:2px">b</i>` +
+				`</body></html>`,
+		},
+		{
+			name: "nested rules",
+			s: Statements{
+				testNested(testRule([]string{"ul"}, "margin:0"),
+					testRule([]string{"> li"}, "color:red"),
+					testRule([]string{"&:hover"}, "color:blue"),
+				),
+			},
+			in: `<ul><li>a</li></ul>`,
+			want: `<html><head><style>ul:hover{color:blue}</style></head><body>` +
+				`<ul style="margin:0"><li style="color:red">a</li></ul>` +
+				`</body></html>`,
+		},
+		{
+			name: "rule without declarations",
+			s: Statements{
+				testRule([]string{"p:focus"}),
+			},
+			in:      `<p>a</p>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Inline(tt.s, doc); (err != nil) != tt.wantErr {
+				t.Fatalf("Inline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			dst := &bytes.Buffer{}
+			if err := html.Render(dst, doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := dst.String(); got != tt.want {
+				t.Errorf("Inline() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package css2json
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Match reports whether element node matches selector. Pseudo-elements and
+// dynamic pseudo-classes like :hover never match, :scope and the nesting
+// selector match the root element.
+func Match(sel Selector, node *html.Node) bool {
+	return matchSelector(&sel, node, nil)
+}
+
+// QueryAll returns descendants of root matching any of selectors in
+// document order, :scope and the nesting selector match root
+func QueryAll(root *html.Node, selectors []Selector) []*html.Node {
+	var ret []*html.Node
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode {
+				for k := range selectors {
+					if matchSelector(&selectors[k], c, root) {
+						ret = append(ret, c)
+						break
+					}
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+
+	return ret
+}
+
+// matchSelector matches compound selectors from right to left, a relative
+// selector starting with a combinator is anchored at scope
+func matchSelector(sel *Selector, node *html.Node, scope *html.Node) bool {
+	if node == nil || node.Type != html.ElementNode {
+		return false
+	}
+
+	if len(sel.Combinates) == 0 {
+		return matchSimple(&sel.Simple, node, scope)
+	}
+
+	last := len(sel.Combinates) - 1
+	if !matchSimple(&sel.Combinates[last].Simple, node, scope) {
+		return false
+	}
+
+	return matchCombinated(sel, last, node, scope)
+}
+
+// matchCombinated reports whether the compounds before the combinator at
+// idx match relatives of node
+func matchCombinated(sel *Selector, idx int, node *html.Node, scope *html.Node) bool {
+	matchPrevious := func(n *html.Node) bool {
+		if idx == 0 {
+			if isEmptySimple(&sel.Simple) {
+				return n == scopeElement(n, scope)
+			}
+			return matchSimple(&sel.Simple, n, scope)
+		}
+		if !matchSimple(&sel.Combinates[idx-1].Simple, n, scope) {
+			return false
+		}
+		return matchCombinated(sel, idx-1, n, scope)
+	}
+
+	switch sel.Combinates[idx].Combinator {
+	case Descendant:
+		for p := parentElement(node); p != nil; p = parentElement(p) {
+			if matchPrevious(p) {
+				return true
+			}
+		}
+	case Child:
+		return matchPrevious(parentElement(node))
+	case NextSibling:
+		return matchPrevious(previousElement(node))
+	case SubsequentSibling:
+		for s := previousElement(node); s != nil; s = previousElement(s) {
+			if matchPrevious(s) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchSimple(v *Simple, node *html.Node, scope *html.Node) bool {
+	if node == nil || node.Type != html.ElementNode {
+		return false
+	}
+
+	if len(v.Element) > 0 && !matchType(string(v.Element), node) {
+		return false
+	}
+
+	if v.Nesting && node != scopeElement(node, scope) {
+		return false
+	}
+
+	for _, i := range v.IDs {
+		if value, ok := attribute(node, "id"); !ok || value != string(i) {
+			return false
+		}
+	}
+
+	if len(v.Classes) > 0 {
+		value, _ := attribute(node, "class")
+		classes := strings.Fields(value)
+		for _, c := range v.Classes {
+			if !contains(classes, string(c)) {
+				return false
+			}
+		}
+	}
+
+	for k := range v.Attributes {
+		if !matchAttribute(&v.Attributes[k], node) {
+			return false
+		}
+	}
+
+	if len(v.PseudoElements) > 0 {
+		return false
+	}
+
+	for k := range v.PseudoClasses {
+		if !matchPseudo(&v.PseudoClasses[k], node, scope) {
+			return false
+		}
+	}
+
+	for k := range v.Negations {
+		if matchSimple(&v.Negations[k], node, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchType matches type selector with optional namespace prefix, the
+// prefix is compared with the namespace of node
+func matchType(element string, node *html.Node) bool {
+	if idx := strings.IndexByte(element, '|'); idx >= 0 {
+		if prefix := element[:idx]; prefix != "*" && !strings.EqualFold(prefix, node.Namespace) {
+			return false
+		}
+		element = element[idx+1:]
+	}
+
+	return element == "" || element == "*" || strings.EqualFold(element, node.Data)
+}
+
+func matchAttribute(v *Attribute, node *html.Node) bool {
+	name := string(v.Attr)
+	if idx := strings.IndexByte(name, '|'); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	value, ok := attribute(node, name)
+	if !ok {
+		return false
+	}
+
+	want := string(v.Value)
+	if strings.EqualFold(string(v.Modifier), "i") {
+		value, want = strings.ToLower(value), strings.ToLower(want)
+	}
+
+	switch string(v.Operator) {
+	case "":
+		return true
+	case "=":
+		return value == want
+	case "~=":
+		return want != "" && contains(strings.Fields(value), want)
+	case "|=":
+		return value == want || strings.HasPrefix(value, want+"-")
+	case "^=":
+		return want != "" && strings.HasPrefix(value, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(value, want)
+	case "*=":
+		return want != "" && strings.Contains(value, want)
+	}
+
+	return false
+}
+
+// matchPseudo matches structural and logical pseudo-classes and those
+// defined by attributes of node
+func matchPseudo(v *Pseudo, node *html.Node, scope *html.Node) bool {
+	ident := strings.ToLower(string(v.Ident))
+
+	selectors := v.Selectors
+	if len(selectors) == 0 && len(v.Func) > 0 && selectorListPseudos[ident] {
+		selectors, _ = parseSelectors(v.Func)
+	}
+
+	switch ident {
+	case "is", "where", "matches", "-webkit-any", "-moz-any":
+		return matchAny(selectors, node, scope)
+	case "not":
+		return !matchAny(selectors, node, scope)
+	case "has":
+		return matchHas(selectors, node)
+	case "scope":
+		return node == scopeElement(node, scope)
+	case "root":
+		return node.Parent != nil && node.Parent.Type == html.DocumentNode
+	case "empty":
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode || c.Type == html.TextNode {
+				return false
+			}
+		}
+		return true
+	case "first-child":
+		return previousElement(node) == nil
+	case "last-child":
+		return nextElement(node) == nil
+	case "only-child":
+		return previousElement(node) == nil && nextElement(node) == nil
+	case "first-of-type":
+		return matchNth(&Nth{B: 1}, false, true, nil, node, scope)
+	case "last-of-type":
+		return matchNth(&Nth{B: 1}, true, true, nil, node, scope)
+	case "only-of-type":
+		return matchNth(&Nth{B: 1}, false, true, nil, node, scope) && matchNth(&Nth{B: 1}, true, true, nil, node, scope)
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		nth := v.Nth
+		if nth == nil {
+			var err error
+			if nth, selectors, err = parseNthArgument(ident, v.Func); err != nil {
+				return false
+			}
+		}
+		last := strings.HasPrefix(ident, "nth-last-")
+		return matchNth(nth, last, strings.HasSuffix(ident, "-of-type"), selectors, node, scope)
+	case "link", "any-link":
+		_, ok := attribute(node, "href")
+		return ok && (node.Data == "a" || node.Data == "area")
+	case "checked":
+		_, checked := attribute(node, "checked")
+		_, selected := attribute(node, "selected")
+		return checked && node.Data == "input" || selected && node.Data == "option"
+	case "disabled", "enabled":
+		if !formElements[node.Data] {
+			return false
+		}
+		_, disabled := attribute(node, "disabled")
+		return disabled == (ident == "disabled")
+	case "required", "optional":
+		if !formElements[node.Data] {
+			return false
+		}
+		_, required := attribute(node, "required")
+		return required == (ident == "required")
+	case "lang":
+		want := strings.ToLower(strings.Trim(string(v.Func), `"' `))
+		for n := node; n != nil; n = n.Parent {
+			if value, ok := attribute(n, "lang"); ok {
+				value = strings.ToLower(value)
+				return value == want || strings.HasPrefix(value, want+"-")
+			}
+		}
+	}
+
+	return false
+}
+
+var formElements = keywords("button", "input", "select", "textarea", "optgroup", "option", "fieldset")
+
+func matchAny(selectors []Selector, node *html.Node, scope *html.Node) bool {
+	for k := range selectors {
+		if matchSelector(&selectors[k], node, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHas matches relative selectors anchored at node against its
+// descendants and following siblings with their descendants
+func matchHas(selectors []Selector, node *html.Node) bool {
+	var found bool
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && !found; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			for k := range selectors {
+				rel := relativeSelector(&selectors[k])
+				if matchSelector(&rel, c, node) {
+					found = true
+					return
+				}
+			}
+			walk(c)
+		}
+	}
+
+	walk(node)
+	for s := nextElement(node); s != nil && !found; s = nextElement(s) {
+		for k := range selectors {
+			rel := relativeSelector(&selectors[k])
+			if matchSelector(&rel, s, node) {
+				return true
+			}
+		}
+		walk(s)
+	}
+
+	return found
+}
+
+// relativeSelector makes selector without leading combinator relative to
+// scope by the descendant combinator
+func relativeSelector(sel *Selector) Selector {
+	if isEmptySimple(&sel.Simple) && len(sel.Combinates) > 0 {
+		return *sel
+	}
+	return Selector{
+		Combinates: append([]Combinate{{Combinator: Descendant, Simple: sel.Simple}}, sel.Combinates...),
+	}
+}
+
+// matchNth counts siblings before or after node, of the same type or
+// matching selectors
+func matchNth(nth *Nth, last, ofType bool, selectors []Selector, node *html.Node, scope *html.Node) bool {
+	if len(selectors) > 0 && !matchAny(selectors, node, scope) {
+		return false
+	}
+
+	next := previousElement
+	if last {
+		next = nextElement
+	}
+
+	index := 1
+	for s := next(node); s != nil; s = next(s) {
+		switch {
+		case ofType && s.Data != node.Data:
+		case len(selectors) > 0 && !matchAny(selectors, s, scope):
+		default:
+			index++
+		}
+	}
+
+	return nth.Matches(index)
+}
+
+func isEmptySimple(v *Simple) bool {
+	return len(v.Element) == 0 && !v.Universal && !v.Nesting && len(v.IDs) == 0 &&
+		len(v.Classes) == 0 && len(v.Attributes) == 0 && len(v.PseudoElements) == 0 &&
+		len(v.PseudoClasses) == 0 && len(v.Negations) == 0
+}
+
+// scopeElement returns scope or the root element of document of node
+func scopeElement(node *html.Node, scope *html.Node) *html.Node {
+	if scope != nil {
+		return scope
+	}
+	for node.Parent != nil && node.Parent.Type == html.ElementNode {
+		node = node.Parent
+	}
+	return node
+}
+
+func attribute(node *html.Node, name string) (string, bool) {
+	for _, a := range node.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, name) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func parentElement(node *html.Node) *html.Node {
+	if node.Parent != nil && node.Parent.Type == html.ElementNode {
+		return node.Parent
+	}
+	return nil
+}
+
+func previousElement(node *html.Node) *html.Node {
+	for s := node.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+func nextElement(node *html.Node) *html.Node {
+	for s := node.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package css2json
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const testDocument = `<!DOCTYPE html>
+<html lang="en-US">
+<body>
+	<div id="main" class="page wide">
+		<h1 title="Hello World">Title</h1>
+		<ul class="list">
+			<li class="item first"><a href="/a" hreflang="en-GB">a</a></li>
+			<li class="item"><img src="b.png" alt=""></li>
+			<li class="item special">c</li>
+			<li class="item"></li>
+		</ul>
+		<p>one</p>
+		<p class="note">two</p>
+		<input type="checkbox" checked disabled>
+		<svg><rect></rect></svg>
+	</div>
+	<footer id="a#b"><p>three</p></footer>
+</body>
+</html>`
+
+func testQuery(t *testing.T, root *html.Node, selector string) string {
+	t.Helper()
+
+	selectors, err := parseSelectors([]byte(selector))
+	if err != nil {
+		t.Fatalf("parseSelectors(%q) error = %v", selector, err)
+	}
+
+	var ret []string
+	for _, n := range QueryAll(root, selectors) {
+		name := n.Data
+		if id, ok := attribute(n, "id"); ok {
+			name += "#" + id
+		}
+		if class, ok := attribute(n, "class"); ok {
+			name += "." + strings.Join(strings.Fields(class), ".")
+		}
+		ret = append(ret, name)
+	}
+
+	return strings.Join(ret, " ")
+}
+
+func TestQueryAll(t *testing.T) {
+	root, err := html.Parse(strings.NewReader(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{selector: "#main > h1", want: "h1"},
+		{selector: "div#main.page.wide", want: "div#main.page.wide"},
+		{selector: `#a\#b`, want: "footer#a#b"},
+		{selector: "#a#b", want: ""},
+		{selector: ".list li:first-child a", want: "a"},
+		{selector: "li.item + li", want: "li.item li.item.special li.item"},
+		{selector: "h1 ~ p", want: "p p.note"},
+		{selector: "body p", want: "p p.note p"},
+		{selector: "div p, footer > p", want: "p p.note p"},
+		{selector: "li:nth-child(odd)", want: "li.item.first li.item.special"},
+		{selector: "li:nth-last-child(1)", want: "li.item"},
+		{selector: "li:nth-child(2 of .item)", want: "li.item"},
+		{selector: "p:first-of-type", want: "p p"},
+		{selector: "p:only-of-type", want: "p"},
+		{selector: "li:empty", want: "li.item"},
+		{selector: "li:not(.first, .special)", want: "li.item li.item"},
+		{selector: "li:is(.first, .special)", want: "li.item.first li.item.special"},
+		{selector: "li:has(> a, img)", want: "li.item.first li.item"},
+		{selector: "h1:has(+ ul)", want: "h1"},
+		{selector: "[title]", want: "h1"},
+		{selector: "[title~=World]", want: "h1"},
+		{selector: "[title^=hello i]", want: "h1"},
+		{selector: "[title$=World]", want: "h1"},
+		{selector: `[title*="o W"]`, want: "h1"},
+		{selector: "[hreflang|=en]", want: "a"},
+		{selector: `img[alt=""]`, want: "img"},
+		{selector: "input:checked:disabled", want: "input"},
+		{selector: "a:link", want: "a"},
+		{selector: "p:lang(en)", want: "p p.note p"},
+		{selector: "svg|rect", want: "rect"},
+		{selector: ":root", want: "html"},
+		{selector: "a:hover", want: ""},
+		{selector: "p::before", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			if got := testQuery(t, root, tt.selector); got != tt.want {
+				t.Errorf("QueryAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	root, err := html.Parse(strings.NewReader(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selectors, _ := parseSelectors([]byte("li.item"))
+	items := QueryAll(root, selectors)
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "ul > li", want: true},
+		{selector: ".list .first", want: true},
+		{selector: "body > li", want: false},
+		{selector: "li:last-child", want: false},
+		{selector: "* li", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, _ := parseSelectors([]byte(tt.selector))
+			if got := Match(sel[0], items[0]); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
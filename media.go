@@ -0,0 +1,399 @@
+package css2json
+
+import (
+	"bytes"
+	"math"
+	"strings"
+)
+
+// MediaOrder is an order of @media blocks merged by MergeMedia
+type MediaOrder int
+
+// Media orders
+const (
+	// SourceOrder keeps blocks in order of the first appearance of a query
+	SourceOrder MediaOrder = iota
+	// MobileFirst places min-width queries ascending, then max-width
+	// descending, then other queries
+	MobileFirst
+	// DesktopFirst places max-width queries descending, then min-width
+	// ascending, then other queries
+	DesktopFirst
+)
+
+// MergeMedia returns statements where @media blocks with equivalent media
+// queries are merged into one and moved after other rules in order. A block
+// is moved past a statement only if it can't change the cascade: the rules
+// don't share a property with the same importance and equal specificity, or
+// their subjects have different types or IDs. Otherwise the block stays in
+// front of the statement, so some queries may be repeated. Nested
+// statements of at-rules are merged too.
+func MergeMedia(s Statements, order MediaOrder) Statements {
+	items := make([]mediaItem, len(s))
+	queries := map[string]int{}
+	for k := range s {
+		v := s[k]
+		if v.AtRule != nil && v.AtRule.Nested != nil {
+			nested := make(Statements, len(v.AtRule.Nested))
+			for j, i := range v.AtRule.Nested {
+				nested[j] = *i
+			}
+			at := *v.AtRule
+			at.Nested = nil
+			for _, i := range MergeMedia(nested, order) {
+				n := i
+				at.Nested = append(at.Nested, &n)
+			}
+			if at.Nested == nil {
+				at.Nested = []*Statement{}
+			}
+			v.AtRule = &at
+		}
+
+		items[k] = mediaItem{statement: v, index: k, footprint: newFootprint(&v)}
+		if info, ok := mediaOf(&v); ok {
+			buf := &bytes.Buffer{}
+			info.encode(buf)
+			items[k].query = buf.String()
+			if _, ok := queries[items[k].query]; !ok {
+				queries[items[k].query] = k
+			}
+			items[k].first = queries[items[k].query]
+			items[k].rank, items[k].width = mediaRank(info, order)
+		} else {
+			items[k].first = k
+		}
+	}
+
+	// a statement must stay after earlier statements it conflicts with
+	before := make([]int, len(items))
+	after := make([][]int, len(items))
+	for j := range items {
+		for i := 0; i < j; i++ {
+			if items[i].footprint.conflicts(items[j].footprint) {
+				before[j]++
+				after[i] = append(after[i], j)
+			}
+		}
+	}
+
+	var (
+		ret  Statements
+		last *mediaItem
+		done = make([]bool, len(items))
+	)
+	for range items {
+		// a block of the last query is taken first to be merged
+		var next *mediaItem
+		for k := range items {
+			if done[k] || before[k] > 0 {
+				continue
+			}
+			if last != nil && last.query != "" && items[k].query == last.query {
+				next = &items[k]
+				break
+			}
+			if next == nil || items[k].less(next) {
+				next = &items[k]
+			}
+		}
+		done[next.index] = true
+		for _, j := range after[next.index] {
+			before[j]--
+		}
+
+		if last != nil && last.query != "" && last.query == next.query {
+			at := *ret[len(ret)-1].AtRule
+			at.Nested = append(append([]*Statement{}, at.Nested...), next.statement.AtRule.Nested...)
+			ret[len(ret)-1].AtRule = &at
+			continue
+		}
+
+		ret = append(ret, next.statement)
+		last = next
+	}
+
+	return ret
+}
+
+// mediaItem is a statement with its place in the order of MergeMedia
+type mediaItem struct {
+	statement Statement
+	footprint *footprint
+	query     string
+	rank      int
+	width     float64
+	first     int
+	index     int
+}
+
+func (v *mediaItem) less(o *mediaItem) bool {
+	switch {
+	case v.rank != o.rank:
+		return v.rank < o.rank
+	case v.width != o.width:
+		return v.width < o.width
+	case v.first != o.first:
+		return v.first < o.first
+	}
+	return v.index < o.index
+}
+
+func mediaOf(v *Statement) (*MediaInformation, bool) {
+	if v.AtRule == nil || v.Ruleset != nil || v.AtRule.Nested == nil {
+		return nil, false
+	}
+	info, ok := v.AtRule.Identifier.Information.(*MediaInformation)
+	return info, ok
+}
+
+// mediaRank returns the group of media in order and the key of sorting
+// inside the group, statements other than @media have rank 0
+func mediaRank(info *MediaInformation, order MediaOrder) (int, float64) {
+	if order == SourceOrder {
+		return 1, 0
+	}
+
+	min, max := math.NaN(), math.NaN()
+	for _, q := range info.Queries {
+		for _, c := range q.Conditions {
+			px, ok := lengthPixels(string(c.Value))
+			if !ok || len(c.Operator) > 0 && !strings.EqualFold(string(c.Operator), "and") {
+				continue
+			}
+			switch strings.ToLower(string(c.Feature)) {
+			case "min-width":
+				if math.IsNaN(min) {
+					min = px
+				}
+			case "max-width":
+				if math.IsNaN(max) {
+					max = px
+				}
+			}
+		}
+	}
+
+	switch {
+	case !math.IsNaN(min) && (order == MobileFirst || math.IsNaN(max)):
+		if order == MobileFirst {
+			return 1, min
+		}
+		return 2, min
+	case !math.IsNaN(max):
+		if order == DesktopFirst {
+			return 1, -max
+		}
+		return 2, -max
+	}
+	return 3, 0
+}
+
+// lengthPixels converts px, em and rem to pixels, em and rem are 16px
+func lengthPixels(s string) (float64, bool) {
+	n, unit, ok := splitDimension(strings.TrimSpace(s))
+	switch {
+	case !ok:
+		return 0, false
+	case unit == "px" || unit == "" && n == 0:
+		return n, true
+	case unit == "em" || unit == "rem":
+		return n * 16, true
+	}
+	return 0, false
+}
+
+// footprint is what a statement changes in the cascade
+type footprint struct {
+	// barrier can't be moved past anything, like @charset or @layer
+	barrier   bool
+	keyframes map[string]bool
+	fontFace  bool
+	rules     []footprintRule
+}
+
+type footprintRule struct {
+	selectors   []Selector
+	specificity []specificity
+	properties  map[string]bool
+	important   map[string]bool
+}
+
+func newFootprint(v *Statement) *footprint {
+	ret := &footprint{keyframes: map[string]bool{}}
+	ret.add(v)
+	return ret
+}
+
+func (f *footprint) add(v *Statement) {
+	if v.AtRule != nil {
+		switch info := v.AtRule.Identifier.Information.(type) {
+		case *KeyframesInformation:
+			f.keyframes[string(info.Value)] = true
+		case *FontFaceInformation:
+			f.fontFace = true
+		case *MediaInformation:
+			for _, i := range v.AtRule.Nested {
+				f.add(i)
+			}
+		default:
+			f.barrier = true
+		}
+	}
+
+	if v.Ruleset != nil && len(v.Ruleset.Nested) > 0 {
+		for _, i := range Flatten(Statements{{Ruleset: v.Ruleset}}) {
+			f.add(&i)
+		}
+		return
+	}
+
+	if v.Ruleset != nil {
+		r := footprintRule{selectors: v.Ruleset.Selectors, properties: map[string]bool{}, important: map[string]bool{}}
+		for _, sel := range v.Ruleset.Selectors {
+			var s specificity
+			s[0], s[1], s[2] = sel.Specificity()
+			r.specificity = append(r.specificity, s)
+		}
+		for _, d := range v.Ruleset.Declarations {
+			name := string(d.Property)
+			if !isCustomProperty(d.Property) {
+				name = strings.ToLower(name)
+			}
+			if _, imp := splitImportant(d.Values); imp {
+				r.important[name] = true
+			} else {
+				r.properties[name] = true
+			}
+		}
+		f.rules = append(f.rules, r)
+	}
+}
+
+// conflicts reports whether the order of statements matters
+func (f *footprint) conflicts(o *footprint) bool {
+	if f.barrier || o.barrier || f.fontFace && o.fontFace {
+		return true
+	}
+	for name := range f.keyframes {
+		if o.keyframes[name] {
+			return true
+		}
+	}
+
+	for _, a := range f.rules {
+		for _, b := range o.rules {
+			if a.overlaps(&b) &&
+				(relatedProperties(a.properties, b.properties) || relatedProperties(a.important, b.important)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// overlaps reports whether rules have selectors of equal specificity which
+// may match the same element, their order decides then
+func (r *footprintRule) overlaps(o *footprintRule) bool {
+	for i := range r.selectors {
+		for j := range o.selectors {
+			if r.specificity[i] == o.specificity[j] && !disjointSelectors(&r.selectors[i], &o.selectors[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// disjointSelectors reports whether subjects of selectors have different
+// types or IDs, so they never match the same element. Types are compared
+// only with the same namespace prefix, as a|x or *|x may match x.
+func disjointSelectors(a, b *Selector) bool {
+	subject := func(s *Selector) *Simple {
+		if n := len(s.Combinates); n > 0 {
+			return &s.Combinates[n-1].Simple
+		}
+		return &s.Simple
+	}
+	x, y := subject(a), subject(b)
+
+	xPrefix, xName := splitType(x.Element)
+	yPrefix, yName := splitType(y.Element)
+	if len(xName) > 0 && len(yName) > 0 && bytes.EqualFold(xPrefix, yPrefix) && !bytes.EqualFold(xName, yName) {
+		return true
+	}
+
+	for _, i := range x.IDs {
+		for _, j := range y.IDs {
+			if !bytes.Equal(i, j) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// splitType splits type selector to namespace prefix with the bar and
+// local name, the prefix is nil without namespace
+func splitType(element []byte) (prefix, name []byte) {
+	if idx := bytes.IndexByte(element, '|'); idx >= 0 {
+		return element[:idx+1], element[idx+1:]
+	}
+	return nil, element
+}
+
+func relatedProperties(a, b map[string]bool) bool {
+	for i := range a {
+		for j := range b {
+			if isRelatedProperty(i, j) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// propertyGroups join properties setting the same longhands, which don't
+// share the first word of the name, by the name or its first word. Logical
+// properties are joined with all physical ones they may map to.
+var propertyGroups = map[string]string{
+	"inset": "top", "right": "top", "bottom": "top", "left": "top",
+	"width": "size", "height": "size", "inline-size": "size", "block-size": "size",
+	"gap": "gap", "row-gap": "gap", "column-gap": "gap", "grid-gap": "gap", "grid-row-gap": "gap", "grid-column-gap": "gap",
+	"place-content": "align", "place-items": "align", "place-self": "align",
+	"justify-content": "align", "justify-items": "align", "justify-self": "align",
+	"columns": "column", "line-height": "font", "white": "text", "word-wrap": "overflow", "page": "break",
+}
+
+// isRelatedProperty reports whether a and b may set the same longhand, like
+// margin and margin-top, font and line-height, width and inline-size,
+// -webkit-transition and transition or all and any
+func isRelatedProperty(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if isCustomProperty(TextBytes(a)) || isCustomProperty(TextBytes(b)) {
+		return false
+	}
+	return a == "all" || b == "all" || propertyGroup(a) == propertyGroup(b)
+}
+
+func propertyGroup(name string) string {
+	if strings.HasPrefix(name, "-") {
+		if idx := strings.IndexByte(name[1:], '-'); idx >= 0 {
+			name = name[idx+2:]
+		}
+	}
+	if g, ok := propertyGroups[name]; ok {
+		return g
+	}
+	if idx := strings.IndexByte(name, '-'); idx > 0 {
+		name = name[:idx]
+	}
+	if g, ok := propertyGroups[name]; ok {
+		return g
+	}
+	return name
+}
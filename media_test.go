@@ -0,0 +1,162 @@
+package css2json
+
+import (
+	"testing"
+)
+
+func TestMergeMedia(t *testing.T) {
+	min := func(width string, nested ...Statement) Statement {
+		return testMedia("min-width", width, nested...)
+	}
+	max := func(width string, nested ...Statement) Statement {
+		return testMedia("max-width", width, nested...)
+	}
+
+	s := Statements{
+		testRule([]string{".a"}, "color:red"),
+		min("768px", testRule([]string{".a"}, "color:blue")),
+		max("600px", testRule([]string{".b"}, "top:0")),
+		min("1024px", testRule([]string{".a"}, "margin:0")),
+		testRule([]string{".b"}, "padding:0"),
+		min("768px", testRule([]string{".b"}, "color:green")),
+		min("40em", testRule([]string{".c"}, "width:1px")),
+		max("1024px", testRule([]string{"#d"}, "top:0")),
+		testMediaType("print", testRule([]string{".a"}, "display:none")),
+	}
+
+	tests := []struct {
+		name  string
+		s     Statements
+		order MediaOrder
+		want  string
+	}{
+		{
+			name:  "source order",
+			s:     s,
+			order: SourceOrder,
+			want: `.a{color:red}.b{padding:0}` +
+				`@media (min-width:768px){.a{color:blue}.b{color:green}}` +
+				`@media (max-width:600px){.b{top:0}}` +
+				`@media (min-width:1024px){.a{margin:0}}` +
+				`@media (min-width:40em){.c{width:1px}}` +
+				`@media (max-width:1024px){#d{top:0}}` +
+				`@media print{.a{display:none}}`,
+		},
+		{
+			name:  "mobile first",
+			s:     s,
+			order: MobileFirst,
+			want: `.a{color:red}.b{padding:0}` +
+				`@media (min-width:40em){.c{width:1px}}` +
+				`@media (min-width:768px){.a{color:blue}.b{color:green}}` +
+				`@media (min-width:1024px){.a{margin:0}}` +
+				`@media (max-width:1024px){#d{top:0}}` +
+				`@media (max-width:600px){.b{top:0}}` +
+				`@media print{.a{display:none}}`,
+		},
+		{
+			name:  "desktop first",
+			s:     s,
+			order: DesktopFirst,
+			want: `.a{color:red}.b{padding:0}` +
+				`@media (max-width:1024px){#d{top:0}}` +
+				`@media (max-width:600px){.b{top:0}}` +
+				`@media (min-width:40em){.c{width:1px}}` +
+				`@media (min-width:768px){.a{color:blue}.b{color:green}}` +
+				`@media (min-width:1024px){.a{margin:0}}` +
+				`@media print{.a{display:none}}`,
+		},
+		{
+			name: "cascade is kept",
+			s: Statements{
+				min("768px", testRule([]string{".a"}, "margin-top:1px")),
+				testRule([]string{".b"}, "margin:0"),
+				testRule([]string{"#c"}, "margin:0"),
+				min("768px", testRule([]string{".a"}, "color:red")),
+				min("1024px", testRule([]string{".a"}, "color:blue")),
+				min("768px", testRule([]string{".a"}, "color:green")),
+			},
+			order: MobileFirst,
+			want: `#c{margin:0}@media (min-width:768px){.a{margin-top:1px}.a{color:red}}.b{margin:0}` +
+				`@media (min-width:1024px){.a{color:blue}}` +
+				`@media (min-width:768px){.a{color:green}}`,
+		},
+		{
+			name: "nested and barriers",
+			s: Statements{
+				testLayer([]string{"base"},
+					min("768px", testRule([]string{"a"}, "color:red")),
+					testRule([]string{"b"}, "color:red"),
+					min("768px", testRule([]string{"i"}, "color:red")),
+				),
+				min("768px", testRule([]string{"a"}, "top:0")),
+				testLayer([]string{"theme"}),
+				testRule([]string{"b"}, "top:0"),
+			},
+			order: MobileFirst,
+			want: `@layer base{b{color:red}@media (min-width:768px){a{color:red}i{color:red}}}` +
+				`@media (min-width:768px){a{top:0}}@layer theme;b{top:0}`,
+		},
+		{
+			name: "shorthands and logical properties",
+			s: Statements{
+				min("768px", testRule([]string{".a"}, "line-height:2")),
+				testRule([]string{".b"}, "font:12px serif"),
+				min("768px", testRule([]string{".c"}, "inline-size:1px")),
+				testRule([]string{".d"}, "width:2px"),
+				min("768px", testRule([]string{".e"}, "inset-inline-start:0")),
+				testRule([]string{".f"}, "left:1px"),
+				min("768px", testRule([]string{".g"}, "block-size:0")),
+				testRule([]string{".h"}, "height:1px"),
+			},
+			order: MobileFirst,
+			want: `@media (min-width:768px){.a{line-height:2}.c{inline-size:1px}.e{inset-inline-start:0}}` +
+				`.b{font:12px serif}.d{width:2px}.f{left:1px}` +
+				`@media (min-width:768px){.g{block-size:0}}.h{height:1px}`,
+		},
+		{
+			name: "namespaced types",
+			s: Statements{
+				testRule([]string{"a"}, "color:blue"),
+				min("768px", testRule([]string{"a"}, "color:red")),
+				testRule([]string{"*|a"}, "color:green"),
+				min("768px", testRule([]string{"b"}, "color:red")),
+				testRule([]string{"svg|b"}, "color:green"),
+			},
+			order: MobileFirst,
+			want: `a{color:blue}@media (min-width:768px){a{color:red}}*|a{color:green}` +
+				`@media (min-width:768px){b{color:red}}svg|b{color:green}`,
+		},
+		{
+			name: "nested rules",
+			s: Statements{
+				min("10px", testRule([]string{".a .b"}, "color:blue")),
+				testNested(testRule([]string{".a"}), testRule([]string{".b"}, "color:red")),
+				min("10px", testRule([]string{".c"}, "color:blue")),
+				testNested(testRule([]string{".d"}), testRule([]string{"&:hover"}, "margin:0")),
+			},
+			order: MobileFirst,
+			want:  `.d{&:hover{margin:0}}@media (min-width:10px){.a .b{color:blue}.c{color:blue}}.a{.b{color:red}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(MergeMedia(tt.s, tt.order))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MergeMedia() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// testMediaType builds @media with a media type query like "print"
+func testMediaType(mediaType string, nested ...Statement) Statement {
+	ret := testMedia("", "", nested...)
+	ret.AtRule.Identifier.Information = &MediaInformation{
+		Queries: []Query{{Type: &Type{Value: TextBytes(mediaType)}}},
+	}
+	return ret
+}
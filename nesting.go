@@ -0,0 +1,249 @@
+package css2json
+
+import (
+	"bytes"
+)
+
+// Flatten returns statements with nested rules of CSS Nesting written as
+// plain rules for browsers without nesting support. A nested selector is
+// joined with every selector of the parent rule, or with :is() of them when
+// it has more than one "&" or one in :not(): "&" is replaced by the
+// parent selector merged into its compound, or by :is() with it when both
+// have a type selector or when "&" follows a combinator and the parent
+// selector has combinators. A relative selector or one without "&" follows
+// the parent one. A nested rule follows its parent rule, a nested at-rule is
+// moved out of the rule with its contents joined to the parent selectors.
+// Statements are not changed.
+//
+// Purge, Critical, Inline, ComputeStyle and ResolveVars flatten nested
+// rules themselves as they match selectors of plain rules.
+func Flatten(s Statements) Statements {
+	var ret Statements
+	for k := range s {
+		ret = append(ret, flattenStatement(&s[k], nil)...)
+	}
+	return ret
+}
+
+// withoutNesting returns statements flattened when they have nested rules,
+// otherwise statements as is
+func withoutNesting(s Statements) Statements {
+	for k := range s {
+		if hasNestedRules(&s[k]) {
+			return Flatten(s)
+		}
+	}
+	return s
+}
+
+// hasNestedRules reports whether a ruleset of the statement or of its
+// nested statements has nested statements
+func hasNestedRules(v *Statement) bool {
+	if v.Ruleset != nil && len(v.Ruleset.Nested) > 0 {
+		return true
+	}
+	if v.AtRule != nil {
+		for _, i := range v.AtRule.Nested {
+			if hasNestedRules(i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flattenStatement returns the statement with nested rules written after
+// it, selectors are joined to parents unless they are nil
+func flattenStatement(v *Statement, parents []Selector) Statements {
+	var ret Statements
+
+	if v.AtRule != nil {
+		at := &AtRule{Identifier: v.AtRule.Identifier}
+		if _, ok := v.AtRule.Identifier.Information.(*KeyframesInformation); ok {
+			parents = nil
+		}
+		if v.AtRule.Nested != nil {
+			at.Nested = []*Statement{}
+			for _, i := range v.AtRule.Nested {
+				for _, n := range flattenStatement(i, parents) {
+					n := n
+					at.Nested = append(at.Nested, &n)
+				}
+			}
+		}
+		ret = append(ret, Statement{AtRule: at})
+	}
+
+	if v.Ruleset != nil {
+		selectors := v.Ruleset.Selectors
+		if parents != nil {
+			selectors = nestSelectors(selectors, parents)
+		}
+		if len(v.Ruleset.Declarations) > 0 {
+			ret = append(ret, Statement{Ruleset: &Ruleset{Selectors: selectors, Declarations: v.Ruleset.Declarations}})
+		}
+		for _, i := range v.Ruleset.Nested {
+			ret = append(ret, flattenStatement(i, selectors)...)
+		}
+	}
+
+	return ret
+}
+
+// nestSelectors joins every selector to every parent one
+func nestSelectors(selectors, parents []Selector) []Selector {
+	var ret []Selector
+
+	for _, s := range selectors {
+		// "&" matches any parent, a selector using it more than once or in
+		// :not() is not the same when joined with every parent one
+		if n, negated := countNesting(&s, false); len(parents) > 1 && (n > 1 || negated) {
+			parent := Selector{Simple: Simple{PseudoClasses: []Pseudo{{Ident: TextBytes("is"), Selectors: parents}}}}
+			ret = append(ret, replaceNesting(&s, &parent))
+			continue
+		}
+
+		for _, p := range parents {
+			if hasNesting(&s) {
+				ret = append(ret, replaceNesting(&s, &p))
+				continue
+			}
+
+			sel := Selector{Simple: p.Simple, Combinates: append([]Combinate{}, p.Combinates...)}
+			if !isEmptySimple(&s.Simple) || len(s.Combinates) == 0 {
+				sel.Combinates = append(sel.Combinates, Combinate{Combinator: Descendant, Simple: s.Simple})
+			}
+			sel.Combinates = append(sel.Combinates, s.Combinates...)
+			ret = append(ret, sel)
+		}
+	}
+
+	return ret
+}
+
+// hasNesting reports whether the selector uses "&" in a compound or in a
+// selector argument of a pseudo-class
+func hasNesting(s *Selector) bool {
+	for _, c := range compounds(s) {
+		if c.Simple.Nesting {
+			return true
+		}
+		for _, pseudos := range [][]Pseudo{c.Simple.PseudoElements, c.Simple.PseudoClasses} {
+			for _, p := range pseudos {
+				for k := range p.Selectors {
+					if hasNesting(&p.Selectors[k]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// countNesting returns the number of "&" in the selector and whether any
+// of them is in an argument of :not()
+func countNesting(s *Selector, negated bool) (int, bool) {
+	n, ret := 0, false
+	for _, c := range compounds(s) {
+		if c.Simple.Nesting {
+			n++
+			ret = ret || negated
+		}
+		for _, pseudos := range [][]Pseudo{c.Simple.PseudoElements, c.Simple.PseudoClasses} {
+			for _, p := range pseudos {
+				not := negated || bytes.EqualFold(p.Ident, []byte("not"))
+				for k := range p.Selectors {
+					count, neg := countNesting(&p.Selectors[k], not)
+					n, ret = n+count, ret || neg
+				}
+			}
+		}
+	}
+	return n, ret
+}
+
+// compounds returns compounds of the selector, the first one is combined
+// by Descendant
+func compounds(s *Selector) []Combinate {
+	return append([]Combinate{{Combinator: Descendant, Simple: s.Simple}}, s.Combinates...)
+}
+
+// replaceNesting returns the selector with "&" replaced by parent
+func replaceNesting(s, parent *Selector) Selector {
+	var ret []Combinate
+
+	for k, c := range compounds(s) {
+		simple := replacePseudoNesting(c.Simple, parent)
+		if !simple.Nesting {
+			ret = append(ret, Combinate{Combinator: c.Combinator, Simple: simple})
+			continue
+		}
+		simple.Nesting = false
+
+		chain := compounds(parent)
+		last := chain[len(chain)-1].Simple
+		merged, ok := mergeSimple(&last, &simple)
+		if !ok || k > 0 && len(chain) > 1 {
+			simple.PseudoClasses = append([]Pseudo{{Ident: TextBytes("is"), Selectors: []Selector{*parent}}}, simple.PseudoClasses...)
+			ret = append(ret, Combinate{Combinator: c.Combinator, Simple: simple})
+			continue
+		}
+		chain[0].Combinator = c.Combinator
+		chain[len(chain)-1].Simple = merged
+		ret = append(ret, chain...)
+	}
+
+	return Selector{Simple: ret[0].Simple, Combinates: ret[1:]}
+}
+
+// replacePseudoNesting returns the compound with "&" in selector arguments
+// of pseudo-classes replaced by parent
+func replacePseudoNesting(v Simple, parent *Selector) Simple {
+	replace := func(pseudos []Pseudo) []Pseudo {
+		if pseudos == nil {
+			return nil
+		}
+		ret := make([]Pseudo, len(pseudos))
+		for k, p := range pseudos {
+			ret[k] = p
+			if len(p.Selectors) == 0 {
+				continue
+			}
+			ret[k].Selectors = make([]Selector, len(p.Selectors))
+			for j := range p.Selectors {
+				ret[k].Selectors[j] = p.Selectors[j]
+				if hasNesting(&p.Selectors[j]) {
+					ret[k].Selectors[j] = replaceNesting(&p.Selectors[j], parent)
+				}
+			}
+		}
+		return ret
+	}
+
+	v.PseudoElements = replace(v.PseudoElements)
+	v.PseudoClasses = replace(v.PseudoClasses)
+	return v
+}
+
+// mergeSimple returns one compound matching both, it fails when both have
+// a type selector
+func mergeSimple(a, b *Simple) (Simple, bool) {
+	if len(a.Element) > 0 && len(b.Element) > 0 {
+		return Simple{}, false
+	}
+
+	ret := Simple{
+		Element:        append(append(TextBytes(nil), a.Element...), b.Element...),
+		IDs:            append(append([]TextBytes(nil), a.IDs...), b.IDs...),
+		Classes:        append(append([]TextBytes(nil), a.Classes...), b.Classes...),
+		Attributes:     append(append([]Attribute(nil), a.Attributes...), b.Attributes...),
+		PseudoElements: append(append([]Pseudo(nil), a.PseudoElements...), b.PseudoElements...),
+		PseudoClasses:  append(append([]Pseudo(nil), a.PseudoClasses...), b.PseudoClasses...),
+		Negations:      append(append([]Simple(nil), a.Negations...), b.Negations...),
+	}
+	ret.Universal = (a.Universal || b.Universal) &&
+		(len(ret.Element) == 0 || bytes.HasSuffix(ret.Element, []byte{'|'}))
+
+	return ret, true
+}
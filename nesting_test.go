@@ -0,0 +1,108 @@
+package css2json
+
+import (
+	"testing"
+)
+
+// testNested adds nested statements to a rule built by testRule
+func testNested(rule Statement, nested ...Statement) Statement {
+	rule.Ruleset.Nested = []*Statement{}
+	for k := range nested {
+		rule.Ruleset.Nested = append(rule.Ruleset.Nested, &nested[k])
+	}
+	return rule
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      Statements
+		nested string
+		want   string
+	}{
+		{
+			name: "nesting selector",
+			s: Statements{
+				testNested(testRule([]string{".card", "#main a"}, "color:red"),
+					testRule([]string{"&:hover", "&.active > b"}, "color:blue"),
+					testRule([]string{".dark &"}, "color:white"),
+					testRule([]string{"span&"}, "margin:0"),
+				),
+			},
+			nested: `.card,#main a{color:red;&:hover,&.active>b{color:blue}.dark &{color:white}span&{margin:0}}`,
+			want: `.card,#main a{color:red}` +
+				`.card:hover,#main a:hover,.card.active>b,#main a.active>b{color:blue}` +
+				`.dark .card,.dark :is(#main a){color:white}` +
+				`span.card,span:is(#main a){margin:0}`,
+		},
+		{
+			name: "relative selectors",
+			s: Statements{
+				testNested(testRule([]string{"ul"}),
+					testNested(testRule([]string{"> li", "a"}, "padding:0"),
+						testRule([]string{"+ li"}, "margin:0"),
+						testRule([]string{":not(&)"}, "color:gray"),
+					),
+				),
+			},
+			nested: `ul{>li,a{padding:0;+li{margin:0}:not(&){color:gray}}}`,
+			want:   `ul>li,ul a{padding:0}ul>li+li,ul a+li{margin:0}:not(:is(ul>li,ul a)){color:gray}`,
+		},
+		{
+			name: "nesting selector in :not() and more than once",
+			s: Statements{
+				testNested(testRule([]string{".a", ".b"}),
+					testRule([]string{":not(&)"}, "color:gray"),
+					testRule([]string{"& + &"}, "margin:0"),
+					testRule([]string{"&:hover"}, "color:red"),
+				),
+				testNested(testRule([]string{".c"}),
+					testRule([]string{"& + &", ":not(&)"}, "margin:0"),
+				),
+			},
+			nested: `.a,.b{:not(&){color:gray}&+&{margin:0}&:hover{color:red}}.c{&+&,:not(&){margin:0}}`,
+			want:   `:not(:is(.a,.b)){color:gray}:is(.a,.b)+:is(.a,.b){margin:0}.a:hover,.b:hover{color:red}.c+.c,:not(.c){margin:0}`,
+		},
+		{
+			name: "nested at-rules",
+			s: Statements{
+				testNested(testRule([]string{".a"}, "color:red"),
+					testMedia("min-width", "600px",
+						testRule([]string{"&"}, "color:blue"),
+						testRule([]string{".b"}, "color:green"),
+					),
+				),
+				testMediaType("print",
+					testNested(testRule([]string{"nav"}),
+						testRule([]string{"&"}, "display:none"),
+					),
+				),
+			},
+			nested: `.a{color:red;@media (min-width:600px){&{color:blue}.b{color:green}}}@media print{nav{&{display:none}}}`,
+			want:   `.a{color:red}@media (min-width:600px){.a{color:blue}.a .b{color:green}}@media print{nav{display:none}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, err := Encode(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(before) != tt.nested {
+				t.Errorf("Encode() = %s, want %s", before, tt.nested)
+			}
+
+			got, err := Encode(Flatten(tt.s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Flatten() = %s, want %s", got, tt.want)
+			}
+
+			if after, _ := Encode(tt.s); string(after) != string(before) {
+				t.Errorf("Flatten() changed statements %s", after)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidNth
+	ErrInvalidNth = errors.New("invalid An+B")
+)
+
+// nthPseudos take An+B as argument, nthOfPseudos also take "of S"
+var (
+	nthPseudos   = keywords("nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type")
+	nthOfPseudos = keywords("nth-child", "nth-last-child")
+)
+
+// Nth is the An+B argument of :nth-child() and the like
+// https://www.w3.org/TR/css-syntax-3/#anb-microsyntax
+type Nth struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// ParseNth parses An+B like "odd", "-n+3" or "2n + 1"
+func ParseNth(b []byte) (*Nth, error) {
+	s := strings.ToLower(string(bytes.TrimSpace(b)))
+
+	switch s {
+	case "odd":
+		return &Nth{A: 2, B: 1}, nil
+	case "even":
+		return &Nth{A: 2, B: 0}, nil
+	}
+
+	idx := strings.IndexByte(s, smallN)
+	if idx < 0 {
+		n, err := parseNthInteger(s, true)
+		if err != nil {
+			return nil, err
+		}
+		return &Nth{B: n}, nil
+	}
+
+	ret := &Nth{}
+	switch a := s[:idx]; a {
+	case "", "+":
+		ret.A = 1
+	case "-":
+		ret.A = -1
+	default:
+		n, err := parseNthInteger(a, true)
+		if err != nil {
+			return nil, err
+		}
+		ret.A = n
+	}
+
+	rest := strings.TrimSpace(s[idx+1:])
+	if rest == "" {
+		return ret, nil
+	}
+	if rest[0] != '+' && rest[0] != '-' {
+		return nil, ErrInvalidNth
+	}
+
+	n, err := parseNthInteger(strings.TrimSpace(rest[1:]), false)
+	if err != nil {
+		return nil, err
+	}
+	if rest[0] == '-' {
+		n = -n
+	}
+	ret.B = n
+
+	return ret, nil
+}
+
+// parseNthInteger parses digits with optional sign
+func parseNthInteger(s string, signed bool) (int, error) {
+	digits := s
+	if signed && len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		digits = digits[1:]
+	}
+	if digits == "" {
+		return 0, ErrInvalidNth
+	}
+	for k := 0; k < len(digits); k++ {
+		if !isDigit(digits[k]) {
+			return 0, ErrInvalidNth
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, ErrInvalidNth
+	}
+
+	return n, nil
+}
+
+// Canonical returns An+B matching the same elements with positive B less
+// or equal to A when A is positive
+func (v Nth) Canonical() Nth {
+	if v.A > 0 && v.B < 0 {
+		v.B %= v.A
+		if v.B < 0 {
+			v.B += v.A
+		}
+	}
+	return v
+}
+
+// String returns the canonical form, 2n+1 is "odd" and 2n is "even"
+func (v Nth) String() string {
+	v = v.Canonical()
+
+	switch {
+	case v.A == 2 && v.B == 1:
+		return "odd"
+	case v.A == 2 && v.B == 0:
+		return "even"
+	case v.A == 0:
+		return strconv.Itoa(v.B)
+	}
+
+	var ret string
+	switch v.A {
+	case 1:
+		ret = "n"
+	case -1:
+		ret = "-n"
+	default:
+		ret = strconv.Itoa(v.A) + "n"
+	}
+
+	switch {
+	case v.B > 0:
+		ret += "+" + strconv.Itoa(v.B)
+	case v.B < 0:
+		ret += strconv.Itoa(v.B)
+	}
+
+	return ret
+}
+
+// Matches reports whether the element at 1-based index matches An+B
+func (v Nth) Matches(index int) bool {
+	if v.A == 0 {
+		return index == v.B
+	}
+	diff := index - v.B
+	return diff%v.A == 0 && diff/v.A >= 0
+}
+
+func (v *Nth) encode(dst *bytes.Buffer) error {
+	_, err := dst.WriteString(v.String())
+	return err
+}
+
+// parseNthArgument parses "An+B [of S]" of pseudo-class ident
+func parseNthArgument(ident string, b []byte) (*Nth, []Selector, error) {
+	var selectors []Selector
+
+	if nthOfPseudos[ident] {
+		if idx := bytes.Index(bytes.ToLower(b), []byte(" of ")); idx >= 0 {
+			var err error
+			if selectors, err = parseSelectors(b[idx+len(" of "):]); err != nil {
+				return nil, nil, err
+			}
+			b = b[:idx]
+		}
+	}
+
+	nth, err := ParseNth(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nth, selectors, nil
+}
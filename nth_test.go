@@ -0,0 +1,98 @@
+package css2json
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNth(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    *Nth
+		str     string
+		wantErr bool
+	}{
+		{in: "odd", want: &Nth{A: 2, B: 1}, str: "odd"},
+		{in: "EVEN", want: &Nth{A: 2}, str: "even"},
+		{in: "2n+1", want: &Nth{A: 2, B: 1}, str: "odd"},
+		{in: "2n-1", want: &Nth{A: 2, B: -1}, str: "odd"},
+		{in: "2n + 0", want: &Nth{A: 2}, str: "even"},
+		{in: " -n+3 ", want: &Nth{A: -1, B: 3}, str: "-n+3"},
+		{in: "+n", want: &Nth{A: 1}, str: "n"},
+		{in: "n- 2", want: &Nth{A: 1, B: -2}, str: "n"},
+		{in: "3n-4", want: &Nth{A: 3, B: -4}, str: "3n+2"},
+		{in: "-2n-1", want: &Nth{A: -2, B: -1}, str: "-2n-1"},
+		{in: "0n+5", want: &Nth{B: 5}, str: "5"},
+		{in: "+7", want: &Nth{B: 7}, str: "7"},
+		{in: "-3", want: &Nth{B: -3}, str: "-3"},
+		{in: "", wantErr: true},
+		{in: "2n 1", wantErr: true},
+		{in: "2n+-1", wantErr: true},
+		{in: "+ 2n", wantErr: true},
+		{in: "n+", wantErr: true},
+		{in: "first", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseNth([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNth() = %v, want %v", got, tt.want)
+			}
+			if got != nil && got.String() != tt.str {
+				t.Errorf("Nth.String() = %v, want %v", got.String(), tt.str)
+			}
+		})
+	}
+}
+
+func TestNth_Matches(t *testing.T) {
+	tests := []struct {
+		nth  Nth
+		want []int
+	}{
+		{nth: Nth{A: 2, B: 1}, want: []int{1, 3, 5}},
+		{nth: Nth{A: -1, B: 3}, want: []int{1, 2, 3}},
+		{nth: Nth{B: 4}, want: []int{4}},
+		{nth: Nth{A: 3, B: -4}, want: []int{2, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nth.String(), func(t *testing.T) {
+			var got []int
+			for i := 1; i <= 6; i++ {
+				if tt.nth.Matches(i) {
+					got = append(got, i)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Nth.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSelectors_nth(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "li:nth-child(2n+1 of .item, #x)", want: "li:nth-child(odd of .item,#x)"},
+		{in: "li:NTH-LAST-OF-TYPE(-n + 2)", want: "li:NTH-LAST-OF-TYPE(-n+2)"},
+		{in: "li:nth-of-type(1 of .a)", wantErr: true},
+		{in: "li:nth-child(foo)", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSelectors([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSelectors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(encodeBytes(&got[0])) != tt.want {
+				t.Errorf("parseSelectors() = %s, want %v", encodeBytes(&got[0]), tt.want)
+			}
+		})
+	}
+}
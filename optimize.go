@@ -0,0 +1,406 @@
+package css2json
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Optimize returns smaller statements with the same meaning:
+//   - colors are written in the shortest form of the same or older syntax,
+//     zero lengths without unit unless a number has another meaning there
+//     like in "flex:1 0px"
+//   - a declaration overridden by a later one of the same property in the
+//     rule is removed when both have the same value or only CSS 2 values,
+//     others may be fallbacks of a newer syntax like "height:100vh;
+//     height:100dvh" or "display:-webkit-box;display:flex"
+//   - adjacent rules with identical selectors are merged, rules with
+//     identical declarations are merged when their selectors use only
+//     features of CSS 2 and Selectors Level 3
+//   - rules without selectors, without declarations and nested rules and
+//     empty @media are removed
+//   - nested rules of CSS Nesting are optimized the same way
+//
+// Custom properties are kept as is, statements are not changed.
+func Optimize(s Statements) Statements {
+	return optimizeStatements(s)
+}
+
+func optimizeStatements(s Statements) Statements {
+	var ret Statements
+
+	for k := range s {
+		v := optimizeStatement(&s[k])
+		if v.AtRule == nil && v.Ruleset == nil {
+			continue
+		}
+
+		if n := len(ret); n > 0 && v.AtRule == nil && ret[n-1].AtRule == nil {
+			if merged := mergeRulesets(ret[n-1].Ruleset, v.Ruleset); merged != nil {
+				ret[n-1].Ruleset = merged
+				continue
+			}
+		}
+
+		ret = append(ret, v)
+	}
+
+	return ret
+}
+
+func optimizeStatement(v *Statement) Statement {
+	var ret Statement
+
+	if v.AtRule != nil {
+		ret.AtRule = optimizeAtRule(v.AtRule)
+	}
+
+	if v.Ruleset != nil && len(v.Ruleset.Selectors) > 0 {
+		decls := removeOverridden(optimizeDeclarations(v.Ruleset.Declarations))
+		nested := optimizeNested(v.Ruleset.Nested)
+		if len(decls) > 0 || len(nested) > 0 {
+			ret.Ruleset = &Ruleset{Selectors: v.Ruleset.Selectors, Declarations: decls, Nested: nested}
+		}
+	}
+
+	return ret
+}
+
+func optimizeNested(s []*Statement) []*Statement {
+	if s == nil {
+		return nil
+	}
+
+	nested := make(Statements, len(s))
+	for k, i := range s {
+		nested[k] = *i
+	}
+
+	ret := []*Statement{}
+	for _, i := range optimizeStatements(nested) {
+		n := i
+		ret = append(ret, &n)
+	}
+	return ret
+}
+
+func optimizeAtRule(v *AtRule) *AtRule {
+	ret := &AtRule{Identifier: v.Identifier}
+
+	if info, ok := v.Identifier.Information.(*FontFaceInformation); ok {
+		ret.Identifier.Information = &FontFaceInformation{Declarations: optimizeDeclarations(info.Declarations)}
+	}
+
+	if v.Nested == nil {
+		return ret
+	}
+
+	ret.Nested = optimizeNested(v.Nested)
+
+	if _, ok := v.Identifier.Information.(*MediaInformation); ok && len(ret.Nested) == 0 {
+		return nil
+	}
+
+	return ret
+}
+
+// mergeRulesets returns a ruleset of adjacent a and b with identical
+// selectors or identical declarations, or nil. Rules with nested
+// statements are not merged.
+func mergeRulesets(a, b *Ruleset) *Ruleset {
+	if len(a.Nested) > 0 || len(b.Nested) > 0 {
+		return nil
+	}
+
+	if bytes.Equal(selectorsBytes(a.Selectors), selectorsBytes(b.Selectors)) {
+		decls := append(append([]Declaration{}, a.Declarations...), b.Declarations...)
+		return &Ruleset{Selectors: a.Selectors, Declarations: removeOverridden(decls)}
+	}
+
+	if !bytes.Equal(declarationsBytes(a.Declarations), declarationsBytes(b.Declarations)) ||
+		!isPlainSelectors(a.Selectors) || !isPlainSelectors(b.Selectors) {
+		return nil
+	}
+
+	ret := &Ruleset{Selectors: append([]Selector{}, a.Selectors...), Declarations: a.Declarations}
+	seen := map[string]bool{}
+	for k := range a.Selectors {
+		seen[string(selectorsBytes(a.Selectors[k:k+1]))] = true
+	}
+	for k := range b.Selectors {
+		if key := string(selectorsBytes(b.Selectors[k : k+1])); !seen[key] {
+			seen[key] = true
+			ret.Selectors = append(ret.Selectors, b.Selectors[k])
+		}
+	}
+
+	return ret
+}
+
+// isPlainSelectors reports whether selectors use only features of CSS 2
+// and Selectors Level 3, a browser not knowing a newer one like :is(),
+// :has() or a vendor prefixed pseudo-class drops the rule with the whole
+// selector list. Selectors are not changed as they may be shared.
+func isPlainSelectors(selectors []Selector) bool {
+	for k := range selectors {
+		for _, c := range compounds(&selectors[k]) {
+			if c.Combinator == Column || !isPlainSimple(&c.Simple, false) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isPlainSimple reports whether the compound uses only features of CSS 2
+// and Selectors Level 3, negated is set in :not() taking one simple
+// selector there
+func isPlainSimple(v *Simple, negated bool) bool {
+	if v.Nesting || negated && len(v.IDs)+len(v.Classes)+len(v.Attributes)+len(v.PseudoClasses)+len(v.PseudoElements) > 1 {
+		return false
+	}
+
+	for _, a := range v.Attributes {
+		if len(a.Modifier) > 0 {
+			return false
+		}
+	}
+
+	for _, p := range v.PseudoElements {
+		if !legacyPseudoElements[strings.ToLower(string(p.Ident))] || negated {
+			return false
+		}
+	}
+
+	for _, p := range v.PseudoClasses {
+		ident := strings.ToLower(string(p.Ident))
+		switch {
+		case ident == "not" && !negated:
+			if len(p.Selectors) != 1 || len(p.Selectors[0].Combinates) > 0 || !isPlainSimple(&p.Selectors[0].Simple, true) {
+				return false
+			}
+		case !plainPseudoClasses[ident] || len(p.Selectors) > 0:
+			return false
+		}
+	}
+
+	for k := range v.Negations {
+		if !isPlainSimple(&v.Negations[k], true) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// plainPseudoClasses are of CSS 2 and Selectors Level 3
+var plainPseudoClasses = keywords(
+	"link", "visited", "hover", "active", "focus", "target", "lang", "enabled", "disabled", "checked",
+	"root", "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type", "first-child", "last-child",
+	"first-of-type", "last-of-type", "only-child", "only-of-type", "empty", "before", "after",
+	"first-line", "first-letter",
+)
+
+func selectorsBytes(selectors []Selector) []byte {
+	buf := &bytes.Buffer{}
+	for k := range selectors {
+		selectors[k].encode(buf)
+		buf.WriteByte(comma)
+	}
+	return buf.Bytes()
+}
+
+func declarationsBytes(decls []Declaration) []byte {
+	buf := &bytes.Buffer{}
+	encodeDeclarations(buf, decls)
+	return buf.Bytes()
+}
+
+// removeOverridden removes declarations overridden by a later one of the
+// same property, an !important declaration overrides later ones
+func removeOverridden(decls []Declaration) []Declaration {
+	var (
+		removed = make([]bool, len(decls))
+		last    = map[string]int{}
+	)
+
+	for k, d := range decls {
+		name := string(d.Property)
+		if !isCustomProperty(d.Property) {
+			name = strings.ToLower(name)
+		}
+
+		prev, ok := last[name]
+		if !ok {
+			last[name] = k
+			continue
+		}
+
+		prevValues, prevImportant := splitImportant(decls[prev].Values)
+		values, important := splitImportant(d.Values)
+		switch {
+		case prevImportant && !important:
+			removed[k] = true
+			continue
+		case bytes.Equal(valuesBytes(prevValues), valuesBytes(values)) || prevImportant == important &&
+			isPlainValue(prevValues) && isPlainValue(values):
+			removed[prev] = true
+		}
+		last[name] = k
+	}
+
+	var ret []Declaration
+	for k, d := range decls {
+		if !removed[k] {
+			ret = append(ret, d)
+		}
+	}
+	return ret
+}
+
+// isPlainValue reports whether values have only numbers, strings, colors
+// and keywords and units of CSS 2, which every browser supports, so an
+// earlier declaration is no fallback for it
+func isPlainValue(values []Value) bool {
+	for _, v := range values {
+		if len(v.Tokens) > 0 {
+			return false
+		}
+		for _, i := range v.ValueSpace {
+			if !isPlainToken(string(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isPlainToken(token string) bool {
+	switch {
+	case token == "," || token == "/":
+		return true
+	case len(token) > 1 && (token[0] == '"' || token[0] == '\''):
+		return strings.IndexByte(token, '\\') < 0
+	case len(token) > 0 && token[0] == numberSign:
+		_, err := ParseColor(TextBytes(token))
+		return err == nil && colorLevel([]byte(token)) == 1
+	}
+
+	if _, unit, ok := splitDimension(token); ok {
+		return plainUnits[unit]
+	}
+
+	token = strings.ToLower(token)
+	_, color := namedColors[token]
+	return color || plainKeywords[token]
+}
+
+// plainUnits and plainKeywords are of CSS 2
+var (
+	plainUnits = keywords("", "%", "px", "em", "ex", "in", "cm", "mm", "pt", "pc", "deg", "rad", "grad", "s", "ms")
+
+	plainKeywords = keywords(
+		"inherit", "auto", "none", "normal", "transparent", "block", "inline", "inline-block", "list-item",
+		"table", "table-row", "table-cell", "static", "relative", "absolute", "fixed", "left", "right",
+		"center", "top", "bottom", "middle", "justify", "both", "hidden", "visible", "scroll", "solid",
+		"dashed", "dotted", "double", "groove", "ridge", "inset", "outset", "thin", "medium", "thick",
+		"bold", "bolder", "lighter", "italic", "oblique", "small-caps", "underline", "overline",
+		"line-through", "uppercase", "lowercase", "capitalize", "nowrap", "pre", "baseline", "sub",
+		"super", "text-top", "text-bottom", "repeat", "repeat-x", "repeat-y", "no-repeat", "collapse",
+		"separate", "pointer", "default", "move", "text", "wait", "help", "crosshair", "disc", "circle",
+		"square", "decimal", "xx-small", "x-small", "small", "large", "x-large", "xx-large", "smaller",
+		"larger", "serif", "sans-serif", "monospace", "cursive", "fantasy", "ltr", "rtl",
+	)
+)
+
+// optimizeDeclarations returns copies of declarations with shortened
+// colors and zero lengths
+func optimizeDeclarations(decls []Declaration) []Declaration {
+	ret := make([]Declaration, len(decls))
+
+	for k, d := range decls {
+		ret[k] = d
+		if isCustomProperty(d.Property) {
+			continue
+		}
+
+		property := strings.ToLower(string(d.Property))
+		names := strings.HasSuffix(property, "color")
+		zeros := !numberProperties[strings.TrimPrefix(property, vendorPrefix(property))]
+		ret[k].Values = make([]Value, len(d.Values))
+		for j, v := range d.Values {
+			ret[k].Values[j] = v
+			ret[k].Values[j].ValueSpace = make([]TextBytes, len(v.ValueSpace))
+			for i, token := range v.ValueSpace {
+				ret[k].Values[j].ValueSpace[i] = optimizeToken(token, names, zeros)
+			}
+		}
+	}
+
+	return ret
+}
+
+// numberProperties accept a number where a length is, "0" and "0px" differ
+// there or a number may be read as another component
+var numberProperties = keywords(
+	"flex", "box-flex", "line-height", "font", "tab-size", "border-image", "border-image-width",
+	"border-image-outset", "stroke-width", "stroke-dasharray", "stroke-dashoffset",
+)
+
+// optimizeToken writes a hex or functional color, or a named one when
+// names is set, in the shortest form and drops the unit of a zero length
+// when zeros is set
+func optimizeToken(token TextBytes, names, zeros bool) TextBytes {
+	if n, unit, ok := splitDimension(string(token)); ok && n == 0 && lengthUnits[unit] && zeros {
+		return TextBytes("0")
+	}
+
+	if len(token) == 0 || !names && token[0] != numberSign && !isColorFunction(token) {
+		return token
+	}
+
+	c, err := ParseColor(token)
+	if err != nil {
+		return token
+	}
+	if short := c.String(); len(short) < len(token) && colorLevel([]byte(short)) <= colorLevel(token) {
+		return TextBytes(short)
+	}
+
+	return token
+}
+
+// colorLevel returns the level of CSS Color introducing the syntax of
+// color: 1 for names, #rgb and rgb() with commas, 3 for rgba() and hsl()
+// with commas, 4 for #rgba, space separated arguments and other functions
+func colorLevel(token []byte) int {
+	if token[0] == numberSign {
+		if len(token) == 5 || len(token) == 9 {
+			return 4
+		}
+		return 1
+	}
+
+	idx := bytes.IndexByte(token, leftParenthesis)
+	if idx < 0 {
+		return 1
+	}
+
+	switch strings.ToLower(string(token[:idx])) {
+	case "rgb":
+		if bytes.IndexByte(token, comma) > 0 {
+			return 1
+		}
+	case "rgba", "hsl", "hsla":
+		if bytes.IndexByte(token, comma) > 0 {
+			return 3
+		}
+	}
+	return 4
+}
+
+var colorFunctions = keywords("rgb", "rgba", "hsl", "hsla", "hwb", "lab", "lch", "oklab", "oklch", "color")
+
+func isColorFunction(token []byte) bool {
+	idx := bytes.IndexByte(token, leftParenthesis)
+	return idx > 0 && colorFunctions[strings.ToLower(string(token[:idx]))]
+}
@@ -0,0 +1,150 @@
+package css2json
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Statements
+		want string
+	}{
+		{
+			name: "values",
+			s: Statements{
+				testRule([]string{"a"},
+					"color:#FF0000", "margin:0px 0.0em 10px -0px", "background:url(#aabbcc) rgb(255, 255, 255)",
+					"border-color:white", "font-family:white", "transition:all 0s", "--gap:0px", "flex-basis:0%",
+				),
+			},
+			want: `a{color:red;margin:0 0 10px 0;background:url(#aabbcc) #fff;border-color:#fff;font-family:white;transition:all 0s;--gap:0px;flex-basis:0%}`,
+		},
+		{
+			name: "numbers and newer color syntax",
+			s: Statements{
+				testRule([]string{"a"},
+					"flex:1 0px", "-webkit-flex:1 1 0px", "line-height:0px", "flex-basis:0px",
+					"color:rgba(0, 0, 0, .5)", "background-color:transparent", "border-color:rgba(0, 0, 0, 1)",
+					"outline-color:hsl(0 100% 50% / 50%)", "caret-color:rgb(0 0 255 / 50%)",
+				),
+			},
+			want: `a{flex:1 0px;-webkit-flex:1 1 0px;line-height:0px;flex-basis:0;` +
+				`color:rgba(0, 0, 0, .5);background-color:transparent;border-color:#000;` +
+				`outline-color:rgb(255 0 0/.5);caret-color:rgb(0 0 255/.5)}`,
+		},
+		{
+			name: "overridden declarations",
+			s: Statements{
+				testRule([]string{"a"},
+					"margin:0", "color:red !important", "margin:1px", "color:blue",
+					"display:-webkit-box", "display:flex", "width:10px", "width:calc(100% - 1px)",
+					"top:1px", "top:1px", "--x:1", "--X:2", "--x:3",
+				),
+			},
+			want: `a{color:red !important;margin:1px;display:-webkit-box;display:flex;width:10px;width:calc(100% - 1px);top:1px;--X:2;--x:3}`,
+		},
+		{
+			name: "fallbacks",
+			s: Statements{
+				testRule([]string{"a"},
+					"height:100vh", "height:100dvh", "display:block", "display:grid",
+					"position:relative", "position:sticky", "width:1rem", "width:2px", "color:#000", "color:#0008",
+				),
+			},
+			want: `a{height:100vh;height:100dvh;display:block;display:grid;position:relative;position:sticky;` +
+				`width:1rem;width:2px;color:#000;color:#0008}`,
+		},
+		{
+			name: "merge adjacent rules",
+			s: Statements{
+				testRule([]string{"a", "b"}, "color:red"),
+				testRule([]string{"a", "b"}, "margin:0", "color:blue"),
+				testRule([]string{"i"}, "margin:0", "color:blue"),
+				testRule([]string{"p"}, "padding:0"),
+				testRule([]string{"q", "p"}, "padding:0"),
+				testRule([]string{"input::-moz-placeholder"}, "padding:0"),
+				testRule([]string{"a"}, "color:red"),
+			},
+			want: `a,b,i{margin:0;color:blue}p,q{padding:0}input::-moz-placeholder{padding:0}a{color:red}`,
+		},
+		{
+			name: "merge only selectors of CSS 2 and 3",
+			s: Statements{
+				testRule([]string{"a"}, "color:red"),
+				testRule([]string{"b:has(c)"}, "color:red"),
+				testRule([]string{"p:is(.x)"}, "color:red"),
+				testRule([]string{"input:focus-visible"}, "color:red"),
+				testRule([]string{"td||col"}, "color:red"),
+				testRule([]string{"a:not(.x)"}, "margin:0"),
+				testRule([]string{"li:nth-child(2n+1)>i:after"}, "margin:0"),
+				testRule([]string{"a:not(.x.y)"}, "margin:0"),
+			},
+			want: `a{color:red}b:has(c){color:red}p:is(.x){color:red}input:focus-visible{color:red}td||col{color:red}` +
+				`a:not(.x),li:nth-child(odd)>i:after{margin:0}a:not(.x.y){margin:0}`,
+		},
+		{
+			name: "empty rules and media",
+			s: Statements{
+				testRule([]string{"a"}),
+				{Ruleset: &Ruleset{Declarations: testDeclarations("color:red")}},
+				testMedia("min-width", "800px", testRule([]string{"b"})),
+				testMedia("min-width", "600px",
+					testRule([]string{"b"}, "color:#000000"),
+					testRule([]string{"i"}, "color:black"),
+				),
+				testLayer([]string{"base"}, testRule([]string{"b"})),
+			},
+			want: `@media (min-width:600px){b,i{color:#000}}@layer base{}`,
+		},
+		{
+			name: "nested rules",
+			s: Statements{
+				testNested(testRule([]string{"a"}, "color:#ff0000"),
+					testRule([]string{"&:hover"}, "margin:0px"),
+					testRule([]string{"&:focus"}),
+				),
+				testRule([]string{"a"}, "color:red"),
+			},
+			want: `a{color:red;&:hover{margin:0}}a{color:red}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := Encode(tt.s)
+
+			got, err := Encode(Optimize(tt.s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Optimize() = %s, want %s", got, tt.want)
+			}
+
+			if after, _ := Encode(tt.s); string(after) != string(before) {
+				t.Errorf("Optimize() changed statements %s", after)
+			}
+		})
+	}
+}
+
+func TestOptimize_concurrent(t *testing.T) {
+	s := Statements{
+		testRule([]string{"a::-moz-selection", "b:not(:-webkit-autofill)"}, "color:red"),
+		testRule([]string{"i"}, "color:red"),
+	}
+	want := `a::-moz-selection,b:not(:-webkit-autofill){color:red}i{color:red}`
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, _ := Encode(Optimize(s)); string(got) != want {
+				t.Errorf("Optimize() = %s, want %s", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
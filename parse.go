@@ -0,0 +1,230 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDeclaration
+var ErrInvalidDeclaration = errors.New("invalid declaration")
+
+// ParseDeclarations parses a list of declarations without selectors and
+// braces like the value of a style attribute. Comments are removed, a
+// malformed declaration is skipped and the first one is reported by an
+// error wrapping ErrInvalidDeclaration along with the rest of declarations.
+func ParseDeclarations(s string) ([]Declaration, error) {
+	var (
+		ret []Declaration
+		err error
+	)
+
+	for _, part := range splitTopLevel(stripComments([]byte(s)), semicolon) {
+		part = bytes.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		d, ok := parseDeclaration(part)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("%w %q", ErrInvalidDeclaration, part)
+			}
+			continue
+		}
+		ret = append(ret, d)
+	}
+
+	return ret, err
+}
+
+// parseDeclaration parses "property:value", the value may be empty for a
+// custom property only
+func parseDeclaration(b []byte) (Declaration, bool) {
+	idx := bytes.IndexByte(b, colon)
+	if idx < 0 {
+		return Declaration{}, false
+	}
+
+	property := bytes.TrimSpace(b[:idx])
+	value := bytes.TrimSpace(b[idx+1:])
+	if len(property) == 0 || skipIdent(property, 0) != len(property) {
+		return Declaration{}, false
+	}
+	if len(value) == 0 && !isCustomProperty(property) || !isBalanced(value) {
+		return Declaration{}, false
+	}
+
+	return Declaration{Property: TextBytes(property), Values: parseValues(value)}, true
+}
+
+// isBalanced reports whether quotes are closed and brackets are paired
+func isBalanced(b []byte) bool {
+	var stack []byte
+	for k := 0; k < len(b); k++ {
+		switch c := b[k]; c {
+		case '\\':
+			k++
+		case doubleQuote, '\'':
+			if k = closingQuote(b, k); k == len(b) {
+				return false
+			}
+		case leftParenthesis:
+			stack = append(stack, rightParenthesis)
+		case leftSquareBracket:
+			stack = append(stack, rightSquareBracket)
+		case leftCurlyBracket:
+			stack = append(stack, rightCurlyBracket)
+		case rightParenthesis, rightSquareBracket, rightCurlyBracket:
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return len(stack) == 0
+}
+
+// stripComments replaces comments outside of quoted strings by a space, an
+// unclosed comment ends at the end of b
+func stripComments(b []byte) []byte {
+	if !bytes.Contains(b, []byte("/*")) {
+		return b
+	}
+
+	ret := make([]byte, 0, len(b))
+	for k := 0; k < len(b); k++ {
+		switch {
+		case b[k] == doubleQuote || b[k] == '\'':
+			end := closingQuote(b, k)
+			if end < len(b) {
+				end++
+			}
+			ret = append(ret, b[k:end]...)
+			k = end - 1
+		case b[k] == '/' && k+1 < len(b) && b[k+1] == asterisk:
+			end := bytes.Index(b[k+2:], []byte("*/"))
+			if end < 0 {
+				return ret
+			}
+			ret = append(ret, space)
+			k += end + 3
+		default:
+			ret = append(ret, b[k])
+		}
+	}
+	return ret
+}
+
+// parseValues splits a raw property value into comma separated Values
+// and space separated tokens. Parentheses and quoted strings are kept
+// as a single token.
+func parseValues(b []byte) []Value {
+	var ret []Value
+
+	for _, part := range splitTopLevel(b, comma) {
+		tokens := splitSpaces(part)
+		if len(tokens) == 0 {
+			continue
+		}
+		ret = append(ret, Value{ValueSpace: tokens})
+	}
+
+	return ret
+}
+
+// splitTopLevel splits b by sep outside of parentheses and quotes.
+func splitTopLevel(b []byte, sep byte) [][]byte {
+	var (
+		ret   [][]byte
+		depth int
+		quote byte
+		start int
+	)
+
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == doubleQuote || c == '\'':
+			quote = c
+		case c == leftParenthesis || c == leftSquareBracket:
+			depth++
+		case c == rightParenthesis || c == rightSquareBracket:
+			if depth > 0 {
+				depth--
+			}
+		case c == sep && depth == 0:
+			ret = append(ret, b[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(ret, b[start:])
+}
+
+// splitSpaces splits b by whitespace outside of parentheses and quotes.
+func splitSpaces(b []byte) []TextBytes {
+	var (
+		ret   []TextBytes
+		depth int
+		quote byte
+		start = -1
+	)
+
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if quote == 0 && depth == 0 && isSpace(c) {
+			if start >= 0 {
+				ret = append(ret, TextBytes(b[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == doubleQuote || c == '\'':
+			quote = c
+		case c == leftParenthesis || c == leftSquareBracket:
+			depth++
+		case c == rightParenthesis || c == rightSquareBracket:
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+
+	if start >= 0 {
+		ret = append(ret, TextBytes(b[start:]))
+	}
+
+	return ret
+}
+
+func isSpace(c byte) bool {
+	return c == space || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// valuesBytes returns Values as they are written by Declaration.encode
+func valuesBytes(values []Value) []byte {
+	buf := &bytes.Buffer{}
+	for idx, i := range values {
+		i.encode(buf)
+		if len(values)-1 > idx {
+			buf.WriteByte(comma)
+		}
+	}
+	return buf.Bytes()
+}
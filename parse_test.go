@@ -0,0 +1,145 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testDeclarations builds declarations from "property:value" strings
+func testDeclarations(decls ...string) []Declaration {
+	var ret []Declaration
+	for _, d := range decls {
+		idx := bytes.IndexByte([]byte(d), colon)
+		ret = append(ret, Declaration{
+			Property: TextBytes(d[:idx]),
+			Values:   parseValues([]byte(d[idx+1:])),
+		})
+	}
+	return ret
+}
+
+// testRule builds a ruleset of parsed selectors
+func testRule(selectors []string, decls ...string) Statement {
+	rs := &Ruleset{Declarations: testDeclarations(decls...)}
+	for _, s := range selectors {
+		parsed, err := parseSelectors([]byte(s))
+		if err != nil {
+			panic(s + ": " + err.Error())
+		}
+		rs.Selectors = append(rs.Selectors, parsed...)
+	}
+	return Statement{Ruleset: rs}
+}
+
+// testMedia builds a media at-rule with a raw condition
+func testMedia(feature, value string, nested ...Statement) Statement {
+	at := &AtRule{
+		Identifier: Identifier{
+			Type: TextBytes("media"),
+			Information: &MediaInformation{
+				Queries: []Query{
+					{
+						Conditions: []Condition{
+							{
+								Feature: TextBytes(feature),
+								Value:   TextBytes(value),
+							},
+						},
+					},
+				},
+			},
+		},
+		Nested: []*Statement{},
+	}
+	for k := range nested {
+		at.Nested = append(at.Nested, &nested[k])
+	}
+	return Statement{AtRule: at}
+}
+
+func Test_parseValues(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Value
+	}{
+		{
+			in: "1px solid red",
+			want: []Value{
+				{ValueSpace: []TextBytes{TextBytes("1px"), TextBytes("solid"), TextBytes("red")}},
+			},
+		},
+		{
+			in: " 0px 10px,right 3em  bottom 2em ",
+			want: []Value{
+				{ValueSpace: []TextBytes{TextBytes("0px"), TextBytes("10px")}},
+				{ValueSpace: []TextBytes{TextBytes("right"), TextBytes("3em"), TextBytes("bottom"), TextBytes("2em")}},
+			},
+		},
+		{
+			in: `calc(10px + 2px) rgb(0, 0, 0) "a, b"`,
+			want: []Value{
+				{ValueSpace: []TextBytes{TextBytes("calc(10px + 2px)"), TextBytes("rgb(0, 0, 0)"), TextBytes(`"a, b"`)}},
+			},
+		},
+		{
+			in: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseValues([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDeclarations(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Declaration
+		wantErr bool
+	}{
+		{
+			in:   "color: red; margin:0 auto !important;",
+			want: testDeclarations("color:red", "margin:0 auto !important"),
+		},
+		{
+			name: "quotes, brackets and comments",
+			in:   `/* note */ content: "a;b" ; background:url(a;b.png) /* x */ no-repeat;font-family:"A, B", serif`,
+			want: testDeclarations(`content:"a;b"`, "background:url(a;b.png) no-repeat", `font-family:"A, B", serif`),
+		},
+		{
+			name: "custom properties",
+			in:   "--empty:;--gap: 4px",
+			want: []Declaration{
+				{Property: TextBytes("--empty")},
+				{Property: TextBytes("--gap"), Values: parseValues([]byte("4px"))},
+			},
+		},
+		{
+			name:    "malformed declarations are skipped",
+			in:      "color;width:1px);1px solid:red;top:;height:1px;content:\"a",
+			want:    testDeclarations("height:1px"),
+			wantErr: true,
+		},
+		{
+			in: " ; ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDeclarations(tt.in)
+			if (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrInvalidDeclaration) {
+				t.Errorf("ParseDeclarations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDeclarations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
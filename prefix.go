@@ -0,0 +1,530 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTarget
+var ErrInvalidTarget = errors.New("invalid browser target")
+
+// Targets are the oldest versions of browsers to support by name: chrome,
+// edge, firefox, safari, ios_saf and ie
+type Targets map[string]float64
+
+var browserNames = map[string]string{
+	"chrome": "chrome", "and_chr": "chrome", "chromeandroid": "chrome",
+	"edge":    "edge",
+	"firefox": "firefox", "ff": "firefox", "and_ff": "firefox", "firefoxandroid": "firefox",
+	"safari":  "safari",
+	"ios_saf": "ios_saf", "ios": "ios_saf",
+	"ie": "ie", "explorer": "ie",
+}
+
+// ParseTargets parses a browserslist-style query of browser versions like
+// "chrome >= 80, safari 12-13, ie 11 or ff > 100". A version, a range or
+// ">=" targets the version and newer ones, "<" and "<=" target all versions
+// up to it.
+func ParseTargets(query string) (Targets, error) {
+	ret := Targets{}
+
+	var queries []string
+	for _, part := range strings.Split(query, ",") {
+		var fields []string
+		for _, i := range append(strings.Fields(part), "or") {
+			if !strings.EqualFold(i, "or") {
+				fields = append(fields, i)
+				continue
+			}
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("%w %q", ErrInvalidTarget, part)
+			}
+			queries = append(queries, strings.Join(fields, ""))
+			fields = nil
+		}
+	}
+
+	for _, q := range queries {
+		idx := strings.IndexFunc(q, func(r rune) bool { return r != '_' && !isLetter(byte(r)) })
+		if idx <= 0 {
+			return nil, fmt.Errorf("%w %q", ErrInvalidTarget, q)
+		}
+		browser, ok := browserNames[strings.ToLower(q[:idx])]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrInvalidTarget, q)
+		}
+
+		version := q[idx:]
+		idx = strings.IndexFunc(version, func(r rune) bool { return r != '<' && r != '>' && r != '=' })
+		if idx < 0 {
+			return nil, fmt.Errorf("%w %q", ErrInvalidTarget, q)
+		}
+		op := version[:idx]
+		version = version[idx:]
+		if idx := strings.IndexByte(version, '-'); idx > 0 && op == "" {
+			version = version[:idx]
+		}
+
+		v, err := strconv.ParseFloat(version, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%w %q", ErrInvalidTarget, q)
+		}
+		switch op {
+		case "", "=", ">=":
+		case ">":
+			v = math.Nextafter(v, math.Inf(1))
+		case "<", "<=":
+			v = 0
+		default:
+			return nil, fmt.Errorf("%w %q", ErrInvalidTarget, q)
+		}
+
+		if old, ok := ret[browser]; !ok || v < old {
+			ret[browser] = v
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("%w %q", ErrInvalidTarget, query)
+	}
+
+	return ret, nil
+}
+
+// needs reports whether any target is older than the first version of its
+// browser supporting v unprefixed
+func (t Targets) needs(v *prefixed) bool {
+	for browser, min := range t {
+		if until, ok := v.until[browser]; ok && min < until {
+			return true
+		}
+	}
+	return false
+}
+
+// Prefix returns statements with vendor prefixed declarations, keywords,
+// rules for prefixed pseudo-classes and pseudo-elements and @keyframes
+// needed by targets, like autoprefixer does. A prefixed copy is put before
+// the original unless it is there already.
+//
+// Prefixes not needed by targets are removed: such a declaration is dropped
+// when an unprefixed one follows in the rule, such a statement is dropped
+// when the same unprefixed one is in the list, otherwise it is unprefixed.
+// Statements are not changed.
+func Prefix(s Statements, targets Targets) Statements {
+	return (&prefixer{targets: targets}).statements(s)
+}
+
+type prefixer struct {
+	targets Targets
+}
+
+func (p *prefixer) statements(s Statements) Statements {
+	var (
+		kept    Statements
+		keys    = make([]string, len(s))
+		renamed = make([]bool, len(s))
+		norm    = make(Statements, len(s))
+		encoded = make([][]byte, len(s))
+	)
+
+	for k := range s {
+		norm[k], renamed[k] = p.unprefix(&s[k])
+		keys[k] = prefixKey(&norm[k])
+		encoded[k] = encodeBytes(&norm[k])
+	}
+
+	// an unprefixed statement is dropped for the same original one or a
+	// later unprefixed one, otherwise it keeps its declarations
+	existing := map[string]bool{}
+	for k := range norm {
+		drop := false
+		for j := range norm {
+			if renamed[k] && j != k && keys[j] != "" && keys[j] == keys[k] && (!renamed[j] || j > k) &&
+				bytes.Equal(encoded[j], encoded[k]) {
+				drop = true
+			}
+		}
+		if !drop {
+			kept = append(kept, norm[k])
+			existing[keys[k]] = true
+		}
+	}
+
+	var ret Statements
+	for k := range kept {
+		for _, v := range p.copies(&kept[k]) {
+			if key := prefixKey(&v); !existing[key] {
+				existing[key] = true
+				ret = append(ret, v)
+			}
+		}
+		ret = append(ret, kept[k])
+	}
+
+	return ret
+}
+
+// prefixKey identifies a ruleset by selectors and @keyframes by the name
+func prefixKey(v *Statement) string {
+	switch {
+	case v.Ruleset != nil:
+		return string(selectorsBytes(v.Ruleset.Selectors))
+	case v.AtRule != nil:
+		if info, ok := v.AtRule.Identifier.Information.(*KeyframesInformation); ok {
+			return "@" + strings.ToLower(string(v.AtRule.Identifier.Type)) + " " + string(info.Value)
+		}
+	}
+	return ""
+}
+
+// unprefix returns a copy of the statement with prefixes of declarations
+// added or removed and prefixes of its selectors or at-rule removed, it
+// reports whether the statement itself was unprefixed
+func (p *prefixer) unprefix(v *Statement) (Statement, bool) {
+	var (
+		ret     Statement
+		renamed bool
+	)
+
+	if v.AtRule != nil {
+		ret.AtRule = &AtRule{Identifier: v.AtRule.Identifier}
+		if base, variant, ok := unprefixedName(prefixedAtRules, strings.ToLower(string(v.AtRule.Identifier.Type))); ok &&
+			!p.targets.needs(variant) {
+			ret.AtRule.Identifier.Type = TextBytes(base)
+			renamed = true
+		}
+
+		if v.AtRule.Nested != nil {
+			ret.AtRule.Nested = p.nested(v.AtRule.Nested)
+		}
+	}
+
+	if v.Ruleset != nil {
+		ret.Ruleset = &Ruleset{Declarations: p.declarations(v.Ruleset.Declarations)}
+		if v.Ruleset.Nested != nil {
+			ret.Ruleset.Nested = p.nested(v.Ruleset.Nested)
+		}
+		for _, sel := range v.Ruleset.Selectors {
+			sel, ok := replacePseudos(sel, func(name string) (string, bool) {
+				base, variant, ok := unprefixedName(prefixedPseudos, name)
+				return base, ok && !p.targets.needs(variant)
+			})
+			ret.Ruleset.Selectors = append(ret.Ruleset.Selectors, sel)
+			renamed = renamed || ok
+		}
+	}
+
+	return ret, renamed
+}
+
+func (p *prefixer) nested(s []*Statement) []*Statement {
+	nested := make(Statements, len(s))
+	for k, i := range s {
+		nested[k] = *i
+	}
+
+	ret := []*Statement{}
+	for _, i := range p.statements(nested) {
+		n := i
+		ret = append(ret, &n)
+	}
+	return ret
+}
+
+// copies returns prefixed copies of @keyframes or of a ruleset with
+// selectors having pseudo-classes or pseudo-elements needing prefixes
+func (p *prefixer) copies(v *Statement) Statements {
+	var ret Statements
+
+	if v.AtRule != nil && v.AtRule.Nested != nil {
+		for _, variant := range prefixedAtRules[strings.ToLower(string(v.AtRule.Identifier.Type))] {
+			if !p.targets.needs(&variant) {
+				continue
+			}
+			at := &AtRule{Identifier: v.AtRule.Identifier, Nested: []*Statement{}}
+			at.Identifier.Type = TextBytes(variant.name)
+			for _, i := range v.AtRule.Nested {
+				n := *i
+				at.Nested = append(at.Nested, &n)
+			}
+			ret = append(ret, Statement{AtRule: at})
+		}
+	}
+
+	if v.Ruleset == nil {
+		return ret
+	}
+
+	// pseudos in order of appearance
+	var bases []string
+	for _, sel := range v.Ruleset.Selectors {
+		replacePseudos(sel, func(name string) (string, bool) {
+			if _, ok := prefixedPseudos[name]; ok {
+				bases = append(bases, name)
+			}
+			return "", false
+		})
+	}
+
+	seen := map[string]bool{}
+	for _, base := range bases {
+		if seen[base] {
+			continue
+		}
+		seen[base] = true
+
+		for _, variant := range prefixedPseudos[base] {
+			if !p.targets.needs(&variant) {
+				continue
+			}
+			rs := &Ruleset{Declarations: v.Ruleset.Declarations, Nested: v.Ruleset.Nested}
+			for _, sel := range v.Ruleset.Selectors {
+				if sel, ok := replacePseudos(sel, func(name string) (string, bool) {
+					return variant.name, name == base
+				}); ok {
+					rs.Selectors = append(rs.Selectors, sel)
+				}
+			}
+			ret = append(ret, Statement{Ruleset: rs})
+		}
+	}
+
+	return ret
+}
+
+// declarations returns declarations with prefixes not needed removed and
+// prefixed declarations and keywords needed added before unprefixed ones
+func (p *prefixer) declarations(decls []Declaration) []Declaration {
+	var (
+		norm    = make([]Declaration, len(decls))
+		renamed = make([]bool, len(decls))
+	)
+
+	for k, d := range decls {
+		norm[k] = d
+		if isCustomProperty(d.Property) {
+			continue
+		}
+		name := strings.ToLower(string(d.Property))
+
+		if base, variant, ok := unprefixedName(prefixedProperties, name); ok && !p.targets.needs(variant) {
+			norm[k] = Declaration{Property: TextBytes(base), Values: p.renameTokens(base, d.Values, func(token string) (string, bool) {
+				base, variant, ok := unprefixedName(prefixedProperties, token)
+				return base, ok && variant.name == vendorPrefix(name)
+			})}
+			renamed[k] = true
+			continue
+		}
+
+		if keyword, imp, ok := singleKeyword(d.Values); ok {
+			base, variant, ok := unprefixedKeyword(keyword)
+			if ok && prefixedKeywords[base].properties[name] && !p.targets.needs(variant) {
+				norm[k].Values = withImportant([]Value{{ValueSpace: []TextBytes{TextBytes(base)}}}, imp)
+				renamed[k] = true
+			}
+		}
+	}
+
+	var (
+		kept    []Declaration
+		present = map[string]bool{}
+	)
+	for k, d := range norm {
+		name := strings.ToLower(string(d.Property))
+		if renamed[k] && hasProperty(norm[k+1:], name) {
+			continue
+		}
+		kept = append(kept, d)
+		present[name] = true
+		if keyword, _, ok := singleKeyword(d.Values); ok {
+			present[name+":"+keyword] = true
+		}
+	}
+
+	var ret []Declaration
+	for _, d := range kept {
+		if isCustomProperty(d.Property) {
+			ret = append(ret, d)
+			continue
+		}
+		name := strings.ToLower(string(d.Property))
+
+		for _, variant := range prefixedProperties[name] {
+			if !p.targets.needs(&variant) || present[variant.name+name] {
+				continue
+			}
+			present[variant.name+name] = true
+			ret = append(ret, Declaration{
+				Property: TextBytes(variant.name + name),
+				Values: p.renameTokens(name, d.Values, func(token string) (string, bool) {
+					for _, i := range prefixedProperties[token] {
+						if i.name == variant.name && p.targets.needs(&i) {
+							return i.name + token, true
+						}
+					}
+					return "", false
+				}),
+			})
+		}
+
+		if keyword, imp, ok := singleKeyword(d.Values); ok && prefixedKeywords[keyword].properties[name] {
+			for _, variant := range prefixedKeywords[keyword].variants {
+				if !p.targets.needs(&variant) || present[name+":"+variant.name] {
+					continue
+				}
+				present[name+":"+variant.name] = true
+				ret = append(ret, Declaration{
+					Property: d.Property,
+					Values:   withImportant([]Value{{ValueSpace: []TextBytes{TextBytes(variant.name)}}}, imp),
+				})
+			}
+		}
+
+		ret = append(ret, d)
+	}
+
+	return ret
+}
+
+func hasProperty(decls []Declaration, name string) bool {
+	for _, d := range decls {
+		if strings.EqualFold(string(d.Property), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyValues are properties taking names of other properties
+var propertyValues = keywords("transition", "transition-property", "will-change")
+
+// renameTokens returns values of property with names of properties renamed
+// by fn when the property takes them
+func (p *prefixer) renameTokens(property string, values []Value, fn func(string) (string, bool)) []Value {
+	if !propertyValues[property] {
+		return values
+	}
+
+	ret := make([]Value, len(values))
+	for k, v := range values {
+		ret[k] = v
+		ret[k].ValueSpace = make([]TextBytes, len(v.ValueSpace))
+		for j, token := range v.ValueSpace {
+			ret[k].ValueSpace[j] = token
+			if to, ok := fn(strings.ToLower(string(token))); ok {
+				ret[k].ValueSpace[j] = TextBytes(to)
+			}
+		}
+	}
+	return ret
+}
+
+// singleKeyword returns the lower-cased keyword of values holding only it
+func singleKeyword(values []Value) (string, bool, bool) {
+	values, imp := splitImportant(values)
+	if len(values) != 1 || len(values[0].ValueSpace) != 1 || values[0].hasTyped() {
+		return "", false, false
+	}
+	return strings.ToLower(string(values[0].ValueSpace[0])), imp, true
+}
+
+// vendorPrefix returns the vendor prefix of name like "-webkit-"
+func vendorPrefix(name string) string {
+	for _, i := range []string{"-webkit-", "-moz-", "-ms-", "-o-"} {
+		if strings.HasPrefix(name, i) {
+			return i
+		}
+	}
+	return ""
+}
+
+// unprefixedName returns the unprefixed name of a prefixed property,
+// pseudo or at-rule in table with its variant
+func unprefixedName(table map[string][]prefixed, name string) (string, *prefixed, bool) {
+	prefix := vendorPrefix(strings.TrimLeft(name, ":"))
+	if prefix == "" {
+		return "", nil, false
+	}
+
+	if base := strings.TrimPrefix(name, prefix); base != name {
+		for k := range table[base] {
+			if table[base][k].name == prefix || table[base][k].name == name {
+				return base, &table[base][k], true
+			}
+		}
+	}
+
+	for base, variants := range table {
+		for k := range variants {
+			if variants[k].name == name {
+				return base, &variants[k], true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+// unprefixedKeyword returns the unprefixed keyword of a prefixed one with
+// its variant
+func unprefixedKeyword(keyword string) (string, *prefixed, bool) {
+	if vendorPrefix(keyword) == "" {
+		return "", nil, false
+	}
+	for base, v := range prefixedKeywords {
+		for k := range v.variants {
+			if v.variants[k].name == keyword {
+				return base, &v.variants[k], true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// replacePseudos returns a copy of the selector with pseudo-classes and
+// pseudo-elements of its compounds without arguments renamed by fn, names
+// are written with colons, so a pseudo-class may become a pseudo-element.
+// It reports whether any pseudo was renamed.
+func replacePseudos(sel Selector, fn func(string) (string, bool)) (Selector, bool) {
+	replaced := false
+
+	rename := func(v Simple) Simple {
+		var elements, classes []Pseudo
+		for k, pseudos := range [][]Pseudo{v.PseudoElements, v.PseudoClasses} {
+			for _, i := range pseudos {
+				name := ":" + strings.ToLower(string(i.Ident))
+				if k == 0 {
+					name = ":" + name
+				}
+				if to, ok := fn(name); ok && i.Func == nil && i.Nth == nil && i.Selectors == nil {
+					replaced = true
+					name = to
+					i = Pseudo{Ident: TextBytes(strings.TrimLeft(to, ":"))}
+				}
+				if strings.HasPrefix(name, "::") {
+					elements = append(elements, i)
+				} else {
+					classes = append(classes, i)
+				}
+			}
+		}
+		v.PseudoElements, v.PseudoClasses = elements, classes
+		return v
+	}
+
+	ret := Selector{Simple: rename(sel.Simple)}
+	if len(sel.Combinates) > 0 {
+		ret.Combinates = make([]Combinate, len(sel.Combinates))
+		for k, c := range sel.Combinates {
+			c.Simple = rename(c.Simple)
+			ret.Combinates[k] = c
+		}
+	}
+
+	return ret, replaced
+}
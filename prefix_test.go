@@ -0,0 +1,174 @@
+package css2json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    Targets
+		wantErr bool
+	}{
+		{
+			query: "chrome >= 80, Safari 12-13, ie 11",
+			want:  Targets{"chrome": 80, "safari": 12, "ie": 11},
+		},
+		{
+			query: "ff>=100 or firefox 90, ios <= 15, and_chr 120",
+			want:  Targets{"firefox": 90, "ios_saf": 0, "chrome": 120},
+		},
+		{
+			query:   "opera 90",
+			wantErr: true,
+		},
+		{
+			query:   "chrome",
+			wantErr: true,
+		},
+		{
+			query:   "chrome => 80",
+			wantErr: true,
+		},
+		{
+			query:   "chrome 80,",
+			wantErr: true,
+		},
+		{
+			query:   "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseTargets(tt.query)
+			if (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrInvalidTarget) {
+				t.Errorf("ParseTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	keyframes := func(kind, name string, nested ...Statement) Statement {
+		at := &AtRule{
+			Identifier: Identifier{Type: TextBytes(kind), Information: &KeyframesInformation{Value: TextBytes(name)}},
+			Nested:     []*Statement{},
+		}
+		for k := range nested {
+			at.Nested = append(at.Nested, &nested[k])
+		}
+		return Statement{AtRule: at}
+	}
+
+	tests := []struct {
+		name    string
+		targets string
+		s       Statements
+		want    string
+	}{
+		{
+			name:    "declarations",
+			targets: "chrome 25, firefox 60, ie 10",
+			s: Statements{
+				testRule([]string{"a"},
+					"transition:transform 1s,color 2s", "user-select:none !important", "display:flex", "color:red",
+				),
+			},
+			want: `a{-webkit-transition:-webkit-transform 1s,color 2s;transition:transform 1s,color 2s;` +
+				`-webkit-user-select:none !important;-moz-user-select:none !important;-ms-user-select:none !important;` +
+				`user-select:none !important;display:-webkit-flex;display:-ms-flexbox;display:flex;color:red}`,
+		},
+		{
+			name:    "prefixed already",
+			targets: "safari 8",
+			s: Statements{
+				testRule([]string{"a"}, "transform:none", "-webkit-transform:none", "display:-webkit-flex", "display:flex"),
+			},
+			want: `a{transform:none;-webkit-transform:none;display:-webkit-flex;display:flex}`,
+		},
+		{
+			name:    "remove prefixes",
+			targets: "chrome >= 100, firefox >= 100, safari >= 16",
+			s: Statements{
+				testRule([]string{"a"},
+					"-webkit-transition:-webkit-transform 1s", "-moz-transition:transform 1s", "transition:transform 1s",
+					"display:-webkit-flex", "display:flex", "-webkit-box-shadow:none", "-moz-tab-size:4",
+					"position:-webkit-sticky", "-webkit-user-select:none",
+				),
+			},
+			want: `a{transition:transform 1s;display:flex;-webkit-box-shadow:none;tab-size:4;position:sticky;-webkit-user-select:none}`,
+		},
+		{
+			name:    "pseudos",
+			targets: "chrome 50, firefox 100, ie 11",
+			s: Statements{
+				testRule([]string{"input::placeholder", "a"}, "color:gray"),
+				testRule([]string{"input::-moz-placeholder"}, "color:gray"),
+				testRule([]string{"::-moz-selection"}, "color:red"),
+				testRule([]string{":fullscreen"}, "margin:0"),
+			},
+			want: `input::-webkit-input-placeholder{color:gray}input:-ms-input-placeholder{color:gray}input::placeholder,a{color:gray}` +
+				`input::placeholder{color:gray}` +
+				`::selection{color:red}:-webkit-full-screen{margin:0}:-ms-fullscreen{margin:0}:fullscreen{margin:0}`,
+		},
+		{
+			name:    "unprefixed pseudos with other declarations",
+			targets: "chrome 100",
+			s: Statements{
+				testRule([]string{"input::-webkit-input-placeholder"}, "color:red"),
+				testRule([]string{"input::placeholder"}, "font-size:12px"),
+				testRule([]string{"input::-webkit-input-placeholder"}, "margin:0"),
+				testRule([]string{"input::placeholder"}, "margin:0"),
+			},
+			want: `input::placeholder{color:red}input::placeholder{font-size:12px}input::placeholder{margin:0}`,
+		},
+		{
+			name:    "keyframes",
+			targets: "safari 8, firefox 100",
+			s: Statements{
+				keyframes("keyframes", "spin", testRule([]string{"to"}, "transform:rotate(1turn)")),
+				testMedia("min-width", "800px",
+					keyframes("-moz-keyframes", "fade", testRule([]string{"to"}, "opacity:0")),
+					keyframes("-moz-keyframes", "pulse", testRule([]string{"to"}, "opacity:1")),
+					keyframes("keyframes", "pulse", testRule([]string{"to"}, "opacity:1")),
+				),
+			},
+			want: `@-webkit-keyframes spin{to{-webkit-transform:rotate(1turn);transform:rotate(1turn)}}` +
+				`@keyframes spin{to{-webkit-transform:rotate(1turn);transform:rotate(1turn)}}` +
+				`@media (min-width:800px){@-webkit-keyframes fade{to{opacity:0}}@keyframes fade{to{opacity:0}}` +
+				`@-webkit-keyframes pulse{to{opacity:1}}@keyframes pulse{to{opacity:1}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := ParseTargets(tt.targets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, _ := Encode(tt.s)
+
+			got, err := Encode(Prefix(tt.s, targets))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Prefix() = %s, want %s", got, tt.want)
+			}
+
+			if after, _ := Encode(tt.s); string(after) != string(before) {
+				t.Errorf("Prefix() changed statements %s", after)
+			}
+
+			again, _ := Encode(Prefix(Prefix(tt.s, targets), targets))
+			if string(again) != string(got) {
+				t.Errorf("Prefix() twice = %s, want %s", again, got)
+			}
+		})
+	}
+}
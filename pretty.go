@@ -0,0 +1,186 @@
+package css2json
+
+import (
+	"bytes"
+	"strings"
+)
+
+// EncodeIndent encodes statements to CSS like Encode, every rule and
+// declaration starts a new line indented by indent per nesting level.
+func EncodeIndent(s Statements, indent string) ([]byte, error) {
+	e := &indentEncoder{dst: &bytes.Buffer{}, indent: indent}
+
+	for k := range s {
+		if err := e.statement(&s[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.dst.Bytes(), nil
+}
+
+type indentEncoder struct {
+	dst    *bytes.Buffer
+	indent string
+	depth  int
+}
+
+func (e *indentEncoder) newline() {
+	e.dst.WriteByte('\n')
+	e.dst.WriteString(strings.Repeat(e.indent, e.depth))
+}
+
+func (e *indentEncoder) open() {
+	e.dst.WriteString(" {")
+	e.depth++
+}
+
+func (e *indentEncoder) close() {
+	e.depth--
+	e.newline()
+	e.dst.WriteByte(rightCurlyBracket)
+}
+
+func (e *indentEncoder) statement(v *Statement) error {
+	if e.dst.Len() > 0 {
+		e.newline()
+	}
+
+	if v.AtRule != nil {
+		if err := e.atRule(v.AtRule); err != nil {
+			return err
+		}
+	}
+
+	if v.Ruleset != nil {
+		if v.AtRule != nil {
+			e.newline()
+		}
+		if err := e.ruleset(v.Ruleset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *indentEncoder) atRule(v *AtRule) error {
+	if info, ok := v.Identifier.Information.(*FontFaceInformation); ok {
+		if _, ok := identifierTypes[string(v.Identifier.Type)]; !ok {
+			return ErrNotExistsTypeIdentifier
+		}
+		e.dst.WriteByte(atSign)
+		e.dst.Write(v.Identifier.Type)
+		if err := e.declarations(info.Declarations); err != nil {
+			return err
+		}
+	} else if err := v.Identifier.encode(e.dst); err != nil {
+		return err
+	}
+
+	if v.Nested == nil {
+		if _, ok := v.Identifier.Information.(*LayerInformation); ok {
+			e.dst.WriteByte(semicolon)
+		}
+		return nil
+	}
+
+	e.open()
+	for _, i := range v.Nested {
+		if err := e.statement(i); err != nil {
+			return err
+		}
+	}
+	e.close()
+
+	return nil
+}
+
+func (e *indentEncoder) ruleset(v *Ruleset) error {
+	if len(v.Declarations) == 0 && len(v.Nested) == 0 {
+		return ErrNotExistsDeclaration
+	}
+
+	for idx, s := range v.Selectors {
+		if err := s.encodeIndent(e.dst); err != nil {
+			return err
+		}
+		if len(v.Selectors)-1 > idx {
+			e.dst.WriteByte(comma)
+			e.newline()
+		}
+	}
+
+	e.open()
+	if err := e.writeDeclarations(v.Declarations); err != nil {
+		return err
+	}
+	for _, i := range v.Nested {
+		if err := e.statement(i); err != nil {
+			return err
+		}
+	}
+	e.close()
+
+	return nil
+}
+
+func (e *indentEncoder) declarations(decls []Declaration) error {
+	e.open()
+	if err := e.writeDeclarations(decls); err != nil {
+		return err
+	}
+	e.close()
+
+	return nil
+}
+
+func (e *indentEncoder) writeDeclarations(decls []Declaration) error {
+	for _, d := range decls {
+		if err := d.validate(); err != nil {
+			return err
+		}
+		e.newline()
+		e.dst.Write(d.Property)
+		e.dst.WriteString(": ")
+		for idx, i := range d.Values {
+			if err := i.encode(e.dst); err != nil {
+				return err
+			}
+			if len(d.Values)-1 > idx {
+				e.dst.WriteString(", ")
+			}
+		}
+		e.dst.WriteByte(semicolon)
+	}
+
+	return nil
+}
+
+// encodeIndent writes selector with spaces around combinators, a relative
+// selector starts with the combinator
+func (v *Selector) encodeIndent(dst *bytes.Buffer) error {
+	start := dst.Len()
+	if err := v.Simple.encode(dst); err != nil {
+		return err
+	}
+
+	for _, i := range v.Combinates {
+		if err := i.Combinator.Validate(); err != nil {
+			return err
+		}
+		if dst.Len() == start {
+			if i.Combinator != Descendant {
+				dst.WriteString(i.Combinator.Symbol())
+				dst.WriteByte(space)
+			}
+		} else {
+			i.Combinator.encodeIndent(dst)
+		}
+		if err := i.Simple.encode(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
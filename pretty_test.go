@@ -0,0 +1,141 @@
+package css2json
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEncodeIndent(t *testing.T) {
+	s := Statements{
+		{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type:        TextBytes("charset"),
+					Information: &CharsetInformation{Value: TextBytes("utf-8")},
+				},
+			},
+		},
+		testRule([]string{"ul > li + li", ".a ~ .b", "td||col"}, "color:red", "font-family:a,b"),
+		{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type: TextBytes("font-face"),
+					Information: &FontFaceInformation{
+						Declarations: testDeclarations(`font-family:"A"`),
+					},
+				},
+			},
+		},
+		testMedia("max-width", "600px",
+			testRule([]string{"#nav a"}, "display:none"),
+		),
+		testNested(testRule([]string{".card"}, "color:red"),
+			testRule([]string{"&:hover"}, "color:blue"),
+		),
+	}
+
+	want := `@charset "utf-8";
+ul > li + li,
+.a ~ .b,
+td || col {
+  color: red;
+  font-family: a, b;
+}
+@font-face {
+  font-family: "A";
+}
+@media (max-width:600px) {
+  #nav a {
+    display: none;
+  }
+}
+.card {
+  color: red;
+  &:hover {
+    color: blue;
+  }
+}`
+	got, err := EncodeIndent(s, "  ")
+	if err != nil {
+		t.Fatalf("EncodeIndent() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("EncodeIndent() = \n%s\nwant\n%s", got, want)
+	}
+
+	min, err := Encode(s)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	wantMin := `@charset "utf-8";ul>li+li,.a~.b,td||col{color:red;font-family:a,b}@font-face {font-family:"A"}@media (max-width:600px){#nav a{display:none}}.card{color:red;&:hover{color:blue}}`
+	if string(min) != wantMin {
+		t.Errorf("Encode() = %s, want %s", min, wantMin)
+	}
+}
+
+func TestCombinator_JSON(t *testing.T) {
+	tests := []struct {
+		js      string
+		want    Combinator
+		out     string
+		wantErr bool
+	}{
+		{js: `"descendant"`, want: Descendant, out: `"descendant"`},
+		{js: `"child"`, want: Child, out: `"child"`},
+		{js: `"next-sibling"`, want: NextSibling, out: `"next-sibling"`},
+		{js: `"subsequent-sibling"`, want: SubsequentSibling, out: `"subsequent-sibling"`},
+		{js: `"column"`, want: Column, out: `"column"`},
+		{js: `" "`, want: Descendant, out: `"descendant"`},
+		{js: `" > "`, want: Child, out: `"child"`},
+		{js: `"||"`, want: Column, out: `"column"`},
+		{js: `"/deep/"`, wantErr: true},
+		{js: `""`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.js, func(t *testing.T) {
+			var got Combinator
+			err := json.Unmarshal([]byte(tt.js), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Combinator.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Combinator.UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+			if out, _ := json.Marshal(got); string(out) != tt.out {
+				t.Errorf("Combinator.MarshalJSON() = %s, want %s", out, tt.out)
+			}
+		})
+	}
+}
+
+func TestCombinator_invalid(t *testing.T) {
+	sel := Selector{
+		Simple:     Simple{Element: TextBytes("a")},
+		Combinates: []Combinate{{Combinator: Combinator(42), Simple: Simple{Element: TextBytes("b")}}},
+	}
+	if _, err := Encode(Statements{{Ruleset: &Ruleset{Selectors: []Selector{sel}, Declarations: testDeclarations("color:red")}}}); !errors.Is(err, ErrInvalidCombinator) {
+		t.Errorf("Encode() error = %v, want %v", err, ErrInvalidCombinator)
+	}
+	if _, err := EncodeIndent(Statements{{Ruleset: &Ruleset{Selectors: []Selector{sel}, Declarations: testDeclarations("color:red")}}}, "\t"); !errors.Is(err, ErrInvalidCombinator) {
+		t.Errorf("EncodeIndent() error = %v, want %v", err, ErrInvalidCombinator)
+	}
+	if _, err := json.Marshal(sel); err == nil {
+		t.Errorf("json.Marshal() error = nil, want %v", ErrInvalidCombinator)
+	}
+}
+
+func TestEncodeIndent_invalidDeclaration(t *testing.T) {
+	s := Statements{testRule([]string{"a"}, "color:red")}
+	s[0].Ruleset.Declarations[0].Property = nil
+
+	if _, err := Encode(s); !errors.Is(err, ErrInvalidDeclaration) {
+		t.Errorf("Encode() error = %v, want %v", err, ErrInvalidDeclaration)
+	}
+	if _, err := EncodeIndent(s, "  "); !errors.Is(err, ErrInvalidDeclaration) {
+		t.Errorf("EncodeIndent() error = %v, want %v", err, ErrInvalidDeclaration)
+	}
+}
@@ -0,0 +1,447 @@
+package css2json
+
+// typeSyntaxes define named data types in value definition syntax
+var typeSyntaxes = map[string]string{
+	"line-width":            "<length> | thin | medium | thick",
+	"line-style":            "none | hidden | dotted | dashed | solid | double | groove | ridge | inset | outset",
+	"alpha-value":           "<number> | <percentage>",
+	"absolute-size":         "xx-small | x-small | small | medium | large | x-large | xx-large | xxx-large",
+	"relative-size":         "larger | smaller",
+	"font-weight-absolute":  "normal | bold | <number>",
+	"font-stretch-absolute": "normal | ultra-condensed | extra-condensed | condensed | semi-condensed | semi-expanded | expanded | extra-expanded | ultra-expanded | <percentage>",
+	"family-name":           "<string> | <custom-ident>+",
+	"generic-family":        "serif | sans-serif | cursive | fantasy | monospace | system-ui | ui-serif | ui-sans-serif | ui-monospace | ui-rounded | math | emoji | fangsong",
+	"position":              "[ left | center | right | top | bottom | <length-percentage> ]{1,4}",
+	"bg-image":              "<image> | none",
+	"bg-size":               "[ <length-percentage> | auto ]{1,2} | cover | contain",
+	"repeat-style":          "repeat-x | repeat-y | [ repeat | space | round | no-repeat ]{1,2}",
+	"attachment":            "scroll | fixed | local",
+	"box":                   "border-box | padding-box | content-box",
+	"bg-layer":              "<bg-image> || <position> [ / <bg-size> ]? || <repeat-style> || <attachment> || <box> || <box>",
+	"final-bg-layer":        "<color> || <bg-image> || <position> [ / <bg-size> ]? || <repeat-style> || <attachment> || <box> || <box>",
+	"easing-function": "linear | ease | ease-in | ease-out | ease-in-out | step-start | step-end | " +
+		"cubic-bezier( <number> , <number> , <number> , <number> ) | " +
+		"steps( <integer> [ , <step-position> ]? ) | linear( <any-value>+ )",
+	"step-position":              "jump-start | jump-end | jump-none | jump-both | start | end",
+	"single-transition-property": "all | <custom-ident>",
+	"single-transition":          "[ none | <single-transition-property> ] || <time> || <easing-function> || <time>",
+	"keyframes-name":             "<custom-ident> | <string>",
+	"single-animation": "<time> || <easing-function> || <time> || [ infinite | <number> ] || " +
+		"[ normal | reverse | alternate | alternate-reverse ] || [ none | forwards | backwards | both ] || " +
+		"[ running | paused ] || [ none | <keyframes-name> ]",
+	"shadow":      "inset? && <length>{2,4} && <color>?",
+	"text-shadow": "<color>? && <length>{2,3}",
+	"grid-line": "auto | <custom-ident> | [ <integer> && <custom-ident>? ] | " +
+		"[ span && [ <integer> || <custom-ident> ] ]",
+	"track-size": "<length-percentage> | <flex> | auto | min-content | max-content | " +
+		"minmax( <any-value>+ ) | fit-content( <length-percentage> ) | repeat( <any-value>+ )",
+	"transform-function": "matrix( <number>#{6} ) | matrix3d( <number>#{16} ) | " +
+		"translate( <length-percentage> [ , <length-percentage> ]? ) | translatex( <length-percentage> ) | " +
+		"translatey( <length-percentage> ) | translatez( <length> ) | translate3d( <length-percentage> , <length-percentage> , <length> ) | " +
+		"scale( <number> [ , <number> ]? ) | scalex( <number> ) | scaley( <number> ) | scalez( <number> ) | " +
+		"scale3d( <number> , <number> , <number> ) | rotate( <angle> ) | rotatex( <angle> ) | rotatey( <angle> ) | " +
+		"rotatez( <angle> ) | rotate3d( <number> , <number> , <number> , <angle> ) | skew( <angle> [ , <angle> ]? ) | " +
+		"skewx( <angle> ) | skewy( <angle> ) | perspective( <length> | none )",
+	"filter-function": "blur( <length>? ) | brightness( <alpha-value>? ) | contrast( <alpha-value>? ) | " +
+		"drop-shadow( <any-value>+ ) | grayscale( <alpha-value>? ) | hue-rotate( <angle>? ) | " +
+		"invert( <alpha-value>? ) | opacity( <alpha-value>? ) | saturate( <alpha-value>? ) | sepia( <alpha-value>? )",
+	"content-item": "<string> | <url> | <image> | counter( <any-value>+ ) | counters( <any-value>+ ) | " +
+		"attr( <any-value>+ ) | open-quote | close-quote | no-open-quote | no-close-quote",
+	"self-position":     "center | start | end | self-start | self-end | flex-start | flex-end",
+	"content-position":  "center | start | end | flex-start | flex-end",
+	"baseline-position": "[ first | last ]? baseline",
+	"blend-mode": "normal | multiply | screen | overlay | darken | lighten | color-dodge | color-burn | hard-light | " +
+		"soft-light | difference | exclusion | hue | saturation | color | luminosity",
+	"basic-shape": "inset( <any-value>+ ) | circle( <any-value>* ) | ellipse( <any-value>* ) | polygon( <any-value>+ ) | " +
+		"path( <any-value>+ ) | rect( <any-value>+ ) | xywh( <any-value>+ )",
+	"geometry-box": "<box> | margin-box | fill-box | stroke-box | view-box",
+	"cursor-keyword": "auto | default | none | context-menu | help | pointer | progress | wait | cell | crosshair | " +
+		"text | vertical-text | alias | copy | move | no-drop | not-allowed | grab | grabbing | e-resize | n-resize | " +
+		"ne-resize | nw-resize | s-resize | se-resize | sw-resize | w-resize | ew-resize | ns-resize | nesw-resize | " +
+		"nwse-resize | col-resize | row-resize | all-scroll | zoom-in | zoom-out",
+}
+
+// propertySyntaxes define properties in value definition syntax
+var propertySyntaxes = map[string]string{
+	"accent-color":               "auto | <color>",
+	"align-content":              "normal | <baseline-position> | space-between | space-around | space-evenly | stretch | <content-position>",
+	"align-items":                "normal | stretch | <baseline-position> | <self-position>",
+	"align-self":                 "auto | normal | stretch | <baseline-position> | <self-position>",
+	"animation":                  "<single-animation>#",
+	"animation-composition":      "[ replace | add | accumulate ]#",
+	"animation-delay":            "<time>#",
+	"animation-direction":        "[ normal | reverse | alternate | alternate-reverse ]#",
+	"animation-duration":         "<time>#",
+	"animation-fill-mode":        "[ none | forwards | backwards | both ]#",
+	"animation-iteration-count":  "[ infinite | <number> ]#",
+	"animation-name":             "[ none | <keyframes-name> ]#",
+	"animation-play-state":       "[ running | paused ]#",
+	"animation-timing-function":  "<easing-function>#",
+	"appearance":                 "none | auto | menulist-button | textfield | button | checkbox | listbox | menulist | meter | progress-bar | push-button | radio | searchfield | slider-horizontal | square-button | textarea",
+	"aspect-ratio":               "auto || <number> [ / <number> ]?",
+	"backdrop-filter":            "none | [ <filter-function> | <url> ]+",
+	"backface-visibility":        "visible | hidden",
+	"background":                 "[ <bg-layer> , ]* <final-bg-layer>",
+	"background-attachment":      "<attachment>#",
+	"background-blend-mode":      "<blend-mode>#",
+	"background-clip":            "[ <box> | text ]#",
+	"background-color":           "<color>",
+	"background-image":           "<bg-image>#",
+	"background-origin":          "<box>#",
+	"background-position":        "<position>#",
+	"background-repeat":          "<repeat-style>#",
+	"background-size":            "<bg-size>#",
+	"block-size":                 "<'width'>",
+	"border":                     "<line-width> || <line-style> || <color>",
+	"border-block":               "<line-width> || <line-style> || <color>",
+	"border-block-color":         "<color>{1,2}",
+	"border-block-end":           "<line-width> || <line-style> || <color>",
+	"border-block-end-color":     "<color>",
+	"border-block-end-style":     "<line-style>",
+	"border-block-end-width":     "<line-width>",
+	"border-block-start":         "<line-width> || <line-style> || <color>",
+	"border-block-start-color":   "<color>",
+	"border-block-start-style":   "<line-style>",
+	"border-block-start-width":   "<line-width>",
+	"border-block-style":         "<line-style>{1,2}",
+	"border-block-width":         "<line-width>{1,2}",
+	"border-bottom":              "<line-width> || <line-style> || <color>",
+	"border-bottom-color":        "<color>",
+	"border-bottom-left-radius":  "<length-percentage>{1,2}",
+	"border-bottom-right-radius": "<length-percentage>{1,2}",
+	"border-bottom-style":        "<line-style>",
+	"border-bottom-width":        "<line-width>",
+	"border-collapse":            "collapse | separate",
+	"border-color":               "<color>{1,4}",
+	"border-end-end-radius":      "<length-percentage>{1,2}",
+	"border-end-start-radius":    "<length-percentage>{1,2}",
+	"border-image-outset":        "[ <length> | <number> ]{1,4}",
+	"border-image-repeat":        "[ stretch | repeat | round | space ]{1,2}",
+	"border-image-slice":         "[ <number> | <percentage> ]{1,4} && fill?",
+	"border-image-source":        "none | <image>",
+	"border-image-width":         "[ <length-percentage> | <number> | auto ]{1,4}",
+	"border-inline":              "<line-width> || <line-style> || <color>",
+	"border-inline-color":        "<color>{1,2}",
+	"border-inline-end":          "<line-width> || <line-style> || <color>",
+	"border-inline-end-color":    "<color>",
+	"border-inline-end-style":    "<line-style>",
+	"border-inline-end-width":    "<line-width>",
+	"border-inline-start":        "<line-width> || <line-style> || <color>",
+	"border-inline-start-color":  "<color>",
+	"border-inline-start-style":  "<line-style>",
+	"border-inline-start-width":  "<line-width>",
+	"border-inline-style":        "<line-style>{1,2}",
+	"border-inline-width":        "<line-width>{1,2}",
+	"border-left":                "<line-width> || <line-style> || <color>",
+	"border-left-color":          "<color>",
+	"border-left-style":          "<line-style>",
+	"border-left-width":          "<line-width>",
+	"border-radius":              "<length-percentage>{1,4} [ / <length-percentage>{1,4} ]?",
+	"border-right":               "<line-width> || <line-style> || <color>",
+	"border-right-color":         "<color>",
+	"border-right-style":         "<line-style>",
+	"border-right-width":         "<line-width>",
+	"border-spacing":             "<length>{1,2}",
+	"border-start-end-radius":    "<length-percentage>{1,2}",
+	"border-start-start-radius":  "<length-percentage>{1,2}",
+	"border-style":               "<line-style>{1,4}",
+	"border-top":                 "<line-width> || <line-style> || <color>",
+	"border-top-color":           "<color>",
+	"border-top-left-radius":     "<length-percentage>{1,2}",
+	"border-top-right-radius":    "<length-percentage>{1,2}",
+	"border-top-style":           "<line-style>",
+	"border-top-width":           "<line-width>",
+	"border-width":               "<line-width>{1,4}",
+	"bottom":                     "<length-percentage> | auto",
+	"box-decoration-break":       "slice | clone",
+	"box-shadow":                 "none | <shadow>#",
+	"box-sizing":                 "content-box | border-box",
+	"break-after":                "auto | avoid | always | all | avoid-page | page | left | right | recto | verso | avoid-column | column | avoid-region | region",
+	"break-before":               "auto | avoid | always | all | avoid-page | page | left | right | recto | verso | avoid-column | column | avoid-region | region",
+	"break-inside":               "auto | avoid | avoid-page | avoid-column | avoid-region",
+	"caption-side":               "top | bottom | block-start | block-end | inline-start | inline-end",
+	"caret-color":                "auto | <color>",
+	"clear":                      "none | left | right | both | inline-start | inline-end",
+	"clip":                       "rect( <any-value>+ ) | auto",
+	"clip-path":                  "none | <url> | [ <basic-shape> || <geometry-box> ]",
+	"color":                      "<color>",
+	"color-scheme":               "normal | [ light | dark | <custom-ident> ]+ && only?",
+	"column-count":               "auto | <integer>",
+	"column-fill":                "auto | balance | balance-all",
+	"column-gap":                 "normal | <length-percentage>",
+	"column-rule":                "<'column-rule-width'> || <'column-rule-style'> || <'column-rule-color'>",
+	"column-rule-color":          "<color>",
+	"column-rule-style":          "<line-style>",
+	"column-rule-width":          "<line-width>",
+	"column-span":                "none | all",
+	"column-width":               "auto | <length>",
+	"columns":                    "<'column-width'> || <'column-count'>",
+	"contain":                    "none | strict | content | [ [ size | inline-size ] || layout || style || paint ]",
+	"container-name":             "none | <custom-ident>+",
+	"container-type":             "normal | size | inline-size",
+	"content":                    "normal | none | <content-item>+",
+	"content-visibility":         "visible | auto | hidden",
+	"counter-increment":          "[ <custom-ident> <integer>? ]+ | none",
+	"counter-reset":              "[ <custom-ident> <integer>? ]+ | none",
+	"counter-set":                "[ <custom-ident> <integer>? ]+ | none",
+	"cursor":                     "[ <url> [ <number> <number> ]? , ]* <cursor-keyword>",
+	"direction":                  "ltr | rtl",
+	"display": "block | inline | inline-block | flex | inline-flex | grid | inline-grid | flow-root | none | " +
+		"contents | table | inline-table | table-row | table-cell | table-caption | table-column | " +
+		"table-column-group | table-header-group | table-footer-group | table-row-group | list-item | " +
+		"run-in | [ block | inline ] [ flow | flow-root | flex | grid | table ]",
+	"empty-cells":    "show | hide",
+	"filter":         "none | [ <filter-function> | <url> ]+",
+	"flex":           "none | [ <'flex-grow'> <'flex-shrink'>? || <'flex-basis'> ]",
+	"flex-basis":     "content | <'width'>",
+	"flex-direction": "row | row-reverse | column | column-reverse",
+	"flex-flow":      "<'flex-direction'> || <'flex-wrap'>",
+	"flex-grow":      "<number>",
+	"flex-shrink":    "<number>",
+	"flex-wrap":      "nowrap | wrap | wrap-reverse",
+	"float":          "left | right | none | inline-start | inline-end",
+	"font": "[ [ <'font-style'> || small-caps || <'font-weight'> || <'font-stretch'> ]? <'font-size'> [ / <'line-height'> ]? <'font-family'> ] | " +
+		"caption | icon | menu | message-box | small-caption | status-bar",
+	"font-family":                "[ <family-name> | <generic-family> ]#",
+	"font-feature-settings":      "normal | [ <string> [ <integer> | on | off ]? ]#",
+	"font-kerning":               "auto | normal | none",
+	"font-optical-sizing":        "auto | none",
+	"font-size":                  "<absolute-size> | <relative-size> | <length-percentage>",
+	"font-size-adjust":           "none | [ ex-height | cap-height | ch-width | ic-width | ic-height ]? [ from-font | <number> ]",
+	"font-stretch":               "<font-stretch-absolute>",
+	"font-style":                 "normal | italic | oblique <angle>?",
+	"font-synthesis":             "none | [ weight || style || small-caps || position ]",
+	"font-variant":               "normal | none | small-caps | all-small-caps | petite-caps | all-petite-caps | unicase | titling-caps",
+	"font-variant-caps":          "normal | small-caps | all-small-caps | petite-caps | all-petite-caps | unicase | titling-caps",
+	"font-variant-ligatures":     "normal | none | [ [ common-ligatures | no-common-ligatures ] || [ discretionary-ligatures | no-discretionary-ligatures ] || [ historical-ligatures | no-historical-ligatures ] || [ contextual | no-contextual ] ]",
+	"font-variant-numeric":       "normal | [ [ lining-nums | oldstyle-nums ] || [ proportional-nums | tabular-nums ] || [ diagonal-fractions | stacked-fractions ] || ordinal || slashed-zero ]",
+	"font-variant-position":      "normal | sub | super",
+	"font-variation-settings":    "normal | [ <string> <number> ]#",
+	"font-weight":                "<font-weight-absolute> | bolder | lighter",
+	"gap":                        "<'row-gap'> <'column-gap'>?",
+	"grid-area":                  "<grid-line> [ / <grid-line> ]{0,3}",
+	"grid-auto-columns":          "<track-size>+",
+	"grid-auto-flow":             "[ row | column ] || dense",
+	"grid-auto-rows":             "<track-size>+",
+	"grid-column":                "<grid-line> [ / <grid-line> ]?",
+	"grid-column-end":            "<grid-line>",
+	"grid-column-start":          "<grid-line>",
+	"grid-row":                   "<grid-line> [ / <grid-line> ]?",
+	"grid-row-end":               "<grid-line>",
+	"grid-row-start":             "<grid-line>",
+	"grid-template-areas":        "none | <string>+",
+	"grid-template-columns":      "none | subgrid | masonry | [ <track-size> | <line-names> ]+",
+	"grid-template-rows":         "none | subgrid | masonry | [ <track-size> | <line-names> ]+",
+	"height":                     "auto | <length-percentage> | min-content | max-content | fit-content | fit-content( <length-percentage> )",
+	"hyphenate-character":        "auto | <string>",
+	"hyphens":                    "none | manual | auto",
+	"image-rendering":            "auto | smooth | high-quality | crisp-edges | pixelated",
+	"inline-size":                "<'width'>",
+	"inset":                      "[ <length-percentage> | auto ]{1,4}",
+	"inset-block":                "[ <length-percentage> | auto ]{1,2}",
+	"inset-block-end":            "<length-percentage> | auto",
+	"inset-block-start":          "<length-percentage> | auto",
+	"inset-inline":               "[ <length-percentage> | auto ]{1,2}",
+	"inset-inline-end":           "<length-percentage> | auto",
+	"inset-inline-start":         "<length-percentage> | auto",
+	"isolation":                  "auto | isolate",
+	"justify-content":            "normal | space-between | space-around | space-evenly | stretch | <content-position> | left | right",
+	"justify-items":              "normal | stretch | <baseline-position> | <self-position> | left | right | legacy",
+	"justify-self":               "auto | normal | stretch | <baseline-position> | <self-position> | left | right",
+	"left":                       "<length-percentage> | auto",
+	"letter-spacing":             "normal | <length>",
+	"line-break":                 "auto | loose | normal | strict | anywhere",
+	"line-height":                "normal | <number> | <length-percentage>",
+	"list-style":                 "<'list-style-type'> || <'list-style-position'> || <'list-style-image'>",
+	"list-style-image":           "<image> | none",
+	"list-style-position":        "inside | outside",
+	"list-style-type":            "<custom-ident> | <string> | none",
+	"margin":                     "[ <length-percentage> | auto ]{1,4}",
+	"margin-block":               "[ <length-percentage> | auto ]{1,2}",
+	"margin-block-end":           "<length-percentage> | auto",
+	"margin-block-start":         "<length-percentage> | auto",
+	"margin-bottom":              "<length-percentage> | auto",
+	"margin-inline":              "[ <length-percentage> | auto ]{1,2}",
+	"margin-inline-end":          "<length-percentage> | auto",
+	"margin-inline-start":        "<length-percentage> | auto",
+	"margin-left":                "<length-percentage> | auto",
+	"margin-right":               "<length-percentage> | auto",
+	"margin-top":                 "<length-percentage> | auto",
+	"mask-image":                 "[ none | <image> ]#",
+	"mask-position":              "<position>#",
+	"mask-repeat":                "<repeat-style>#",
+	"mask-size":                  "<bg-size>#",
+	"max-block-size":             "<'max-width'>",
+	"max-height":                 "none | <length-percentage> | min-content | max-content | fit-content",
+	"max-inline-size":            "<'max-width'>",
+	"max-width":                  "none | <length-percentage> | min-content | max-content | fit-content",
+	"min-block-size":             "<'min-width'>",
+	"min-height":                 "auto | <length-percentage> | min-content | max-content | fit-content",
+	"min-inline-size":            "<'min-width'>",
+	"min-width":                  "auto | <length-percentage> | min-content | max-content | fit-content",
+	"mix-blend-mode":             "<blend-mode> | plus-darker | plus-lighter",
+	"object-fit":                 "fill | contain | cover | none | scale-down",
+	"object-position":            "<position>",
+	"opacity":                    "<alpha-value>",
+	"order":                      "<integer>",
+	"orphans":                    "<integer>",
+	"outline":                    "<'outline-color'> || <'outline-style'> || <'outline-width'>",
+	"outline-color":              "<color> | invert",
+	"outline-offset":             "<length>",
+	"outline-style":              "auto | <line-style>",
+	"outline-width":              "<line-width>",
+	"overflow":                   "[ visible | hidden | clip | scroll | auto ]{1,2}",
+	"overflow-anchor":            "auto | none",
+	"overflow-block":             "visible | hidden | clip | scroll | auto",
+	"overflow-inline":            "visible | hidden | clip | scroll | auto",
+	"overflow-wrap":              "normal | break-word | anywhere",
+	"overflow-x":                 "visible | hidden | clip | scroll | auto",
+	"overflow-y":                 "visible | hidden | clip | scroll | auto",
+	"overscroll-behavior":        "[ contain | none | auto ]{1,2}",
+	"overscroll-behavior-block":  "contain | none | auto",
+	"overscroll-behavior-inline": "contain | none | auto",
+	"overscroll-behavior-x":      "contain | none | auto",
+	"overscroll-behavior-y":      "contain | none | auto",
+	"padding":                    "<length-percentage>{1,4}",
+	"padding-block":              "<length-percentage>{1,2}",
+	"padding-block-end":          "<length-percentage>",
+	"padding-block-start":        "<length-percentage>",
+	"padding-bottom":             "<length-percentage>",
+	"padding-inline":             "<length-percentage>{1,2}",
+	"padding-inline-end":         "<length-percentage>",
+	"padding-inline-start":       "<length-percentage>",
+	"padding-left":               "<length-percentage>",
+	"padding-right":              "<length-percentage>",
+	"padding-top":                "<length-percentage>",
+	"page-break-after":           "auto | always | avoid | left | right",
+	"page-break-before":          "auto | always | avoid | left | right",
+	"page-break-inside":          "auto | avoid",
+	"paint-order":                "normal | [ fill || stroke || markers ]",
+	"perspective":                "none | <length>",
+	"perspective-origin":         "<position>",
+	"place-content":              "<'align-content'> <'justify-content'>?",
+	"place-items":                "<'align-items'> <'justify-items'>?",
+	"place-self":                 "<'align-self'> <'justify-self'>?",
+	"pointer-events":             "auto | none | visiblepainted | visiblefill | visiblestroke | visible | painted | fill | stroke | all",
+	"position":                   "static | relative | absolute | fixed | sticky",
+	"quotes":                     "none | auto | [ <string> <string> ]+",
+	"resize":                     "none | both | horizontal | vertical | block | inline",
+	"right":                      "<length-percentage> | auto",
+	"rotate":                     "none | <angle> | [ x | y | z | <number>{3} ] && <angle>",
+	"row-gap":                    "normal | <length-percentage>",
+	"scale":                      "none | [ <number> | <percentage> ]{1,3}",
+	"scroll-behavior":            "auto | smooth",
+	"scroll-margin":              "<length>{1,4}",
+	"scroll-margin-block":        "<length>{1,2}",
+	"scroll-margin-bottom":       "<length>",
+	"scroll-margin-inline":       "<length>{1,2}",
+	"scroll-margin-left":         "<length>",
+	"scroll-margin-right":        "<length>",
+	"scroll-margin-top":          "<length>",
+	"scroll-padding":             "[ auto | <length-percentage> ]{1,4}",
+	"scroll-padding-block":       "[ auto | <length-percentage> ]{1,2}",
+	"scroll-padding-bottom":      "auto | <length-percentage>",
+	"scroll-padding-inline":      "[ auto | <length-percentage> ]{1,2}",
+	"scroll-padding-left":        "auto | <length-percentage>",
+	"scroll-padding-right":       "auto | <length-percentage>",
+	"scroll-padding-top":         "auto | <length-percentage>",
+	"scroll-snap-align":          "[ none | start | end | center ]{1,2}",
+	"scroll-snap-stop":           "normal | always",
+	"scroll-snap-type":           "none | [ x | y | block | inline | both ] [ mandatory | proximity ]?",
+	"scrollbar-color":            "auto | <color>{2}",
+	"scrollbar-gutter":           "auto | stable && both-edges?",
+	"scrollbar-width":            "auto | thin | none",
+	"shape-margin":               "<length-percentage>",
+	"tab-size":                   "<number> | <length>",
+	"table-layout":               "auto | fixed",
+	"text-align":                 "start | end | left | right | center | justify | match-parent",
+	"text-align-last":            "auto | start | end | left | right | center | justify | match-parent",
+	"text-decoration":            "<'text-decoration-line'> || <'text-decoration-style'> || <'text-decoration-color'>",
+	"text-decoration-color":      "<color>",
+	"text-decoration-line":       "none | [ underline || overline || line-through || blink ]",
+	"text-decoration-style":      "solid | double | dotted | dashed | wavy",
+	"text-decoration-thickness":  "auto | from-font | <length-percentage>",
+	"text-indent":                "<length-percentage> && hanging? && each-line?",
+	"text-justify":               "auto | none | inter-word | inter-character",
+	"text-orientation":           "mixed | upright | sideways",
+	"text-overflow":              "[ clip | ellipsis | <string> ]{1,2}",
+	"text-rendering":             "auto | optimizespeed | optimizelegibility | geometricprecision",
+	"text-shadow":                "none | <text-shadow>#",
+	"text-size-adjust":           "none | auto | <percentage>",
+	"text-transform":             "none | capitalize | uppercase | lowercase | full-width | full-size-kana",
+	"text-underline-offset":      "auto | <length-percentage>",
+	"text-underline-position":    "auto | from-font | [ under || [ left | right ] ]",
+	"text-wrap":                  "wrap | nowrap | balance | stable | pretty",
+	"top":                        "<length-percentage> | auto",
+	"touch-action":               "auto | none | [ [ pan-x | pan-left | pan-right ] || [ pan-y | pan-up | pan-down ] || pinch-zoom ] | manipulation",
+	"transform":                  "none | <transform-function>+",
+	"transform-box":              "content-box | border-box | fill-box | stroke-box | view-box",
+	"transform-origin":           "[ left | center | right | top | bottom | <length-percentage> ]{1,2} <length>?",
+	"transform-style":            "flat | preserve-3d",
+	"transition":                 "<single-transition>#",
+	"transition-behavior":        "[ normal | allow-discrete ]#",
+	"transition-delay":           "<time>#",
+	"transition-duration":        "<time>#",
+	"transition-property":        "none | <single-transition-property>#",
+	"transition-timing-function": "<easing-function>#",
+	"translate":                  "none | <length-percentage> [ <length-percentage> <length>? ]?",
+	"unicode-bidi":               "normal | embed | isolate | bidi-override | isolate-override | plaintext",
+	"user-select":                "auto | text | none | contain | all",
+	"vertical-align":             "baseline | sub | super | text-top | text-bottom | middle | top | bottom | <length-percentage>",
+	"view-transition-name":       "none | <custom-ident>",
+	"visibility":                 "visible | hidden | collapse",
+	"white-space":                "normal | pre | nowrap | pre-wrap | pre-line | break-spaces",
+	"widows":                     "<integer>",
+	"width":                      "auto | <length-percentage> | min-content | max-content | fit-content | fit-content( <length-percentage> )",
+	"will-change":                "auto | [ scroll-position | contents | <custom-ident> ]#",
+	"word-break":                 "normal | break-all | keep-all | break-word",
+	"word-spacing":               "normal | <length>",
+	"word-wrap":                  "normal | break-word | anywhere",
+	"writing-mode":               "horizontal-tb | vertical-rl | vertical-lr | sideways-rl | sideways-lr",
+	"z-index":                    "auto | <integer>",
+	"zoom":                       "normal | reset | <number> | <percentage>",
+}
+
+// uncheckedProperties are known properties without a syntax in
+// propertySyntaxes, their values are not checked
+var uncheckedProperties = keywords(
+	"anchor-name", "animation-range", "animation-timeline", "border-image", "container", "fill", "fill-opacity",
+	"font-language-override", "font-palette", "font-variant-alternates", "font-variant-east-asian",
+	"font-variant-emoji", "grid", "grid-template", "marker", "mask", "mask-border", "mask-clip", "mask-composite",
+	"mask-mode", "mask-origin", "mask-type", "offset", "offset-anchor", "offset-distance", "offset-path",
+	"offset-position", "offset-rotate", "position-anchor", "position-area", "position-try", "shape-image-threshold",
+	"shape-outside", "stroke", "stroke-dasharray", "stroke-dashoffset", "stroke-linecap", "stroke-linejoin",
+	"stroke-miterlimit", "stroke-opacity", "stroke-width", "text-emphasis", "text-emphasis-color",
+	"text-emphasis-position", "text-emphasis-style", "text-shadow-color", "view-timeline", "scroll-timeline",
+)
+
+// fontFaceSyntaxes define descriptors of @font-face
+var fontFaceSyntaxes = map[string]string{
+	"ascent-override":         "normal | <percentage>",
+	"descent-override":        "normal | <percentage>",
+	"font-display":            "auto | block | swap | fallback | optional",
+	"font-family":             "<family-name>",
+	"font-feature-settings":   "normal | [ <string> [ <integer> | on | off ]? ]#",
+	"font-stretch":            "auto | <font-stretch-absolute>{1,2}",
+	"font-style":              "auto | normal | italic | oblique <angle>{0,2}",
+	"font-variation-settings": "normal | [ <string> <number> ]#",
+	"font-weight":             "auto | <font-weight-absolute>{1,2}",
+	"line-gap-override":       "normal | <percentage>",
+	"size-adjust":             "<percentage>",
+	"src":                     "[ <url> [ format( [ <string> | <custom-ident> ]# ) ]? [ tech( <any-value>+ ) ]? | local( <family-name> ) ]#",
+	"unicode-range":           "<urange>#",
+}
+
+var (
+	types      = parseSyntaxes(typeSyntaxes)
+	properties = parseSyntaxes(propertySyntaxes)
+	fontFace   = parseSyntaxes(fontFaceSyntaxes)
+)
+
+func parseSyntaxes(syntaxes map[string]string) map[string]*grammar {
+	ret := make(map[string]*grammar, len(syntaxes))
+	for k, v := range syntaxes {
+		ret[k] = mustParseGrammar(v)
+	}
+	return ret
+}
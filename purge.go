@@ -0,0 +1,333 @@
+package css2json
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// staticPseudos don't depend on user interaction, other pseudo-classes
+// are ignored by Purge
+var staticPseudos = keywords(
+	"is", "where", "matches", "-webkit-any", "-moz-any", "not", "has",
+	"nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type",
+	"first-child", "last-child", "only-child", "first-of-type", "last-of-type", "only-of-type",
+	"root", "empty", "scope", "lang",
+)
+
+// Purge returns statements without selectors matching nothing in documents.
+// A selector is kept when every class it uses is in classes, a ruleset
+// without selectors and an at-rule without nested statements are removed.
+// Pseudo-elements and dynamic pseudo-classes like :hover are ignored when
+// matching, rules of @keyframes are kept as is. Nested rules are returned
+// flattened like by Flatten.
+func Purge(s Statements, documents []*html.Node, classes []string) Statements {
+	allowed := map[string]bool{}
+	for _, c := range classes {
+		allowed[c] = true
+	}
+
+	p := &purger{
+		used: func(sel *Selector) bool {
+			if allowedClasses(sel, allowed) {
+				return true
+			}
+			static := staticSelector(sel)
+			for _, d := range documents {
+				if matchDocument(&static, d) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+
+	return p.statements(withoutNesting(s))
+}
+
+// purger removes unused selectors, at-rules without nested statements
+// like @font-face are kept as is
+type purger struct {
+	used func(*Selector) bool
+}
+
+func (p *purger) statements(s Statements) Statements {
+	var ret Statements
+	for k := range s {
+		if v, ok := p.statement(&s[k]); ok {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func (p *purger) statement(v *Statement) (Statement, bool) {
+	var ret Statement
+
+	if v.AtRule != nil {
+		ret.AtRule = p.atRule(v.AtRule)
+	}
+
+	if v.Ruleset != nil {
+		var selectors []Selector
+		for _, s := range v.Ruleset.Selectors {
+			if p.used(&s) {
+				selectors = append(selectors, s)
+			}
+		}
+		if len(selectors) > 0 {
+			ret.Ruleset = &Ruleset{Selectors: selectors, Declarations: v.Ruleset.Declarations}
+		}
+	}
+
+	return ret, ret.AtRule != nil || ret.Ruleset != nil
+}
+
+func (p *purger) atRule(v *AtRule) *AtRule {
+	if _, ok := v.Identifier.Information.(*KeyframesInformation); ok || v.Nested == nil {
+		return v
+	}
+
+	ret := &AtRule{Identifier: v.Identifier, Nested: []*Statement{}}
+	for _, i := range v.Nested {
+		if n, ok := p.statement(i); ok {
+			ret.Nested = append(ret.Nested, &n)
+		}
+	}
+
+	if len(ret.Nested) == 0 {
+		return nil
+	}
+
+	return ret
+}
+
+// allowedClasses reports whether selector uses classes and all of them
+// are allowed
+func allowedClasses(sel *Selector, allowed map[string]bool) bool {
+	var classes []TextBytes
+	collectClasses(&sel.Simple, &classes)
+	for k := range sel.Combinates {
+		collectClasses(&sel.Combinates[k].Simple, &classes)
+	}
+
+	if len(classes) == 0 || len(allowed) == 0 {
+		return false
+	}
+	for _, c := range classes {
+		if !allowed[string(c)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func collectClasses(v *Simple, dst *[]TextBytes) {
+	*dst = append(*dst, v.Classes...)
+	for k := range v.PseudoClasses {
+		for j := range v.PseudoClasses[k].Selectors {
+			s := &v.PseudoClasses[k].Selectors[j]
+			collectClasses(&s.Simple, dst)
+			for i := range s.Combinates {
+				collectClasses(&s.Combinates[i].Simple, dst)
+			}
+		}
+	}
+}
+
+func matchDocument(sel *Selector, root *html.Node) bool {
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && matchSelector(sel, c, nil) {
+			return true
+		}
+		if matchDocument(sel, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// staticSelector returns a copy of selector without pseudo-elements and
+// dynamic pseudo-classes, :not() with one of them is removed as a whole
+func staticSelector(sel *Selector) Selector {
+	ret := Selector{Simple: staticSimple(&sel.Simple)}
+	for _, c := range sel.Combinates {
+		ret.Combinates = append(ret.Combinates, Combinate{Combinator: c.Combinator, Simple: staticSimple(&c.Simple)})
+	}
+	return ret
+}
+
+func staticSimple(v *Simple) Simple {
+	ret := *v
+	ret.PseudoElements = nil
+	ret.PseudoClasses = nil
+	ret.Negations = nil
+
+	for _, n := range v.Negations {
+		if isStaticSimple(&n) {
+			ret.Negations = append(ret.Negations, n)
+		}
+	}
+
+	for _, p := range v.PseudoClasses {
+		ident := strings.ToLower(string(p.Ident))
+		if !staticPseudos[ident] {
+			continue
+		}
+		if ident == "not" {
+			if isStaticPseudo(&p) {
+				ret.PseudoClasses = append(ret.PseudoClasses, p)
+			}
+			continue
+		}
+		if len(p.Selectors) > 0 {
+			selectors := p.Selectors
+			p.Selectors = nil
+			for k := range selectors {
+				p.Selectors = append(p.Selectors, staticSelector(&selectors[k]))
+			}
+		}
+		ret.PseudoClasses = append(ret.PseudoClasses, p)
+	}
+
+	return ret
+}
+
+func isStaticSimple(v *Simple) bool {
+	if len(v.PseudoElements) > 0 {
+		return false
+	}
+	for k := range v.PseudoClasses {
+		if !isStaticPseudo(&v.PseudoClasses[k]) {
+			return false
+		}
+	}
+	for k := range v.Negations {
+		if !isStaticSimple(&v.Negations[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isStaticPseudo(v *Pseudo) bool {
+	if !staticPseudos[strings.ToLower(string(v.Ident))] {
+		return false
+	}
+	for k := range v.Selectors {
+		s := &v.Selectors[k]
+		if !isStaticSimple(&s.Simple) {
+			return false
+		}
+		for i := range s.Combinates {
+			if !isStaticSimple(&s.Combinates[i].Simple) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var (
+	templateAction = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+	templateString = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`")
+)
+
+// TemplateClasses collects class names from class attributes of Go
+// template sources, string literals of actions inside an attribute like
+// class="btn {{if .Active}}{{"active"}}{{end}}" are collected too. Names
+// built by actions like "icon-{{.Name}}" are skipped.
+func TemplateClasses(sources ...[]byte) []string {
+	set := map[string]bool{}
+
+	for _, src := range sources {
+		for _, value := range classAttributes(src) {
+			for _, action := range templateAction.FindAll(value, -1) {
+				for _, lit := range templateString.FindAll(action, -1) {
+					for _, c := range strings.Fields(string(lit[1 : len(lit)-1])) {
+						if isIdent(c) {
+							set[c] = true
+						}
+					}
+				}
+			}
+			for _, c := range strings.Fields(string(templateAction.ReplaceAllFunc(value, replaceAction))) {
+				if !strings.ContainsRune(c, 0) {
+					set[c] = true
+				}
+			}
+		}
+	}
+
+	ret := make([]string, 0, len(set))
+	for c := range set {
+		ret = append(ret, c)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// templateControls are actions which don't print a value
+var templateControls = keywords("if", "else", "end", "range", "with", "block", "define", "template", "break", "continue")
+
+// replaceAction replaces a control action by space, an action printing a
+// value by NUL marking a name unknown until execution
+func replaceAction(action []byte) []byte {
+	inner := strings.Trim(string(action[2:len(action)-2]), "- \t\r\n")
+	words := strings.Fields(inner)
+	if strings.HasPrefix(inner, "/*") || len(words) > 0 && templateControls[words[0]] {
+		return []byte{space}
+	}
+	return []byte{0}
+}
+
+// classAttributes returns values of class attributes, quotes inside
+// template actions don't close the value
+func classAttributes(src []byte) [][]byte {
+	var ret [][]byte
+
+	lower := bytes.ToLower(src)
+	for k := 0; k < len(src); {
+		idx := bytes.Index(lower[k:], []byte("class"))
+		if idx < 0 {
+			break
+		}
+		k += idx + len("class")
+		if k-len("class") > 0 && !isSpace(src[k-len("class")-1]) {
+			continue
+		}
+
+		j := k
+		for j < len(src) && isSpace(src[j]) {
+			j++
+		}
+		if j >= len(src) || src[j] != '=' {
+			continue
+		}
+		j++
+		for j < len(src) && isSpace(src[j]) {
+			j++
+		}
+		if j >= len(src) || src[j] != doubleQuote && src[j] != '\'' {
+			continue
+		}
+
+		quote, start := src[j], j+1
+		for j = start; j < len(src) && src[j] != quote; j++ {
+			if bytes.HasPrefix(src[j:], []byte("{{")) {
+				if end := bytes.Index(src[j:], []byte("}}")); end >= 0 {
+					j += end + 1
+				}
+			}
+		}
+		ret = append(ret, src[start:j])
+		k = j
+	}
+
+	return ret
+}
@@ -0,0 +1,95 @@
+package css2json
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestPurge(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<main class="page"><a class="btn" href="#">x</a><ul><li>1</li></ul></main>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyframes := Statement{
+		AtRule: &AtRule{
+			Identifier: Identifier{
+				Type:        TextBytes("keyframes"),
+				Information: &KeyframesInformation{Value: TextBytes("spin")},
+			},
+			Nested: []*Statement{
+				func() *Statement { s := testRule([]string{"from"}, "opacity:0"); return &s }(),
+			},
+		},
+	}
+
+	s := Statements{
+		testRule([]string{".page .btn", ".unused"}, "color:red"),
+		testRule([]string{".card"}, "margin:0"),
+		testRule([]string{".modal.open"}, "display:block"),
+		testRule([]string{"a:hover", "a::before", "li:not(:hover)", "li:not(.x)"}, "color:blue"),
+		testRule([]string{"ul:has(> .item)", "p"}, "padding:0"),
+		testMedia("max-width", "600px",
+			testRule([]string{".btn"}, "width:100%"),
+			testRule([]string{".sidebar"}, "display:none"),
+		),
+		testMedia("min-width", "900px",
+			testRule([]string{".sidebar"}, "display:block"),
+		),
+		keyframes,
+	}
+
+	got, err := Encode(Purge(s, []*html.Node{doc}, []string{"modal", "open"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ".page .btn{color:red}" +
+		".modal.open{display:block}" +
+		"a:hover,a::before,li:not(:hover),li:not(.x){color:blue}" +
+		"@media (max-width:600px){.btn{width:100%}}" +
+		"@keyframes spin{from{opacity:0}}"
+	if string(got) != want {
+		t.Errorf("Purge() = %s, want %s", got, want)
+	}
+}
+
+func TestPurge_nested(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<div class="a"><b class="b">x</b></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Statements{
+		testNested(testRule([]string{".a"}, "color:red"),
+			testRule([]string{".b"}, "margin:0"),
+			testRule([]string{".c"}, "margin:1px"),
+		),
+	}
+
+	got, err := Encode(Purge(s, []*html.Node{doc}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ".a{color:red}.a .b{margin:0}"; string(got) != want {
+		t.Errorf("Purge() = %s, want %s", got, want)
+	}
+}
+
+func TestTemplateClasses(t *testing.T) {
+	src := []byte(`{{define "button"}}
+<button class="btn {{if .Primary}}btn-primary{{else}}{{"btn-default"}}{{end}} {{.Extra}}" type="button">
+	<span CLASS='icon icon-{{.Icon}} {{}}{{/* note */}}'></span>
+	<i data-class="not-a-class" class=
+		"{{ printf "%s" "x-large" }}"></i>
+</button>
+{{end}}`)
+
+	want := []string{"btn", "btn-default", "btn-primary", "icon", "x-large"}
+	if got := TemplateClasses(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateClasses() = %v, want %v", got, want)
+	}
+}
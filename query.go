@@ -0,0 +1,244 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidMediaQuery
+var ErrInvalidMediaQuery = errors.New("invalid media query")
+
+// QueryResult is a ruleset or a declaration found in statements with
+// at-rules enclosing it from the outermost one. Ruleset is nil for a
+// descriptor of @font-face.
+type QueryResult struct {
+	Path        []*AtRule
+	Ruleset     *Ruleset
+	Declaration *Declaration
+}
+
+// FindDeclarations returns declarations of property in rulesets and
+// @font-face, the name is compared case-insensitively unless it is a
+// custom property
+func (s Statements) FindDeclarations(property string) []QueryResult {
+	var ret []QueryResult
+
+	s.query(func(path []*AtRule, rs *Ruleset, d *Declaration) {
+		if d == nil {
+			return
+		}
+		if isCustomProperty(TextBytes(property)) && string(d.Property) == property ||
+			!isCustomProperty(TextBytes(property)) && strings.EqualFold(string(d.Property), property) {
+			ret = append(ret, QueryResult{Path: path, Ruleset: rs, Declaration: d})
+		}
+	})
+
+	return ret
+}
+
+// RulesMatchingSelector returns rulesets having any of selectors in their
+// selector list, selectors are compared as they are encoded
+func (s Statements) RulesMatchingSelector(selector string) ([]QueryResult, error) {
+	parsed, err := parseSelectors([]byte(selector))
+	if err != nil {
+		return nil, err
+	}
+
+	want := map[string]bool{}
+	for k := range parsed {
+		buf := &bytes.Buffer{}
+		if err := parsed[k].encode(buf); err != nil {
+			return nil, err
+		}
+		want[buf.String()] = true
+	}
+
+	var ret []QueryResult
+	s.query(func(path []*AtRule, rs *Ruleset, d *Declaration) {
+		if d != nil || rs == nil {
+			return
+		}
+		for k := range rs.Selectors {
+			buf := &bytes.Buffer{}
+			if rs.Selectors[k].encode(buf) == nil && want[buf.String()] {
+				ret = append(ret, QueryResult{Path: path, Ruleset: rs})
+				return
+			}
+		}
+	})
+
+	return ret, nil
+}
+
+// RulesInMedia returns rulesets inside @media matching query like "print"
+// or "screen and (min-width: 800px)". A media query of @media matches when
+// it has the media type and all conditions of any of queries, so "print"
+// matches "@media print and (color)" and "@media only print" too.
+func (s Statements) RulesInMedia(query string) ([]QueryResult, error) {
+	queries, err := parseMediaQueries([]byte(query))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []QueryResult
+	s.query(func(path []*AtRule, rs *Ruleset, d *Declaration) {
+		if d != nil || rs == nil {
+			return
+		}
+		for _, at := range path {
+			info, ok := at.Identifier.Information.(*MediaInformation)
+			if !ok {
+				continue
+			}
+			for k := range queries {
+				if mediaContains(info, &queries[k]) {
+					ret = append(ret, QueryResult{Path: path, Ruleset: rs})
+					return
+				}
+			}
+		}
+	})
+
+	return ret, nil
+}
+
+// query calls fn for every ruleset and declaration with the path of
+// enclosing at-rules, statements are not changed
+func (s Statements) query(fn func(path []*AtRule, rs *Ruleset, d *Declaration)) {
+	for k := range s {
+		queryStatement(&s[k], []*AtRule{}, fn)
+	}
+}
+
+// queryStatement calls fn for the statement and its nested ones, path is
+// copied on adding an at-rule as results keep it
+func queryStatement(v *Statement, path []*AtRule, fn func(path []*AtRule, rs *Ruleset, d *Declaration)) {
+	if v.AtRule != nil {
+		path = append(path[:len(path):len(path)], v.AtRule)
+		if info, ok := v.AtRule.Identifier.Information.(*FontFaceInformation); ok {
+			for k := range info.Declarations {
+				fn(path, nil, &info.Declarations[k])
+			}
+		}
+		for _, i := range v.AtRule.Nested {
+			queryStatement(i, path, fn)
+		}
+	}
+
+	if v.Ruleset != nil {
+		fn(path, v.Ruleset, nil)
+		for k := range v.Ruleset.Declarations {
+			fn(path, v.Ruleset, &v.Ruleset.Declarations[k])
+		}
+		for _, i := range v.Ruleset.Nested {
+			queryStatement(i, path, fn)
+		}
+	}
+}
+
+// mediaContains reports whether any query of info has the media type and
+// all conditions of want, "only" of a type is ignored as it doesn't change
+// matching, "not" has to match
+func mediaContains(info *MediaInformation, want *Query) bool {
+	negated := func(t *Type) bool {
+		return strings.EqualFold(string(t.Operator), "not")
+	}
+
+	for _, q := range info.Queries {
+		if want.Type != nil && (q.Type == nil ||
+			!strings.EqualFold(string(q.Type.Value), string(want.Type.Value)) || negated(q.Type) != negated(want.Type)) {
+			continue
+		}
+		found := true
+		for _, c := range want.Conditions {
+			found = found && hasCondition(q.Conditions, &c)
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCondition(conditions []Condition, want *Condition) bool {
+	operator := func(c *Condition) string {
+		if strings.EqualFold(string(c.Operator), "and") {
+			return ""
+		}
+		return strings.ToLower(string(c.Operator))
+	}
+
+	for k := range conditions {
+		c := &conditions[k]
+		if strings.EqualFold(string(c.Feature), string(want.Feature)) && operator(c) == operator(want) &&
+			strings.Join(strings.Fields(string(c.Value)), "") == strings.Join(strings.Fields(string(want.Value)), "") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMediaQueries parses a comma separated list of media queries, a word
+// before a condition is kept as its operator
+func parseMediaQueries(b []byte) ([]Query, error) {
+	var ret []Query
+
+	for _, part := range splitTopLevel(b, comma) {
+		var (
+			q        Query
+			operator []byte
+		)
+
+		for k := 0; k < len(part); {
+			switch {
+			case isSpace(part[k]):
+				k++
+			case part[k] == leftParenthesis:
+				end := closingBracket(part, k, leftParenthesis, rightParenthesis)
+				if end == len(part) {
+					return nil, fmt.Errorf("%w %q", ErrInvalidMediaQuery, part)
+				}
+				c := Condition{Operator: TextBytes(operator)}
+				inner := part[k+1 : end]
+				if idx := bytes.IndexByte(inner, colon); idx >= 0 {
+					c.Feature = TextBytes(bytes.TrimSpace(inner[:idx]))
+					c.Value = TextBytes(bytes.TrimSpace(inner[idx+1:]))
+				} else {
+					c.Feature = TextBytes(bytes.TrimSpace(inner))
+				}
+				q.Conditions = append(q.Conditions, c)
+				operator = nil
+				k = end + 1
+			default:
+				end := skipIdent(part, k)
+				if end == k {
+					return nil, fmt.Errorf("%w %q", ErrInvalidMediaQuery, part)
+				}
+				word := part[k:end]
+				k = end
+				switch strings.ToLower(string(word)) {
+				case "and", "or", "not", "only":
+					if operator != nil {
+						return nil, fmt.Errorf("%w %q", ErrInvalidMediaQuery, part)
+					}
+					operator = word
+					continue
+				}
+				if q.Type != nil || len(q.Conditions) > 0 {
+					return nil, fmt.Errorf("%w %q", ErrInvalidMediaQuery, part)
+				}
+				q.Type = &Type{Operator: TextBytes(operator), Value: TextBytes(word)}
+				operator = nil
+			}
+		}
+
+		if operator != nil || q.Type == nil && len(q.Conditions) == 0 {
+			return nil, fmt.Errorf("%w %q", ErrInvalidMediaQuery, part)
+		}
+		ret = append(ret, q)
+	}
+
+	return ret, nil
+}
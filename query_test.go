@@ -0,0 +1,282 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testQueryStatements holds rules in print and screen media and a layer
+func testQueryStatements() Statements {
+	screen := testMedia("min-width", "800px", testRule([]string{".card"}, "color:#333", "margin:0"))
+	screen.AtRule.Identifier.Information.(*MediaInformation).Queries[0].Type = &Type{Value: TextBytes("screen")}
+	screen.AtRule.Identifier.Information.(*MediaInformation).Queries[0].Conditions[0].Operator = TextBytes("and")
+
+	print := Statement{
+		AtRule: &AtRule{
+			Identifier: Identifier{
+				Type: TextBytes("media"),
+				Information: &MediaInformation{
+					Queries: []Query{{Type: &Type{Value: TextBytes("print")}}},
+				},
+			},
+			Nested: []*Statement{},
+		},
+	}
+	rule := testRule([]string{"a", ".card > h2"}, "COLOR:black")
+	layer := testLayer([]string{"base"}, rule)
+	print.AtRule.Nested = append(print.AtRule.Nested, &layer)
+
+	return Statements{
+		testRule([]string{".card"}, "color:red", "--color:blue"),
+		screen,
+		print,
+		{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type:        TextBytes("font-face"),
+					Information: &FontFaceInformation{Declarations: testDeclarations("font-family:A")},
+				},
+			},
+		},
+	}
+}
+
+// testQueryResults writes results as "path|selectors|declaration" lines
+func testQueryResults(results []QueryResult) []string {
+	var ret []string
+	for _, r := range results {
+		buf := &bytes.Buffer{}
+		for _, at := range r.Path {
+			at.Identifier.encode(buf)
+			buf.WriteByte(space)
+		}
+		buf.WriteByte('|')
+		if r.Ruleset != nil {
+			for _, s := range r.Ruleset.Selectors {
+				s.encode(buf)
+				buf.WriteByte(space)
+			}
+		}
+		buf.WriteByte('|')
+		if r.Declaration != nil {
+			r.Declaration.encode(buf)
+		}
+		ret = append(ret, buf.String())
+	}
+	return ret
+}
+
+func TestStatements_FindDeclarations(t *testing.T) {
+	tests := []struct {
+		property string
+		want     []string
+	}{
+		{
+			property: "color",
+			want: []string{
+				"|.card |color:red",
+				"@media screen and (min-width:800px) |.card |color:#333",
+				"@media print @layer base |a .card>h2 |COLOR:black",
+			},
+		},
+		{
+			property: "--color",
+			want:     []string{"|.card |--color:blue"},
+		},
+		{
+			property: "--COLOR",
+		},
+		{
+			property: "font-family",
+			want:     []string{"@font-face {font-family:A} ||font-family:A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			if got := testQueryResults(testQueryStatements().FindDeclarations(tt.property)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Statements.FindDeclarations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatements_RulesMatchingSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			selector: ".card",
+			want: []string{
+				"|.card |",
+				"@media screen and (min-width:800px) |.card |",
+			},
+		},
+		{
+			selector: ".card>h2, nav",
+			want:     []string{"@media print @layer base |a .card>h2 |"},
+		},
+		{
+			selector: "h2",
+		},
+		{
+			selector: "a[",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := testQueryStatements().RulesMatchingSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Statements.RulesMatchingSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if results := testQueryResults(got); !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("Statements.RulesMatchingSelector() = %q, want %q", results, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatements_FindDeclarations_nested(t *testing.T) {
+	s := Statements{
+		testNested(testRule([]string{".a"}),
+			testRule([]string{"&:hover"}, "color:red"),
+			testMedia("min-width", "800px", testRule([]string{"&"}, "color:blue")),
+		),
+		testMedia("min-width", "1px"),
+	}
+	before, _ := Encode(s)
+
+	want := []string{
+		"|&:hover |color:red",
+		"@media (min-width:800px) |& |color:blue",
+	}
+	if got := testQueryResults(s.FindDeclarations("color")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements.FindDeclarations() = %q, want %q", got, want)
+	}
+
+	if after, _ := Encode(s); string(after) != string(before) {
+		t.Errorf("Statements.FindDeclarations() changed statements %s", after)
+	}
+}
+
+func TestStatements_query_concurrent(t *testing.T) {
+	s := testQueryStatements()
+
+	var wg sync.WaitGroup
+	for k := 0; k < 4; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.FindDeclarations("color")
+			s.RulesInMedia("print")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStatements_RulesInMedia(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			query: "print",
+			want:  []string{"@media print @layer base |a .card>h2 |"},
+		},
+		{
+			query: "(min-width: 800px)",
+			want:  []string{"@media screen and (min-width:800px) |.card |"},
+		},
+		{
+			query: "SCREEN and (min-width:800px), print",
+			want: []string{
+				"@media screen and (min-width:800px) |.card |",
+				"@media print @layer base |a .card>h2 |",
+			},
+		},
+		{
+			query: "print and (color)",
+		},
+		{
+			query: "not print",
+		},
+		{
+			query:   "screen and",
+			wantErr: true,
+		},
+		{
+			query:   "(min-width: 800px",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := testQueryStatements().RulesInMedia(tt.query)
+			if (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrInvalidMediaQuery) {
+				t.Errorf("Statements.RulesInMedia() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if results := testQueryResults(got); !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("Statements.RulesInMedia() = %q, want %q", results, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseMediaQueries(t *testing.T) {
+	got, err := parseMediaQueries([]byte("only screen and (min-width: 800px) and (orientation:landscape), not print"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Statements{
+		{
+			AtRule: &AtRule{
+				Identifier: Identifier{Type: TextBytes("media"), Information: &MediaInformation{Queries: got}},
+				Nested:     []*Statement{},
+			},
+		},
+	}
+	b, err := Encode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "@media only screen and (min-width:800px) and (orientation:landscape),not print{}"; string(b) != want {
+		t.Errorf("parseMediaQueries() = %s, want %s", b, want)
+	}
+}
+
+func TestStatements_RulesInMedia_typeOperator(t *testing.T) {
+	only := testMediaType("screen", testRule([]string{"a"}, "color:red"))
+	only.AtRule.Identifier.Information.(*MediaInformation).Queries[0].Type.Operator = TextBytes("only")
+	not := testMediaType("print", testRule([]string{"b"}, "color:red"))
+	not.AtRule.Identifier.Information.(*MediaInformation).Queries[0].Type.Operator = TextBytes("not")
+	s := Statements{only, not}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "screen", want: []string{"@media only screen |a |"}},
+		{query: "only screen", want: []string{"@media only screen |a |"}},
+		{query: "not screen"},
+		{query: "print"},
+		{query: "not print", want: []string{"@media not print |b |"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := s.RulesInMedia(tt.query)
+			if err != nil {
+				t.Fatalf("Statements.RulesInMedia() error = %v", err)
+			}
+			if results := testQueryResults(got); !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("Statements.RulesInMedia() = %q, want %q", results, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,511 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidSelector
+	ErrInvalidSelector = errors.New("invalid selector")
+)
+
+// selectorListPseudos take a selector list as argument
+var selectorListPseudos = keywords(
+	"is", "where", "has", "not", "matches", "-webkit-any", "-moz-any",
+	"host", "host-context", "slotted",
+)
+
+// legacyPseudoElements may be written with a single colon
+var legacyPseudoElements = keywords("before", "after", "first-line", "first-letter")
+
+// Specificity returns the specificity of selector as defined by Selectors
+// Level 4: a counts IDs, b counts classes, attributes and pseudo-classes,
+// c counts types and pseudo-elements. The nesting selector counts nothing
+// as its parent rule is unknown here.
+// https://www.w3.org/TR/selectors-4/#specificity-rules
+func (v Selector) Specificity() (a, b, c int) {
+	ret := v.Simple.specificity()
+	for _, i := range v.Combinates {
+		ret = ret.add(i.Simple.specificity())
+	}
+	return ret[0], ret[1], ret[2]
+}
+
+type specificity [3]int
+
+func (s specificity) add(o specificity) specificity {
+	return specificity{s[0] + o[0], s[1] + o[1], s[2] + o[2]}
+}
+
+func (s specificity) less(o specificity) bool {
+	for k := range s {
+		if s[k] != o[k] {
+			return s[k] < o[k]
+		}
+	}
+	return false
+}
+
+func (v *Simple) specificity() specificity {
+	var ret specificity
+
+	element := v.Element
+	if idx := bytes.IndexByte(element, '|'); idx >= 0 {
+		element = element[idx+1:]
+	}
+	if len(element) > 0 {
+		ret[2]++
+	}
+	ret[0] += len(v.IDs)
+
+	ret[1] += len(v.Classes) + len(v.Attributes)
+
+	// ::slotted() adds its argument
+	// https://www.w3.org/TR/css-scoping-1/#slotted-pseudo
+	for _, p := range v.PseudoElements {
+		ret[2]++
+		if strings.EqualFold(string(p.Ident), "slotted") {
+			ret = ret.add(maxSpecificityOf(p.Selectors))
+		}
+	}
+
+	for _, p := range v.PseudoClasses {
+		ret = ret.add(p.specificity())
+	}
+
+	for _, n := range v.Negations {
+		ret = ret.add(n.specificity())
+	}
+
+	return ret
+}
+
+// specificity of a pseudo-class, :is(), :not() and :has() take the most
+// specific argument, :where() is zero, :host() and :host-context() add
+// their argument
+func (v *Pseudo) specificity() specificity {
+	ident := strings.ToLower(string(v.Ident))
+
+	switch ident {
+	case "where":
+		return specificity{}
+	case "is", "not", "has", "matches", "-webkit-any", "-moz-any":
+		if len(v.Selectors) > 0 {
+			return maxSpecificityOf(v.Selectors)
+		}
+		return maxSpecificity(v.Func)
+	case "host", "host-context":
+		return specificity{0, 1, 0}.add(maxSpecificityOf(v.Selectors))
+	case "nth-child", "nth-last-child":
+		ret := specificity{0, 1, 0}
+		if v.Nth != nil {
+			return ret.add(maxSpecificityOf(v.Selectors))
+		}
+		if idx := bytes.Index(bytes.ToLower(v.Func), []byte(" of ")); idx >= 0 {
+			ret = ret.add(maxSpecificity(v.Func[idx+len(" of "):]))
+		}
+		return ret
+	}
+
+	if len(v.Func) == 0 && legacyPseudoElements[ident] {
+		return specificity{0, 0, 1}
+	}
+
+	return specificity{0, 1, 0}
+}
+
+// maxSpecificity returns the most specific selector of a selector list
+func maxSpecificity(b []byte) specificity {
+	selectors, err := parseSelectors(b)
+	if err != nil {
+		return specificity{}
+	}
+
+	return maxSpecificityOf(selectors)
+}
+
+func maxSpecificityOf(selectors []Selector) specificity {
+	var ret specificity
+
+	for _, s := range selectors {
+		var i specificity
+		i[0], i[1], i[2] = s.Specificity()
+		if ret.less(i) {
+			ret = i
+		}
+	}
+
+	return ret
+}
+
+// parseSelectors parses a comma separated list of selectors
+func parseSelectors(b []byte) ([]Selector, error) {
+	var ret []Selector
+
+	for _, part := range splitTopLevel(b, comma) {
+		p := &selectorParser{b: bytes.TrimSpace(part)}
+		s, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+
+	return ret, nil
+}
+
+type selectorParser struct {
+	b   []byte
+	pos int
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.b)
+}
+
+func (p *selectorParser) skipSpaces() bool {
+	start := p.pos
+	for !p.eof() && isSpace(p.b[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// selector parses compound selectors divided by combinators, a leading
+// combinator makes a relative selector with empty Simple
+func (p *selectorParser) selector() (Selector, error) {
+	var ret Selector
+
+	if p.eof() {
+		return ret, ErrInvalidSelector
+	}
+
+	first := true
+	for !p.eof() {
+		var (
+			combinator Combinator
+			combined   bool
+		)
+		if !first {
+			combined = p.skipSpaces()
+			if p.eof() {
+				break
+			}
+		}
+
+		for k, c := range combinators {
+			if k != int(Descendant) && bytes.HasPrefix(p.b[p.pos:], []byte(c.symbol)) {
+				combinator, combined = Combinator(k), true
+				p.pos += len(c.symbol)
+				p.skipSpaces()
+				break
+			}
+		}
+
+		simple, err := p.simple()
+		if err != nil {
+			return ret, err
+		}
+
+		if combined {
+			ret.Combinates = append(ret.Combinates, Combinate{Combinator: combinator, Simple: simple})
+		} else {
+			ret.Simple = simple
+		}
+		first = false
+	}
+
+	return ret, nil
+}
+
+// simple parses a compound selector, repeated IDs are joined by "#" in ID
+func (p *selectorParser) simple() (Simple, error) {
+	var ret Simple
+
+	start := p.pos
+	if !p.eof() && (p.b[p.pos] == '*' || p.b[p.pos] == '|' || isIdentStart(p.b[p.pos:])) {
+		if p.b[p.pos] == '*' {
+			p.pos++
+			ret.Universal = true
+		} else if p.b[p.pos] != '|' {
+			p.ident()
+		}
+		if !p.eof() && p.b[p.pos] == '|' && !bytes.HasPrefix(p.b[p.pos:], []byte("||")) {
+			p.pos++
+			ret.Universal = !p.eof() && p.b[p.pos] == '*'
+			if ret.Universal {
+				p.pos++
+			} else if p.ident() == nil {
+				return ret, ErrInvalidSelector
+			}
+		}
+		end := p.pos
+		if ret.Universal {
+			end--
+		}
+		if end > start {
+			ret.Element = unescape(append(TextBytes(nil), p.b[start:end]...))
+		}
+	}
+
+	for !p.eof() {
+		switch p.b[p.pos] {
+		case ampersand:
+			p.pos++
+			ret.Nesting = true
+		case numberSign:
+			idStart := p.pos + 1
+			p.pos = skipName(p.b, idStart)
+			if p.pos == idStart {
+				return ret, ErrInvalidSelector
+			}
+			ret.IDs = append(ret.IDs, unescape(p.b[idStart:p.pos]))
+		case period:
+			p.pos++
+			class := p.ident()
+			if class == nil {
+				return ret, ErrInvalidSelector
+			}
+			ret.Classes = append(ret.Classes, class)
+		case leftSquareBracket:
+			a, err := p.attribute()
+			if err != nil {
+				return ret, err
+			}
+			ret.Attributes = append(ret.Attributes, a)
+		case colon:
+			if err := p.pseudo(&ret); err != nil {
+				return ret, err
+			}
+		default:
+			if p.pos == start {
+				return ret, ErrInvalidSelector
+			}
+			return ret, nil
+		}
+	}
+
+	if p.pos == start {
+		return ret, ErrInvalidSelector
+	}
+
+	return ret, nil
+}
+
+func (p *selectorParser) ident() TextBytes {
+	start := p.pos
+	p.pos = skipIdent(p.b, p.pos)
+	if p.pos == start {
+		return nil
+	}
+	return unescape(append(TextBytes(nil), p.b[start:p.pos]...))
+}
+
+func (p *selectorParser) attribute() (Attribute, error) {
+	var ret Attribute
+
+	end := closingBracket(p.b, p.pos, leftSquareBracket, rightSquareBracket)
+	if end >= len(p.b) {
+		return ret, ErrInvalidSelector
+	}
+	inner := &selectorParser{b: p.b[p.pos+1 : end]}
+	p.pos = end + 1
+
+	inner.skipSpaces()
+	start := inner.pos
+	if !inner.eof() && inner.b[inner.pos] == '*' {
+		inner.pos++
+	} else {
+		inner.ident()
+	}
+	if !inner.eof() && inner.b[inner.pos] == '|' && (inner.pos+1 >= len(inner.b) || inner.b[inner.pos+1] != '=') {
+		inner.pos++
+		if inner.ident() == nil {
+			return ret, ErrInvalidSelector
+		}
+	}
+	if inner.pos == start {
+		return ret, ErrInvalidSelector
+	}
+	ret.Attr = unescape(append(TextBytes(nil), inner.b[start:inner.pos]...))
+	inner.skipSpaces()
+
+	if inner.eof() {
+		return ret, nil
+	}
+
+	switch {
+	case inner.b[inner.pos] == '=':
+		ret.Operator = TextBytes{'='}
+		inner.pos++
+	case inner.pos+1 < len(inner.b) && inner.b[inner.pos+1] == '=' && bytes.IndexByte([]byte("~|^$*"), inner.b[inner.pos]) >= 0:
+		ret.Operator = append(TextBytes(nil), inner.b[inner.pos:inner.pos+2]...)
+		inner.pos += 2
+	default:
+		return ret, ErrInvalidSelector
+	}
+	inner.skipSpaces()
+
+	if !inner.eof() && (inner.b[inner.pos] == doubleQuote || inner.b[inner.pos] == '\'') {
+		end := closingQuote(inner.b, inner.pos)
+		if end >= len(inner.b) {
+			return ret, ErrInvalidSelector
+		}
+		ret.Value = unescape(append(TextBytes(nil), inner.b[inner.pos+1:end]...))
+		inner.pos = end + 1
+	} else if end := skipName(inner.b, inner.pos); end > inner.pos {
+		ret.Value = unescape(append(TextBytes(nil), inner.b[inner.pos:end]...))
+		inner.pos = end
+	} else {
+		return ret, ErrInvalidSelector
+	}
+
+	inner.skipSpaces()
+	if !inner.eof() {
+		ret.Modifier = inner.ident()
+		inner.skipSpaces()
+	}
+	if !inner.eof() {
+		return ret, ErrInvalidSelector
+	}
+
+	return ret, ret.Validate()
+}
+
+// pseudo parses pseudo-classes and pseudo-elements of a compound selector,
+// selector list arguments are parsed in Selectors
+func (p *selectorParser) pseudo(dst *Simple) error {
+	p.pos++
+	element := !p.eof() && p.b[p.pos] == colon
+	if element {
+		p.pos++
+	}
+
+	var ret Pseudo
+	if ret.Ident = p.ident(); ret.Ident == nil {
+		return ErrInvalidSelector
+	}
+
+	if !p.eof() && p.b[p.pos] == leftParenthesis {
+		end := closingBracket(p.b, p.pos, leftParenthesis, rightParenthesis)
+		if end >= len(p.b) {
+			return ErrInvalidSelector
+		}
+		ret.Func = append(TextBytes(nil), bytes.TrimSpace(p.b[p.pos+1:end])...)
+		p.pos = end + 1
+	}
+
+	ident := strings.ToLower(string(ret.Ident))
+	if selectorListPseudos[ident] && len(ret.Func) > 0 {
+		if selectors, err := parseSelectors(ret.Func); err == nil {
+			ret.Func, ret.Selectors = nil, selectors
+		}
+	}
+
+	if nthPseudos[ident] && !element {
+		nth, selectors, err := parseNthArgument(ident, ret.Func)
+		if err != nil {
+			return ErrInvalidSelector
+		}
+		ret.Func, ret.Nth, ret.Selectors = nil, nth, selectors
+	}
+
+	if element {
+		dst.PseudoElements = append(dst.PseudoElements, ret)
+		return nil
+	}
+
+	dst.PseudoClasses = append(dst.PseudoClasses, ret)
+
+	return nil
+}
+
+// isIdentStart reports whether b starts with an identifier
+func isIdentStart(b []byte) bool {
+	if len(b) > 0 && b[0] == '-' {
+		b = b[1:]
+		if len(b) > 0 && b[0] == '-' {
+			return true
+		}
+	}
+	return len(b) > 0 && (isLetter(b[0]) || b[0] == '_' || b[0] >= 0x80 || b[0] == '\\')
+}
+
+// skipIdent returns the end of an identifier started at k
+func skipIdent(b []byte, k int) int {
+	if !isIdentStart(b[k:]) {
+		return k
+	}
+	return skipName(b, k)
+}
+
+// skipName returns the end of a name started at k, the name of an ID may
+// start with a digit
+func skipName(b []byte, k int) int {
+	for k < len(b) {
+		switch c := b[k]; {
+		case c == '\\' && k+1 < len(b) && b[k+1] != '\n':
+			k = skipEscape(b, k)
+		case isIdentByte(c) || c >= 0x80:
+			k++
+		default:
+			return k
+		}
+	}
+	return k
+}
+
+// skipEscape returns the end of an escape started at backslash k, a hex
+// escape may be followed by a whitespace
+func skipEscape(b []byte, k int) int {
+	k++
+	start := k
+	for k < len(b) && k-start < 6 && isHexDigit(b[k]) {
+		k++
+	}
+	if k == start {
+		return k + 1
+	}
+	if k < len(b) && isSpace(b[k]) {
+		k++
+	}
+	return k
+}
+
+// closingBracket returns the position of bracket closing the one at open,
+// or len(b)
+func closingBracket(b []byte, open int, left, right byte) int {
+	depth := 0
+	for k := open; k < len(b); k++ {
+		switch b[k] {
+		case '\\':
+			k++
+		case doubleQuote, '\'':
+			k = closingQuote(b, k)
+		case left:
+			depth++
+		case right:
+			depth--
+			if depth == 0 {
+				return k
+			}
+		}
+	}
+	return len(b)
+}
+
+// closingQuote returns the position of quote closing the one at open, or
+// len(b)
+func closingQuote(b []byte, open int) int {
+	for k := open + 1; k < len(b); k++ {
+		switch b[k] {
+		case '\\':
+			k++
+		case b[open]:
+			return k
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,169 @@
+package css2json
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSelector_Specificity(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     [3]int
+	}{
+		{selector: "*", want: [3]int{0, 0, 0}},
+		{selector: "li", want: [3]int{0, 0, 1}},
+		{selector: "ul li", want: [3]int{0, 0, 2}},
+		{selector: "ul ol+li", want: [3]int{0, 0, 3}},
+		{selector: "h1 + *[rel=up]", want: [3]int{0, 1, 1}},
+		{selector: "ul ol li.red", want: [3]int{0, 1, 3}},
+		{selector: "li.red.level", want: [3]int{0, 2, 1}},
+		{selector: "#x34y", want: [3]int{1, 0, 0}},
+		{selector: "div#main#main", want: [3]int{2, 0, 1}},
+		{selector: `#a\#b`, want: [3]int{1, 0, 0}},
+		{selector: "svg|circle", want: [3]int{0, 0, 1}},
+		{selector: "*|*", want: [3]int{0, 0, 0}},
+		{selector: "a:hover::before", want: [3]int{0, 1, 2}},
+		{selector: "a:after", want: [3]int{0, 0, 2}},
+		{selector: "#s12:not(FOO)", want: [3]int{1, 0, 1}},
+		{selector: ".foo :is(.bar, #baz)", want: [3]int{1, 1, 0}},
+		{selector: ":where(#a, .b) p", want: [3]int{0, 0, 1}},
+		{selector: "a:not(.b, #c span)", want: [3]int{1, 0, 2}},
+		{selector: "div:has(> img.icon)", want: [3]int{0, 1, 2}},
+		{selector: "li:nth-child(2n+1 of .item, #x)", want: [3]int{1, 1, 1}},
+		{selector: "li:nth-of-type(2n)", want: [3]int{0, 1, 1}},
+		{selector: "input[type=\"text\" i]:focus", want: [3]int{0, 2, 1}},
+		{selector: "::slotted(.x)", want: [3]int{0, 1, 1}},
+		{selector: ":host", want: [3]int{0, 1, 0}},
+		{selector: ":host(.x)", want: [3]int{0, 2, 0}},
+		{selector: ":host-context(main.x) a", want: [3]int{0, 2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			s, err := parseSelectors([]byte(tt.selector))
+			if err != nil || len(s) != 1 {
+				t.Fatalf("parseSelectors() = %v, %v", s, err)
+			}
+			a, b, c := s[0].Specificity()
+			if got := [3]int{a, b, c}; got != tt.want {
+				t.Errorf("Selector.Specificity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSelectors(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "a, b", want: "a,b"},
+		{in: "#sidebar ul>li  a", want: "#sidebar ul>li a"},
+		{in: "a.b.c[href^='http' i]::before", want: `a.b.c[href^="http" i]::before`},
+		{in: "html|*:not(:link):not(:visited)", want: "html|*:not(:link):not(:visited)"},
+		{in: "tr:nth-child( 2n+1 )", want: "tr:nth-child(odd)"},
+		{in: "td || col", want: "td||col"},
+		{in: "a[data-x=1]", want: `a[data-x="1"]`},
+		{in: `a[alt=""]`, want: `a[alt=""]`},
+		{in: `a[alt="x"I]`, want: `a[alt="x" I]`},
+		{in: "a[alt=]", wantErr: true},
+		{in: "a[alt i]", wantErr: true},
+		{in: "a[alt=x y]", wantErr: true},
+		{in: "a,", wantErr: true},
+		{in: "a..b", wantErr: true},
+		{in: "a[href", wantErr: true},
+		{in: "a:", wantErr: true},
+		{in: "a >", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSelectors([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSelectors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			r := &Ruleset{Selectors: got, Declarations: testDeclarations("color:red")}
+			if s := string(encodeBytes(r)); s != tt.want+"{color:red}" {
+				t.Errorf("parseSelectors() = %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSelectors_simple(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Simple
+	}{
+		{in: "#sidebar", want: Simple{IDs: []TextBytes{TextBytes("sidebar")}}},
+		{in: `#a\#b`, want: Simple{IDs: []TextBytes{TextBytes("a#b")}}},
+		{in: "div#a#b", want: Simple{Element: TextBytes("div"), IDs: []TextBytes{TextBytes("a"), TextBytes("b")}}},
+		{in: "*", want: Simple{Universal: true}},
+		{in: "*|*", want: Simple{Element: TextBytes("*|"), Universal: true}},
+		{in: "svg|rect", want: Simple{Element: TextBytes("svg|rect")}},
+		{in: `a\#b\&`, want: Simple{Element: TextBytes("a#b&")}},
+		{in: "&.active", want: Simple{Nesting: true, Classes: []TextBytes{TextBytes("active")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSelectors([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("parseSelectors() error = %v", err)
+			}
+			if !reflect.DeepEqual(got[0].Simple, tt.want) {
+				t.Errorf("parseSelectors() = %+v, want %+v", got[0].Simple, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSelectors_selectorList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		js   string
+	}{
+		{
+			in:   "a:has(> img, + p)",
+			want: "a:has(>img,+p)",
+			js:   `{"simple":{"element":"a","pseudo_classes":[{"ident":"has","selectors":[{"simple":{},"combinate":[{"combinator":">","simple":{"element":"img"}}]},{"simple":{},"combinate":[{"combinator":"+","simple":{"element":"p"}}]}]}]}}`,
+		},
+		{
+			in:   ":is(.a .b, #c):not(.d > .e, [f])",
+			want: ":is(.a .b,#c):not(.d>.e,[f])",
+			js:   `{"simple":{"pseudo_classes":[{"ident":"is","selectors":[{"simple":{"classes":["a"]},"combinate":[{"combinator":" ","simple":{"classes":["b"]}}]},{"simple":{"ids":["c"]}}]},{"ident":"not","selectors":[{"simple":{"classes":["d"]},"combinate":[{"combinator":">","simple":{"classes":["e"]}}]},{"simple":{"attributes":[{"attr":"f"}]}}]}]}}`,
+		},
+		{
+			in:   "li:where(:not(.a))",
+			want: "li:where(:not(.a))",
+		},
+		{
+			in:   ":is(!)",
+			want: ":is(!)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSelectors([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("parseSelectors() error = %v", err)
+			}
+			if s := string(encodeBytes(&got[0])); s != tt.want {
+				t.Errorf("parseSelectors() = %v, want %v", s, tt.want)
+			}
+			if tt.js == "" {
+				return
+			}
+			var decoded Selector
+			if err := json.Unmarshal([]byte(tt.js), &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if s := string(encodeBytes(&decoded)); s != tt.want {
+				t.Errorf("json.Unmarshal() = %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
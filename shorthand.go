@@ -0,0 +1,1787 @@
+package css2json
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+var important = TextBytes("!important")
+
+// cssWideKeywords https://developer.mozilla.org/en-US/docs/Web/CSS/CSS_Values_and_Units#css-wide_values
+var cssWideKeywords = map[string]bool{
+	"inherit":      true,
+	"initial":      true,
+	"unset":        true,
+	"revert":       true,
+	"revert-layer": true,
+}
+
+type shorthand struct {
+	longhands []string
+	// resets are other longhands set to initial values by the shorthand
+	resets   []string
+	expand   func([]Value) ([][]Value, bool)
+	collapse func([][]Value) ([]Value, bool)
+}
+
+// shorthandNames is the order of collapsing, shorthands of shorthands like
+// font-variant of font go first
+var shorthandNames = []string{
+	"font-variant",
+	"margin",
+	"padding",
+	"inset",
+	"margin-inline",
+	"margin-block",
+	"padding-inline",
+	"padding-block",
+	"inset-inline",
+	"inset-block",
+	"border",
+	"border-width",
+	"border-style",
+	"border-color",
+	"border-top",
+	"border-right",
+	"border-bottom",
+	"border-left",
+	"border-inline",
+	"border-block",
+	"border-inline-start",
+	"border-inline-end",
+	"border-block-start",
+	"border-block-end",
+	"border-inline-width",
+	"border-inline-style",
+	"border-inline-color",
+	"border-block-width",
+	"border-block-style",
+	"border-block-color",
+	"border-radius",
+	"border-image",
+	"font",
+	"background",
+	"flex",
+	"flex-flow",
+	"grid-area",
+	"grid-row",
+	"grid-column",
+	"overflow",
+	"gap",
+	"place-content",
+	"place-items",
+	"place-self",
+	"list-style",
+	"text-decoration",
+	"columns",
+	"transition",
+}
+
+var shorthands = map[string]*shorthand{
+	"margin": {
+		longhands: []string{"margin-top", "margin-right", "margin-bottom", "margin-left"},
+		expand:    expandBox,
+		collapse:  collapseBox,
+	},
+	"padding": {
+		longhands: []string{"padding-top", "padding-right", "padding-bottom", "padding-left"},
+		expand:    expandBox,
+		collapse:  collapseBox,
+	},
+	"inset": {
+		longhands: []string{"top", "right", "bottom", "left"},
+		expand:    expandBox,
+		collapse:  collapseBox,
+	},
+	"border": {
+		longhands: []string{
+			"border-top-width", "border-right-width", "border-bottom-width", "border-left-width",
+			"border-top-style", "border-right-style", "border-bottom-style", "border-left-style",
+			"border-top-color", "border-right-color", "border-bottom-color", "border-left-color",
+		},
+		resets: []string{
+			"border-image-source", "border-image-slice", "border-image-width",
+			"border-image-outset", "border-image-repeat",
+		},
+		expand:   expandBorder,
+		collapse: collapseBorder,
+	},
+	"font": {
+		longhands: []string{
+			"font-style", "font-variant", "font-weight", "font-stretch",
+			"font-size", "line-height", "font-family",
+		},
+		resets: []string{
+			"font-kerning", "font-size-adjust", "font-language-override", "font-optical-sizing",
+			"font-palette", "font-variation-settings", "font-variant-caps", "font-variant-ligatures",
+			"font-variant-numeric", "font-variant-east-asian", "font-variant-alternates",
+			"font-variant-position", "font-variant-emoji",
+		},
+		expand:   expandFont,
+		collapse: collapseFont,
+	},
+	"background": {
+		longhands: []string{
+			"background-image", "background-position", "background-size", "background-repeat",
+			"background-attachment", "background-origin", "background-clip", "background-color",
+		},
+		expand:   expandBackground,
+		collapse: collapseBackground,
+	},
+	"flex": {
+		longhands: []string{"flex-grow", "flex-shrink", "flex-basis"},
+		expand:    expandFlex,
+		collapse:  collapseFlex,
+	},
+	"grid-area": {
+		longhands: []string{"grid-row-start", "grid-column-start", "grid-row-end", "grid-column-end"},
+		expand:    expandGridArea,
+		collapse:  collapseGridArea,
+	},
+	"transition": {
+		longhands: []string{
+			"transition-property", "transition-duration",
+			"transition-timing-function", "transition-delay",
+		},
+		expand:   expandTransition,
+		collapse: collapseTransition,
+	},
+	"margin-inline":       pairShorthand("margin-inline-start", "margin-inline-end"),
+	"margin-block":        pairShorthand("margin-block-start", "margin-block-end"),
+	"padding-inline":      pairShorthand("padding-inline-start", "padding-inline-end"),
+	"padding-block":       pairShorthand("padding-block-start", "padding-block-end"),
+	"inset-inline":        pairShorthand("inset-inline-start", "inset-inline-end"),
+	"inset-block":         pairShorthand("inset-block-start", "inset-block-end"),
+	"border-width":        boxShorthand("border-", "-width"),
+	"border-style":        boxShorthand("border-", "-style"),
+	"border-color":        boxShorthand("border-", "-color"),
+	"border-top":          sideShorthand("border-top"),
+	"border-right":        sideShorthand("border-right"),
+	"border-bottom":       sideShorthand("border-bottom"),
+	"border-left":         sideShorthand("border-left"),
+	"border-inline-start": sideShorthand("border-inline-start"),
+	"border-inline-end":   sideShorthand("border-inline-end"),
+	"border-block-start":  sideShorthand("border-block-start"),
+	"border-block-end":    sideShorthand("border-block-end"),
+	"border-inline-width": pairShorthand("border-inline-start-width", "border-inline-end-width"),
+	"border-inline-style": pairShorthand("border-inline-start-style", "border-inline-end-style"),
+	"border-inline-color": pairShorthand("border-inline-start-color", "border-inline-end-color"),
+	"border-block-width":  pairShorthand("border-block-start-width", "border-block-end-width"),
+	"border-block-style":  pairShorthand("border-block-start-style", "border-block-end-style"),
+	"border-block-color":  pairShorthand("border-block-start-color", "border-block-end-color"),
+	"border-inline": {
+		longhands: append(sideShorthand("border-inline-start").longhands, sideShorthand("border-inline-end").longhands...),
+		expand:    expandBorderSides,
+		collapse:  collapseBorderSides,
+	},
+	"border-block": {
+		longhands: append(sideShorthand("border-block-start").longhands, sideShorthand("border-block-end").longhands...),
+		expand:    expandBorderSides,
+		collapse:  collapseBorderSides,
+	},
+	"border-radius": {
+		longhands: []string{
+			"border-top-left-radius", "border-top-right-radius",
+			"border-bottom-right-radius", "border-bottom-left-radius",
+		},
+		expand:   expandRadius,
+		collapse: collapseRadius,
+	},
+	"border-image": {
+		longhands: []string{
+			"border-image-source", "border-image-slice", "border-image-width",
+			"border-image-outset", "border-image-repeat",
+		},
+		expand:   expandBorderImage,
+		collapse: collapseBorderImage,
+	},
+	"font-variant": {
+		longhands: []string{
+			"font-variant-ligatures", "font-variant-caps", "font-variant-alternates", "font-variant-numeric",
+			"font-variant-east-asian", "font-variant-position", "font-variant-emoji",
+		},
+		expand:   expandFontVariant,
+		collapse: collapseFontVariant,
+	},
+	"flex-flow":     keywordShorthand([]string{"flex-direction", "flex-wrap"}, flexFlowKeywords),
+	"grid-row":      lineShorthand("grid-row-start", "grid-row-end"),
+	"grid-column":   lineShorthand("grid-column-start", "grid-column-end"),
+	"overflow":      pairShorthand("overflow-x", "overflow-y"),
+	"gap":           pairShorthand("row-gap", "column-gap"),
+	"place-content": pairShorthand("align-content", "justify-content"),
+	"place-items":   pairShorthand("align-items", "justify-items"),
+	"place-self":    pairShorthand("align-self", "justify-self"),
+	"list-style": {
+		longhands: []string{"list-style-position", "list-style-image", "list-style-type"},
+		expand:    expandListStyle,
+		collapse:  collapseListStyle,
+	},
+	"text-decoration": keywordShorthand(
+		[]string{"text-decoration-line", "text-decoration-style", "text-decoration-color", "text-decoration-thickness"},
+		textDecorationKeywords,
+	),
+	"columns": keywordShorthand([]string{"column-width", "column-count"}, columnsKeywords),
+}
+
+// ExpandShorthands replaces shorthand declarations like margin, border,
+// border-width, margin-inline, font or background by their longhands,
+// shorthands set by other ones like font-variant of font are expanded too.
+// Properties reset by border and font, like border-image-source or
+// font-kerning, are set to initial before the longhands. Values which can
+// not be expanded, like ones with var(), are kept as is.
+func (v *Ruleset) ExpandShorthands() {
+	var ret []Declaration
+	for _, d := range v.Declarations {
+		ret = append(ret, expandShorthand(d)...)
+	}
+	v.Declarations = ret
+}
+
+// CollapseShorthands replaces a complete set of longhands by the shortest
+// shorthand, it is the reverse of ExpandShorthands. Longhands are kept when
+// the shorthand would reset a property declared before them, like
+// border-image by border, or when a related property like margin-inline is
+// declared between them.
+func (v *Ruleset) CollapseShorthands() {
+	for _, name := range shorthandNames {
+		v.Declarations = collapseShorthand(v.Declarations, name, shorthands[name])
+	}
+}
+
+func expandShorthand(d Declaration) []Declaration {
+	sh, ok := shorthands[strings.ToLower(string(d.Property))]
+	if !ok {
+		return []Declaration{d}
+	}
+
+	raw := valuesBytes(d.Values)
+	if bytes.Contains(raw, []byte("var(")) {
+		return []Declaration{d}
+	}
+
+	values, imp := splitImportant(parseValues(raw))
+
+	var (
+		longhands [][]Value
+		reset     = tokenValues("initial")
+	)
+	if keyword, ok := cssWideKeyword(values); ok {
+		for range sh.longhands {
+			longhands = append(longhands, keyword)
+		}
+		reset = keyword
+	} else if longhands, ok = sh.expand(values); !ok {
+		return []Declaration{d}
+	}
+
+	var ret []Declaration
+	for k, name := range sh.longhands {
+		ret = append(ret, expandShorthand(Declaration{
+			Property: TextBytes(name),
+			Values:   withImportant(longhands[k], imp),
+		})...)
+	}
+
+	// resets go first, ones set by the longhands like font-variant-caps by
+	// font-variant are skipped
+	var resets []Declaration
+	for _, name := range sh.resets {
+		if !declaresProperty(ret, name) {
+			resets = append(resets, Declaration{
+				Property: TextBytes(name),
+				Values:   withImportant(reset, imp),
+			})
+		}
+	}
+
+	return append(resets, ret...)
+}
+
+func declaresProperty(decls []Declaration, name string) bool {
+	for _, d := range decls {
+		if string(d.Property) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resetOf reports whether the property is reset by the shorthand, or is a
+// shorthand of such properties like border-image
+func (sh *shorthand) resetOf(p string) bool {
+	for _, r := range sh.resets {
+		if p == r || strings.HasPrefix(r, p+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func (sh *shorthand) longhandOf(p string) bool {
+	for _, l := range sh.longhands {
+		if p == l {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether the property may set a longhand of the
+// shorthand, like margin-inline-start sets margin-left or margin-right
+func (sh *shorthand) overlaps(p string) bool {
+	for _, i := range longhandNames(p) {
+		for _, l := range sh.longhands {
+			if i == l || containsString(physicalProperties(i), l) || containsString(physicalProperties(l), i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// longhandNames returns longhands set by the property
+func longhandNames(name string) []string {
+	sh, ok := shorthands[name]
+	if !ok {
+		return []string{name}
+	}
+
+	ret := append([]string{}, sh.resets...)
+	for _, l := range sh.longhands {
+		ret = append(ret, longhandNames(l)...)
+	}
+	return ret
+}
+
+func containsString(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseShorthand(decls []Declaration, name string, sh *shorthand) []Declaration {
+	var (
+		first   = -1
+		last    = map[string]int{}
+		resets  []int
+		related []int
+	)
+
+	for k, d := range decls {
+		p := strings.ToLower(string(d.Property))
+		switch {
+		case p == name && first >= 0:
+			return decls
+		case sh.longhandOf(p):
+			last[p] = k
+			if first < 0 {
+				first = k
+			}
+		case sh.resetOf(p) && first < 0:
+			resets = append(resets, k)
+		case first >= 0 && !sh.resetOf(p) && sh.overlaps(p):
+			related = append(related, k)
+		}
+	}
+
+	if len(last) != len(sh.longhands) {
+		return decls
+	}
+
+	// the shorthand is written in place of the first longhand, a related
+	// property like margin-inline before the last one would be moved after it
+	for _, k := range related {
+		for _, l := range sh.longhands {
+			if k < last[l] {
+				return decls
+			}
+		}
+	}
+
+	var (
+		values   = make([][]Value, len(sh.longhands))
+		imp      bool
+		keywords int
+	)
+	for k, l := range sh.longhands {
+		raw := valuesBytes(decls[last[l]].Values)
+		if bytes.Contains(raw, []byte("var(")) {
+			return decls
+		}
+
+		var i bool
+		values[k], i = splitImportant(parseValues(raw))
+		if k > 0 && i != imp {
+			return decls
+		}
+		imp = i
+
+		if _, ok := cssWideKeyword(values[k]); ok {
+			if keywords++; !bytes.Equal(valuesBytes(values[k]), valuesBytes(values[0])) {
+				return decls
+			}
+		}
+	}
+
+	var (
+		collapsed []Value
+		reset     = tokenValues("initial")
+	)
+	switch keywords {
+	case 0:
+		var ok bool
+		if collapsed, ok = sh.collapse(values); !ok {
+			return decls
+		}
+	case len(sh.longhands):
+		collapsed, reset = values[0], values[0]
+	default:
+		return decls
+	}
+
+	// reset properties declared before are dropped when the shorthand sets
+	// them to the same value, like ones written by ExpandShorthands
+	dropped := map[int]bool{}
+	for _, k := range resets {
+		values, i := splitImportant(parseValues(valuesBytes(decls[k].Values)))
+		if i != imp || !bytes.Equal(valuesBytes(values), valuesBytes(reset)) {
+			return decls
+		}
+		dropped[k] = true
+	}
+
+	var ret []Declaration
+	for k, d := range decls {
+		if k == first {
+			ret = append(ret, Declaration{
+				Property: TextBytes(name),
+				Values:   withImportant(collapsed, imp),
+			})
+		}
+		if _, ok := last[strings.ToLower(string(d.Property))]; !ok && !dropped[k] {
+			ret = append(ret, d)
+		}
+	}
+
+	return ret
+}
+
+// splitImportant removes !important from values
+func splitImportant(values []Value) ([]Value, bool) {
+	if len(values) == 0 {
+		return values, false
+	}
+
+	lastValue := values[len(values)-1]
+	tokens := lastValue.ValueSpace
+	components := lastValue.components()
+	if len(components) == 0 {
+		return values, false
+	}
+	if _, ok := components[len(components)-1].(rawToken); !ok {
+		return values, false
+	}
+
+	lastToken := tokens[len(tokens)-1]
+	if len(lastToken) < len(important) || !bytes.EqualFold(lastToken[len(lastToken)-len(important):], important) {
+		return values, false
+	}
+
+	tokens = append([]TextBytes{}, tokens[:len(tokens)-1]...)
+	if rest := bytes.TrimSpace(lastToken[:len(lastToken)-len(important)]); len(rest) > 0 {
+		tokens = append(tokens, rest)
+	}
+
+	ret := append([]Value{}, values[:len(values)-1]...)
+	if len(tokens) > 0 || lastValue.hasTyped() {
+		ret = append(ret, Value{ValueSpace: tokens, Tokens: lastValue.positionedTokens()})
+	}
+
+	return ret, true
+}
+
+// withImportant appends !important to a copy of values
+func withImportant(values []Value, imp bool) []Value {
+	if !imp {
+		return values
+	}
+
+	ret := append([]Value{}, values...)
+	if len(ret) == 0 {
+		return []Value{{ValueSpace: []TextBytes{important}}}
+	}
+
+	last := &ret[len(ret)-1]
+	last.Tokens = last.positionedTokens()
+	last.ValueSpace = append(append([]TextBytes{}, last.ValueSpace...), important)
+
+	return ret
+}
+
+func cssWideKeyword(values []Value) ([]Value, bool) {
+	if len(values) != 1 || len(values[0].ValueSpace) != 1 {
+		return nil, false
+	}
+	return values, cssWideKeywords[strings.ToLower(string(values[0].ValueSpace[0]))]
+}
+
+func tokenValues(tokens ...string) []Value {
+	ret := Value{ValueSpace: make([]TextBytes, len(tokens))}
+	for k, i := range tokens {
+		ret.ValueSpace[k] = TextBytes(i)
+	}
+	return []Value{ret}
+}
+
+// singleTokens returns the only token of every value list
+func singleTokens(values [][]Value) ([]string, bool) {
+	ret := make([]string, len(values))
+	for k, i := range values {
+		if len(i) != 1 || len(i[0].ValueSpace) != 1 {
+			return nil, false
+		}
+		ret[k] = string(i[0].ValueSpace[0])
+	}
+	return ret, true
+}
+
+// layerTokens returns tokens of the idx comma separated layer
+func layerTokens(values []Value, idx int) []string {
+	var ret []string
+	for _, i := range values[idx].ValueSpace {
+		ret = append(ret, string(i))
+	}
+	return ret
+}
+
+// splitSlash separates "/" in tokens outside of functions
+func splitSlash(tokens []TextBytes) []string {
+	var ret []string
+	for _, t := range tokens {
+		s := string(t)
+		if strings.ContainsRune(s, leftParenthesis) || !strings.ContainsRune(s, '/') {
+			ret = append(ret, s)
+			continue
+		}
+		for k, part := range strings.Split(s, "/") {
+			if k > 0 {
+				ret = append(ret, "/")
+			}
+			if part != "" {
+				ret = append(ret, part)
+			}
+		}
+	}
+	return ret
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// isLength reports whether s is a dimension, percentage or math function
+func isLength(s string) bool {
+	if IsMathFunction([]byte(s)) {
+		return true
+	}
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	if (c == '+' || c == '-') && len(s) > 1 {
+		c = s[1]
+	}
+	return isDigit(c) || c == period
+}
+
+func isTime(s string) bool {
+	s = strings.ToLower(s)
+	return (strings.HasSuffix(s, "ms") && isNumber(s[:len(s)-2])) ||
+		(strings.HasSuffix(s, "s") && isNumber(s[:len(s)-1])) ||
+		IsMathFunction([]byte(s))
+}
+
+func isZero(s string) bool {
+	n := strings.TrimRight(strings.ToLower(s), "abcdefghijklmnopqrstuvwxyz%")
+	f, err := strconv.ParseFloat(n, 64)
+	return err == nil && f == 0
+}
+
+func keywords(words ...string) map[string]bool {
+	ret := map[string]bool{}
+	for _, i := range words {
+		ret[i] = true
+	}
+	return ret
+}
+
+func expandBox(v []Value) ([][]Value, bool) {
+	if len(v) != 1 || len(v[0].ValueSpace) == 0 || len(v[0].ValueSpace) > 4 {
+		return nil, false
+	}
+
+	tokens := layerTokens(v, 0)
+	sides := [][4]int{{0, 0, 0, 0}, {0, 1, 0, 1}, {0, 1, 2, 1}, {0, 1, 2, 3}}[len(tokens)-1]
+
+	ret := make([][]Value, 4)
+	for k, i := range sides {
+		ret[k] = tokenValues(tokens[i])
+	}
+
+	return ret, true
+}
+
+func collapseBox(v [][]Value) ([]Value, bool) {
+	sides, ok := singleTokens(v)
+	if !ok {
+		return nil, false
+	}
+
+	n := 4
+	if sides[3] == sides[1] {
+		n = 3
+		if sides[2] == sides[0] {
+			n = 2
+			if sides[1] == sides[0] {
+				n = 1
+			}
+		}
+	}
+
+	return tokenValues(sides[:n]...), true
+}
+
+var (
+	borderStyles = keywords("none", "hidden", "dotted", "dashed", "solid", "double", "groove", "ridge", "inset", "outset")
+	borderWidths = keywords("thin", "medium", "thick")
+)
+
+func expandBorder(v []Value) ([][]Value, bool) {
+	if len(v) != 1 || len(v[0].ValueSpace) > 3 {
+		return nil, false
+	}
+
+	width, style, color := "", "", ""
+	for _, t := range layerTokens(v, 0) {
+		l := strings.ToLower(t)
+		switch {
+		case borderStyles[l] && style == "":
+			style = t
+		case (borderWidths[l] || isLength(l)) && width == "":
+			width = t
+		case !borderStyles[l] && !borderWidths[l] && !isLength(l) && color == "":
+			color = t
+		default:
+			return nil, false
+		}
+	}
+
+	ret := make([][]Value, 0, 12)
+	for k, i := range []string{width, style, color} {
+		if i == "" {
+			i = []string{"medium", "none", "currentcolor"}[k]
+		}
+		for n := 0; n < 4; n++ {
+			ret = append(ret, tokenValues(i))
+		}
+	}
+
+	return ret, true
+}
+
+func collapseBorder(v [][]Value) ([]Value, bool) {
+	tokens, ok := singleTokens(v)
+	if !ok {
+		return nil, false
+	}
+
+	var ret []string
+	for k, initial := range []string{"medium", "none", "currentcolor"} {
+		side := tokens[k*4 : k*4+4]
+		for _, i := range side[1:] {
+			if i != side[0] {
+				return nil, false
+			}
+		}
+		if !strings.EqualFold(side[0], initial) {
+			ret = append(ret, side[0])
+		}
+	}
+
+	if len(ret) == 0 {
+		ret = []string{"none"}
+	}
+
+	return tokenValues(ret...), true
+}
+
+var (
+	fontStyles    = keywords("italic", "oblique")
+	fontVariants  = keywords("small-caps")
+	fontWeights   = keywords("bold", "bolder", "lighter")
+	fontStretches = keywords("ultra-condensed", "extra-condensed", "condensed", "semi-condensed",
+		"semi-expanded", "expanded", "extra-expanded", "ultra-expanded")
+	fontSizes = keywords("xx-small", "x-small", "small", "medium", "large", "x-large",
+		"xx-large", "xxx-large", "larger", "smaller")
+)
+
+func isFontWeight(s string) bool {
+	if fontWeights[s] {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 1 && n <= 1000
+}
+
+func expandFont(v []Value) ([][]Value, bool) {
+	if len(v) == 0 {
+		return nil, false
+	}
+
+	var (
+		tokens = splitSlash(v[0].ValueSpace)
+		prefix = []string{"normal", "normal", "normal", "normal"}
+		i      int
+	)
+
+loop:
+	for ; i < len(tokens); i++ {
+		l := strings.ToLower(tokens[i])
+		switch {
+		case l == "normal":
+		case fontStyles[l]:
+			prefix[0] = tokens[i]
+		case fontVariants[l]:
+			prefix[1] = tokens[i]
+		case isFontWeight(l):
+			prefix[2] = tokens[i]
+		case fontStretches[l]:
+			prefix[3] = tokens[i]
+		default:
+			break loop
+		}
+	}
+
+	if i >= len(tokens) || !(isLength(tokens[i]) || fontSizes[strings.ToLower(tokens[i])]) {
+		return nil, false
+	}
+	size, lineHeight := tokens[i], "normal"
+	i++
+
+	if i < len(tokens) && tokens[i] == "/" {
+		if i+1 >= len(tokens) {
+			return nil, false
+		}
+		lineHeight = tokens[i+1]
+		i += 2
+	}
+
+	if i >= len(tokens) {
+		return nil, false
+	}
+	family := append(tokenValues(tokens[i:]...), v[1:]...)
+
+	ret := make([][]Value, 0, 7)
+	for _, p := range prefix {
+		ret = append(ret, tokenValues(p))
+	}
+
+	return append(ret, tokenValues(size), tokenValues(lineHeight), family), true
+}
+
+func collapseFont(v [][]Value) ([]Value, bool) {
+	tokens, ok := singleTokens(v[:6])
+	if !ok || len(v[6]) == 0 {
+		return nil, false
+	}
+
+	style, variant, weight, stretch := strings.ToLower(tokens[0]), strings.ToLower(tokens[1]),
+		strings.ToLower(tokens[2]), strings.ToLower(tokens[3])
+	if (style != "normal" && !fontStyles[style]) ||
+		(variant != "normal" && !fontVariants[variant]) ||
+		(weight != "normal" && !isFontWeight(weight)) ||
+		(stretch != "normal" && !fontStretches[stretch]) {
+		return nil, false
+	}
+
+	var ret []string
+	for _, i := range tokens[:4] {
+		if !strings.EqualFold(i, "normal") {
+			ret = append(ret, i)
+		}
+	}
+
+	size := tokens[4]
+	if !strings.EqualFold(tokens[5], "normal") {
+		size += "/" + tokens[5]
+	}
+	ret = append(ret, size)
+	ret = append(ret, layerTokens(v[6], 0)...)
+
+	return append(tokenValues(ret...), v[6][1:]...), true
+}
+
+var (
+	backgroundRepeats     = keywords("repeat-x", "repeat-y", "repeat", "space", "round", "no-repeat")
+	backgroundAttachments = keywords("scroll", "fixed", "local")
+	backgroundBoxes       = keywords("border-box", "padding-box", "content-box")
+	backgroundPositions   = keywords("left", "right", "top", "bottom", "center")
+	backgroundSizes       = keywords("auto", "cover", "contain")
+	backgroundDefaults    = []string{"none", "0% 0%", "auto", "repeat", "scroll", "padding-box", "border-box", "transparent"}
+)
+
+func isImage(s string) bool {
+	if s == "none" {
+		return true
+	}
+	for _, i := range []string{"url(", "image(", "image-set(", "cross-fade(", "element(", "paint("} {
+		if strings.HasPrefix(s, i) || strings.HasPrefix(s, "-webkit-"+i) {
+			return true
+		}
+	}
+	return strings.Contains(s, "gradient(")
+}
+
+func expandBackground(v []Value) ([][]Value, bool) {
+	if len(v) == 0 {
+		return nil, false
+	}
+
+	ret := make([][]Value, 8)
+	color := backgroundDefaults[7]
+
+	for idx := range v {
+		var (
+			tokens = splitSlash(v[idx].ValueSpace)
+			layer  = make([][]string, 7)
+			boxes  []string
+		)
+
+		for i := 0; i < len(tokens); i++ {
+			t, l := tokens[i], strings.ToLower(tokens[i])
+			switch {
+			case isImage(l) && layer[0] == nil:
+				layer[0] = []string{t}
+			case (backgroundPositions[l] || isLength(l)) && layer[1] == nil:
+				for ; i < len(tokens) && (backgroundPositions[strings.ToLower(tokens[i])] || isLength(tokens[i])); i++ {
+					layer[1] = append(layer[1], tokens[i])
+				}
+				if i < len(tokens) && tokens[i] == "/" {
+					for i++; i < len(tokens) && (backgroundSizes[strings.ToLower(tokens[i])] || isLength(tokens[i])); i++ {
+						layer[2] = append(layer[2], tokens[i])
+					}
+					if layer[2] == nil {
+						return nil, false
+					}
+				}
+				i--
+			case backgroundRepeats[l] && len(layer[3]) < 2:
+				layer[3] = append(layer[3], t)
+			case backgroundAttachments[l] && layer[4] == nil:
+				layer[4] = []string{t}
+			case backgroundBoxes[l] && len(boxes) < 2:
+				boxes = append(boxes, t)
+			case idx == len(v)-1 && color == backgroundDefaults[7] && t != "/":
+				color = t
+			default:
+				return nil, false
+			}
+		}
+
+		switch len(boxes) {
+		case 1:
+			layer[5], layer[6] = boxes, boxes
+		case 2:
+			layer[5], layer[6] = boxes[:1], boxes[1:]
+		}
+
+		for k, i := range layer {
+			if i == nil {
+				i = strings.Fields(backgroundDefaults[k])
+			}
+			ret[k] = append(ret[k], tokenValues(i...)...)
+		}
+	}
+
+	ret[7] = tokenValues(color)
+
+	return ret, true
+}
+
+func collapseBackground(v [][]Value) ([]Value, bool) {
+	layers := len(v[0])
+	for _, i := range v[:7] {
+		if len(i) != layers {
+			return nil, false
+		}
+	}
+	if len(v[7]) != 1 {
+		return nil, false
+	}
+
+	var ret []Value
+	for idx := 0; idx < layers; idx++ {
+		var (
+			layer = make([]string, 8)
+			out   []string
+		)
+		for k := range layer[:7] {
+			layer[k] = strings.Join(layerTokens(v[k], idx), " ")
+		}
+		layer[7] = strings.Join(layerTokens(v[7], 0), " ")
+
+		if layer[0] != backgroundDefaults[0] {
+			out = append(out, layer[0])
+		}
+
+		switch {
+		case layer[2] != backgroundDefaults[2]:
+			position := strings.Fields(layer[1])
+			size := strings.Fields(layer[2])
+			out = append(out, position[:len(position)-1]...)
+			out = append(out, position[len(position)-1]+"/"+size[0])
+			out = append(out, size[1:]...)
+		case layer[1] != backgroundDefaults[1]:
+			out = append(out, strings.Fields(layer[1])...)
+		}
+
+		for k := 3; k < 5; k++ {
+			if layer[k] != backgroundDefaults[k] {
+				out = append(out, strings.Fields(layer[k])...)
+			}
+		}
+
+		switch {
+		case layer[5] == backgroundDefaults[5] && layer[6] == backgroundDefaults[6]:
+		case layer[5] == layer[6]:
+			out = append(out, layer[5])
+		default:
+			out = append(out, layer[5], layer[6])
+		}
+
+		if idx == layers-1 && !strings.EqualFold(layer[7], backgroundDefaults[7]) {
+			out = append(out, layer[7])
+		}
+
+		if len(out) == 0 {
+			out = []string{backgroundDefaults[0]}
+		}
+
+		ret = append(ret, tokenValues(out...)...)
+	}
+
+	return ret, true
+}
+
+func expandFlex(v []Value) ([][]Value, bool) {
+	if len(v) != 1 || len(v[0].ValueSpace) > 3 {
+		return nil, false
+	}
+
+	tokens := layerTokens(v, 0)
+	grow, shrink, basis := "1", "1", "0%"
+
+	switch len(tokens) {
+	case 1:
+		switch t := strings.ToLower(tokens[0]); {
+		case t == "none":
+			grow, shrink, basis = "0", "0", "auto"
+		case t == "auto":
+			basis = "auto"
+		case isNumber(t):
+			grow = tokens[0]
+		default:
+			basis = tokens[0]
+		}
+	case 2:
+		if !isNumber(tokens[0]) {
+			return nil, false
+		}
+		grow = tokens[0]
+		if isNumber(tokens[1]) {
+			shrink = tokens[1]
+		} else {
+			basis = tokens[1]
+		}
+	case 3:
+		if !isNumber(tokens[0]) || !isNumber(tokens[1]) {
+			return nil, false
+		}
+		grow, shrink, basis = tokens[0], tokens[1], tokens[2]
+	}
+
+	return [][]Value{tokenValues(grow), tokenValues(shrink), tokenValues(basis)}, true
+}
+
+func collapseFlex(v [][]Value) ([]Value, bool) {
+	tokens, ok := singleTokens(v)
+	if !ok || !isNumber(tokens[0]) || !isNumber(tokens[1]) {
+		return nil, false
+	}
+
+	grow, _ := strconv.ParseFloat(tokens[0], 64)
+	shrink, _ := strconv.ParseFloat(tokens[1], 64)
+	basis := strings.ToLower(tokens[2])
+
+	switch {
+	case grow == 0 && shrink == 0 && basis == "auto":
+		return tokenValues("none"), true
+	case grow == 1 && shrink == 1 && basis == "auto":
+		return tokenValues("auto"), true
+	case shrink == 1 && basis == "0%":
+		return tokenValues(tokens[0]), true
+	case shrink == 1 && !isNumber(basis):
+		return tokenValues(tokens[0], tokens[2]), true
+	}
+
+	return tokenValues(tokens...), true
+}
+
+// isCustomIdent reports whether a grid line is a single name
+func isCustomIdent(line []string) bool {
+	if len(line) != 1 {
+		return false
+	}
+	l := strings.ToLower(line[0])
+	return l != "auto" && l != "span" && !isLength(l)
+}
+
+func expandGridArea(v []Value) ([][]Value, bool) {
+	if len(v) != 1 {
+		return nil, false
+	}
+
+	lines := [][]string{nil}
+	for _, t := range splitSlash(v[0].ValueSpace) {
+		if t == "/" {
+			lines = append(lines, nil)
+			continue
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], t)
+	}
+
+	if len(lines) > 4 {
+		return nil, false
+	}
+	for _, i := range lines {
+		if len(i) == 0 {
+			return nil, false
+		}
+	}
+
+	// omitted column start and row end copy row start, column end
+	// copies column start
+	copies := []int{0, 0, 0, 1}
+	for len(lines) < 4 {
+		line := []string{"auto"}
+		if src := lines[copies[len(lines)]]; isCustomIdent(src) {
+			line = src
+		}
+		lines = append(lines, line)
+	}
+
+	ret := make([][]Value, 4)
+	for k, i := range lines {
+		ret[k] = tokenValues(i...)
+	}
+
+	return ret, true
+}
+
+func collapseGridArea(v [][]Value) ([]Value, bool) {
+	lines := make([][]string, 4)
+	for k, i := range v {
+		if len(i) != 1 || len(i[0].ValueSpace) == 0 {
+			return nil, false
+		}
+		lines[k] = layerTokens(i, 0)
+	}
+
+	omitted := func(line, src []string) bool {
+		if isCustomIdent(src) {
+			return strings.Join(line, " ") == src[0]
+		}
+		return len(line) == 1 && strings.EqualFold(line[0], "auto")
+	}
+
+	n := 4
+	if omitted(lines[3], lines[1]) {
+		n = 3
+		if omitted(lines[2], lines[0]) {
+			n = 2
+			if omitted(lines[1], lines[0]) {
+				n = 1
+			}
+		}
+	}
+
+	var ret []string
+	for k, i := range lines[:n] {
+		if k > 0 {
+			ret = append(ret, "/")
+		}
+		ret = append(ret, i...)
+	}
+
+	return tokenValues(ret...), true
+}
+
+var transitionTimings = keywords("ease", "linear", "ease-in", "ease-out", "ease-in-out", "step-start", "step-end")
+
+func isTimingFunction(s string) bool {
+	return transitionTimings[s] ||
+		strings.HasPrefix(s, "cubic-bezier(") ||
+		strings.HasPrefix(s, "steps(") ||
+		strings.HasPrefix(s, "linear(")
+}
+
+func expandTransition(v []Value) ([][]Value, bool) {
+	if len(v) == 0 {
+		return nil, false
+	}
+
+	ret := make([][]Value, 4)
+	for idx := range v {
+		var property, timing string
+		var times []string
+
+		for _, t := range layerTokens(v, idx) {
+			l := strings.ToLower(t)
+			switch {
+			case isTime(l) && len(times) < 2:
+				times = append(times, t)
+			case isTimingFunction(l) && timing == "":
+				timing = t
+			case l == "normal" || l == "allow-discrete":
+				return nil, false
+			case property == "" && !isTime(l) && !isTimingFunction(l):
+				property = t
+			default:
+				return nil, false
+			}
+		}
+
+		times = append(times, "0s", "0s")
+		if property == "" {
+			property = "all"
+		}
+		if timing == "" {
+			timing = "ease"
+		}
+
+		for k, i := range []string{property, times[0], timing, times[1]} {
+			ret[k] = append(ret[k], tokenValues(i)...)
+		}
+	}
+
+	return ret, true
+}
+
+func collapseTransition(v [][]Value) ([]Value, bool) {
+	layers := len(v[0])
+	for _, i := range v {
+		if len(i) != layers {
+			return nil, false
+		}
+	}
+
+	var ret []Value
+	for idx := 0; idx < layers; idx++ {
+		var out []string
+
+		layer := make([]string, 4)
+		for k := range layer {
+			tokens := layerTokens(v[k], idx)
+			if len(tokens) != 1 {
+				return nil, false
+			}
+			layer[k] = tokens[0]
+		}
+
+		if !strings.EqualFold(layer[0], "all") {
+			out = append(out, layer[0])
+		}
+		if !isZero(layer[1]) || !isZero(layer[3]) {
+			out = append(out, layer[1])
+		}
+		if !strings.EqualFold(layer[2], "ease") {
+			out = append(out, layer[2])
+		}
+		if !isZero(layer[3]) {
+			out = append(out, layer[3])
+		}
+
+		if len(out) == 0 {
+			out = []string{"all"}
+		}
+
+		ret = append(ret, tokenValues(out...)...)
+	}
+
+	return ret, true
+}
+
+// pairShorthand sets start and end longhands, the end copies the start
+// when omitted
+func pairShorthand(start, end string) *shorthand {
+	return &shorthand{longhands: []string{start, end}, expand: expandPair, collapse: collapsePair}
+}
+
+func expandPair(v []Value) ([][]Value, bool) {
+	if len(v) != 1 || len(v[0].ValueSpace) == 0 || len(v[0].ValueSpace) > 2 {
+		return nil, false
+	}
+
+	tokens := layerTokens(v, 0)
+	return [][]Value{tokenValues(tokens[0]), tokenValues(tokens[len(tokens)-1])}, true
+}
+
+func collapsePair(v [][]Value) ([]Value, bool) {
+	tokens, ok := singleTokens(v)
+	if !ok {
+		return nil, false
+	}
+	if tokens[0] == tokens[1] {
+		return tokenValues(tokens[0]), true
+	}
+	return tokenValues(tokens...), true
+}
+
+// boxShorthand sets longhands of four sides named by prefix, side and suffix
+func boxShorthand(prefix, suffix string) *shorthand {
+	ret := &shorthand{expand: expandBox, collapse: collapseBox}
+	for _, i := range []string{"top", "right", "bottom", "left"} {
+		ret.longhands = append(ret.longhands, prefix+i+suffix)
+	}
+	return ret
+}
+
+// sideShorthand sets width, style and color of one border side
+func sideShorthand(prefix string) *shorthand {
+	return &shorthand{
+		longhands: []string{prefix + "-width", prefix + "-style", prefix + "-color"},
+		expand:    expandBorderSide,
+		collapse:  collapseBorderSide,
+	}
+}
+
+func expandBorderSide(v []Value) ([][]Value, bool) {
+	sides, ok := expandBorder(v)
+	if !ok {
+		return nil, false
+	}
+	return [][]Value{sides[0], sides[4], sides[8]}, true
+}
+
+func collapseBorderSide(v [][]Value) ([]Value, bool) {
+	sides := make([][]Value, 0, 12)
+	for _, i := range v {
+		sides = append(sides, i, i, i, i)
+	}
+	return collapseBorder(sides)
+}
+
+// expandBorderSides sets both sides of border-inline or border-block
+func expandBorderSides(v []Value) ([][]Value, bool) {
+	side, ok := expandBorderSide(v)
+	if !ok {
+		return nil, false
+	}
+	return append(side, side...), true
+}
+
+func collapseBorderSides(v [][]Value) ([]Value, bool) {
+	for k := range v[:3] {
+		if !bytes.Equal(valuesBytes(v[k]), valuesBytes(v[k+3])) {
+			return nil, false
+		}
+	}
+	return collapseBorderSide(v[:3])
+}
+
+// expandRadius sets corners from horizontal radii and optional vertical
+// ones after "/"
+func expandRadius(v []Value) ([][]Value, bool) {
+	if len(v) != 1 {
+		return nil, false
+	}
+
+	radii := [][]string{nil}
+	for _, t := range splitSlash(v[0].ValueSpace) {
+		if t == "/" {
+			radii = append(radii, nil)
+			continue
+		}
+		radii[len(radii)-1] = append(radii[len(radii)-1], t)
+	}
+	if len(radii) > 2 {
+		return nil, false
+	}
+
+	corners := make([][]string, 4)
+	for _, r := range radii {
+		box, ok := expandBox(tokenValues(r...))
+		if !ok {
+			return nil, false
+		}
+		for k, i := range box {
+			corners[k] = append(corners[k], layerTokens(i, 0)...)
+		}
+	}
+
+	ret := make([][]Value, 4)
+	for k, i := range corners {
+		if len(i) == 2 && i[0] == i[1] {
+			i = i[:1]
+		}
+		ret[k] = tokenValues(i...)
+	}
+
+	return ret, true
+}
+
+func collapseRadius(v [][]Value) ([]Value, bool) {
+	horizontal, vertical := make([][]Value, 4), make([][]Value, 4)
+	for k, i := range v {
+		if len(i) != 1 || len(i[0].ValueSpace) == 0 || len(i[0].ValueSpace) > 2 {
+			return nil, false
+		}
+		tokens := layerTokens(i, 0)
+		horizontal[k], vertical[k] = tokenValues(tokens[0]), tokenValues(tokens[len(tokens)-1])
+	}
+
+	h, _ := collapseBox(horizontal)
+	vv, _ := collapseBox(vertical)
+	if bytes.Equal(valuesBytes(h), valuesBytes(vv)) {
+		return h, true
+	}
+
+	return tokenValues(append(append(layerTokens(h, 0), "/"), layerTokens(vv, 0)...)...), true
+}
+
+var (
+	borderImageRepeats  = keywords("stretch", "repeat", "round", "space")
+	borderImageDefaults = []string{"none", "100%", "1", "0", "stretch"}
+)
+
+// expandBorderImage splits source, slice with optional width and outset
+// after "/", and repeat
+func expandBorderImage(v []Value) ([][]Value, bool) {
+	if len(v) != 1 {
+		return nil, false
+	}
+
+	var (
+		tokens = splitSlash(v[0].ValueSpace)
+		parts  = make([][]string, 5)
+	)
+
+	for i := 0; i < len(tokens); i++ {
+		t, l := tokens[i], strings.ToLower(tokens[i])
+		switch {
+		case isImage(l) && parts[0] == nil:
+			parts[0] = []string{t}
+		case borderImageRepeats[l] && parts[4] == nil:
+			for ; i < len(tokens) && borderImageRepeats[strings.ToLower(tokens[i])] && len(parts[4]) < 2; i++ {
+				parts[4] = append(parts[4], tokens[i])
+			}
+			i--
+		case (isLength(l) || l == "fill") && parts[1] == nil:
+			for ; i < len(tokens) && (isLength(tokens[i]) || strings.EqualFold(tokens[i], "fill")); i++ {
+				parts[1] = append(parts[1], tokens[i])
+			}
+			// width may be omitted before outset like "30 / / 1px"
+			for n := 2; n < 4 && i < len(tokens) && tokens[i] == "/"; n++ {
+				for i++; i < len(tokens) && (isLength(tokens[i]) || strings.EqualFold(tokens[i], "auto")); i++ {
+					parts[n] = append(parts[n], tokens[i])
+				}
+				if parts[n] == nil && (n == 3 || i >= len(tokens) || tokens[i] != "/") {
+					return nil, false
+				}
+			}
+			i--
+		default:
+			return nil, false
+		}
+	}
+
+	ret := make([][]Value, 5)
+	for k, i := range parts {
+		if i == nil {
+			i = []string{borderImageDefaults[k]}
+		}
+		ret[k] = tokenValues(i...)
+	}
+
+	return ret, true
+}
+
+func collapseBorderImage(v [][]Value) ([]Value, bool) {
+	parts := make([]string, 5)
+	for k, i := range v {
+		if len(i) != 1 || len(i[0].ValueSpace) == 0 {
+			return nil, false
+		}
+		parts[k] = strings.Join(layerTokens(i, 0), " ")
+	}
+
+	var ret []string
+	if !strings.EqualFold(parts[0], borderImageDefaults[0]) {
+		ret = append(ret, parts[0])
+	}
+
+	width, outset := parts[2] != borderImageDefaults[2], !isZero(parts[3]) || strings.Contains(parts[3], " ")
+	if parts[1] != borderImageDefaults[1] || width || outset {
+		ret = append(ret, strings.Fields(parts[1])...)
+	}
+	if width || outset {
+		ret = append(ret, "/")
+		if width {
+			ret = append(ret, strings.Fields(parts[2])...)
+		}
+	}
+	if outset {
+		ret = append(ret, "/")
+		ret = append(ret, strings.Fields(parts[3])...)
+	}
+
+	if !strings.EqualFold(parts[4], borderImageDefaults[4]) {
+		ret = append(ret, strings.Fields(parts[4])...)
+	}
+
+	if len(ret) == 0 {
+		ret = []string{borderImageDefaults[0]}
+	}
+
+	return tokenValues(ret...), true
+}
+
+// fontVariantKeywords are values of font-variant longhands in order
+var fontVariantKeywords = []map[string]bool{
+	keywords("common-ligatures", "no-common-ligatures", "discretionary-ligatures", "no-discretionary-ligatures",
+		"historical-ligatures", "no-historical-ligatures", "contextual", "no-contextual"),
+	keywords("small-caps", "all-small-caps", "petite-caps", "all-petite-caps", "unicase", "titling-caps"),
+	keywords("historical-forms"),
+	keywords("lining-nums", "oldstyle-nums", "proportional-nums", "tabular-nums", "diagonal-fractions",
+		"stacked-fractions", "ordinal", "slashed-zero"),
+	keywords("jis78", "jis83", "jis90", "jis04", "simplified", "traditional", "full-width", "proportional-width", "ruby"),
+	keywords("sub", "super"),
+	keywords("text", "emoji", "unicode"),
+}
+
+// isFontAlternate reports whether s is a function of font-variant-alternates
+func isFontAlternate(s string) bool {
+	for _, i := range []string{"stylistic(", "styleset(", "character-variant(", "swash(", "ornaments(", "annotation("} {
+		if strings.HasPrefix(s, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func expandFontVariant(v []Value) ([][]Value, bool) {
+	if len(v) != 1 || len(v[0].ValueSpace) == 0 {
+		return nil, false
+	}
+
+	var (
+		tokens = layerTokens(v, 0)
+		parts  = make([][]string, len(fontVariantKeywords))
+	)
+
+	switch strings.ToLower(tokens[0]) {
+	case "normal", "none":
+		if len(tokens) > 1 {
+			return nil, false
+		}
+		if strings.EqualFold(tokens[0], "none") {
+			parts[0] = tokens
+		}
+		tokens = nil
+	}
+
+tokens:
+	for _, t := range tokens {
+		l := strings.ToLower(t)
+		if isFontAlternate(l) {
+			parts[2] = append(parts[2], t)
+			continue
+		}
+		for k, i := range fontVariantKeywords {
+			if i[l] {
+				parts[k] = append(parts[k], t)
+				continue tokens
+			}
+		}
+		return nil, false
+	}
+
+	ret := make([][]Value, len(parts))
+	for k, i := range parts {
+		if i == nil {
+			i = []string{"normal"}
+		}
+		ret[k] = tokenValues(i...)
+	}
+
+	return ret, true
+}
+
+func collapseFontVariant(v [][]Value) ([]Value, bool) {
+	var ret []string
+	for k, i := range v {
+		if len(i) != 1 || len(i[0].ValueSpace) == 0 {
+			return nil, false
+		}
+		tokens := layerTokens(i, 0)
+		switch l := strings.ToLower(strings.Join(tokens, " ")); {
+		case l == "normal":
+		case l == "none" && k == 0:
+			ret = append(ret, tokens...)
+		default:
+			if len(ret) > 0 && strings.EqualFold(ret[0], "none") {
+				return nil, false
+			}
+			ret = append(ret, tokens...)
+		}
+	}
+
+	if len(ret) > 1 && strings.EqualFold(ret[0], "none") {
+		return nil, false
+	}
+	if len(ret) == 0 {
+		ret = []string{"normal"}
+	}
+
+	return tokenValues(ret...), true
+}
+
+// lineShorthand sets start and end grid lines, an omitted end copies a
+// name of the start or is auto
+func lineShorthand(start, end string) *shorthand {
+	return &shorthand{longhands: []string{start, end}, expand: expandGridLine, collapse: collapseGridLine}
+}
+
+func expandGridLine(v []Value) ([][]Value, bool) {
+	if len(v) != 1 {
+		return nil, false
+	}
+
+	lines := [][]string{nil}
+	for _, t := range splitSlash(v[0].ValueSpace) {
+		if t == "/" {
+			lines = append(lines, nil)
+			continue
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], t)
+	}
+	if len(lines) > 2 || len(lines[0]) == 0 || len(lines) == 2 && len(lines[1]) == 0 {
+		return nil, false
+	}
+
+	if len(lines) == 1 {
+		line := []string{"auto"}
+		if isCustomIdent(lines[0]) {
+			line = lines[0]
+		}
+		lines = append(lines, line)
+	}
+
+	return [][]Value{tokenValues(lines[0]...), tokenValues(lines[1]...)}, true
+}
+
+func collapseGridLine(v [][]Value) ([]Value, bool) {
+	lines := make([][]string, 2)
+	for k, i := range v {
+		if len(i) != 1 || len(i[0].ValueSpace) == 0 {
+			return nil, false
+		}
+		lines[k] = layerTokens(i, 0)
+	}
+
+	end := strings.Join(lines[1], " ")
+	if isCustomIdent(lines[0]) && end == lines[0][0] || !isCustomIdent(lines[0]) && strings.EqualFold(end, "auto") {
+		return tokenValues(lines[0]...), true
+	}
+
+	return tokenValues(append(append(lines[0], "/"), lines[1]...)...), true
+}
+
+// keywordLonghand is a longhand of keywordShorthand, a token goes to the
+// first longhand it matches
+type keywordLonghand struct {
+	initial  string
+	match    func(string) bool
+	multiple bool
+}
+
+// keywordShorthand sets longhands told apart by their values in any order
+func keywordShorthand(longhands []string, values []keywordLonghand) *shorthand {
+	expand := func(v []Value) ([][]Value, bool) {
+		if len(v) != 1 || len(v[0].ValueSpace) == 0 {
+			return nil, false
+		}
+
+		parts := make([][]string, len(values))
+	tokens:
+		for _, t := range layerTokens(v, 0) {
+			l := strings.ToLower(t)
+			for k, i := range values {
+				if i.match(l) && (parts[k] == nil || i.multiple) {
+					parts[k] = append(parts[k], t)
+					continue tokens
+				}
+			}
+			return nil, false
+		}
+
+		ret := make([][]Value, len(parts))
+		for k, i := range parts {
+			if i == nil {
+				i = []string{values[k].initial}
+			}
+			ret[k] = tokenValues(i...)
+		}
+		return ret, true
+	}
+
+	collapse := func(v [][]Value) ([]Value, bool) {
+		var ret []string
+		for k, i := range v {
+			if len(i) != 1 || len(i[0].ValueSpace) == 0 {
+				return nil, false
+			}
+			if tokens := layerTokens(i, 0); !strings.EqualFold(strings.Join(tokens, " "), values[k].initial) {
+				ret = append(ret, tokens...)
+			}
+		}
+		if len(ret) == 0 {
+			ret = []string{values[0].initial}
+		}
+
+		// values which expand to other longhands can't be collapsed
+		collapsed := tokenValues(ret...)
+		expanded, ok := expand(collapsed)
+		if !ok {
+			return nil, false
+		}
+		for k := range v {
+			if !strings.EqualFold(string(valuesBytes(expanded[k])), string(valuesBytes(v[k]))) {
+				return nil, false
+			}
+		}
+		return collapsed, true
+	}
+
+	return &shorthand{longhands: longhands, expand: expand, collapse: collapse}
+}
+
+var flexFlowKeywords = []keywordLonghand{
+	{initial: "row", match: func(s string) bool { return keywords("row", "row-reverse", "column", "column-reverse")[s] }},
+	{initial: "nowrap", match: func(s string) bool { return keywords("nowrap", "wrap", "wrap-reverse")[s] }},
+}
+
+var (
+	textDecorationLines  = keywords("none", "underline", "overline", "line-through", "blink", "spelling-error", "grammar-error")
+	textDecorationStyles = keywords("solid", "double", "dotted", "dashed", "wavy")
+)
+
+func isTextDecorationThickness(s string) bool {
+	return s == "auto" || s == "from-font" || isLength(s)
+}
+
+var textDecorationKeywords = []keywordLonghand{
+	{initial: "none", match: func(s string) bool { return textDecorationLines[s] }, multiple: true},
+	{initial: "solid", match: func(s string) bool { return textDecorationStyles[s] }},
+	{initial: "currentcolor", match: func(s string) bool {
+		return !textDecorationLines[s] && !textDecorationStyles[s] && !isTextDecorationThickness(s)
+	}},
+	{initial: "auto", match: isTextDecorationThickness},
+}
+
+var columnsKeywords = []keywordLonghand{
+	{initial: "auto", match: func(s string) bool { return s == "auto" || isLength(s) && !isNumber(s) }},
+	{initial: "auto", match: func(s string) bool { return s == "auto" || isNumber(s) }},
+}
+
+var listStylePositions = keywords("inside", "outside")
+
+// expandListStyle sets position, image and type, none goes to image and
+// type not set by other values
+func expandListStyle(v []Value) ([][]Value, bool) {
+	if len(v) != 1 || len(v[0].ValueSpace) == 0 || len(v[0].ValueSpace) > 3 {
+		return nil, false
+	}
+
+	var (
+		position, image, typ string
+		nones                int
+	)
+	for _, t := range layerTokens(v, 0) {
+		l := strings.ToLower(t)
+		switch {
+		case l == "none":
+			nones++
+		case listStylePositions[l] && position == "":
+			position = t
+		case isImage(l) && image == "":
+			image = t
+		case !listStylePositions[l] && !isImage(l) && typ == "":
+			typ = t
+		default:
+			return nil, false
+		}
+	}
+
+	switch {
+	case nones == 0:
+	case nones == 2 && image == "" && typ == "", nones == 1 && image == "" && typ == "":
+		image, typ = "none", "none"
+	case nones == 1 && image == "":
+		image = "none"
+	case nones == 1 && typ == "":
+		typ = "none"
+	default:
+		return nil, false
+	}
+
+	ret := make([][]Value, 3)
+	for k, i := range []string{position, image, typ} {
+		if i == "" {
+			i = []string{"outside", "none", "disc"}[k]
+		}
+		ret[k] = tokenValues(i)
+	}
+
+	return ret, true
+}
+
+func collapseListStyle(v [][]Value) ([]Value, bool) {
+	tokens, ok := singleTokens(v)
+	if !ok {
+		return nil, false
+	}
+	position, image, typ := tokens[0], tokens[1], tokens[2]
+
+	var ret []string
+	if !strings.EqualFold(position, "outside") {
+		ret = append(ret, position)
+	}
+	switch {
+	case strings.EqualFold(image, "none") && strings.EqualFold(typ, "none"):
+		ret = append(ret, "none")
+	case strings.EqualFold(image, "none"):
+		ret = append(ret, typ)
+	case strings.EqualFold(typ, "disc"):
+		ret = append(ret, image)
+	default:
+		ret = append(ret, image, typ)
+	}
+
+	return tokenValues(ret...), true
+}
@@ -0,0 +1,295 @@
+package css2json
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDeclarationsBytes(decls []Declaration) string {
+	var ret []string
+	for _, d := range decls {
+		ret = append(ret, string(encodeBytes(&d)))
+	}
+	return strings.Join(ret, ";")
+}
+
+func TestRuleset_ExpandShorthands(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "margin:1px 2px",
+			want: "margin-top:1px;margin-right:2px;margin-bottom:1px;margin-left:2px",
+		},
+		{
+			in:   "padding:1px 2px 3px !important",
+			want: "padding-top:1px !important;padding-right:2px !important;padding-bottom:3px !important;padding-left:2px !important",
+		},
+		{
+			in:   "inset:0",
+			want: "top:0;right:0;bottom:0;left:0",
+		},
+		{
+			in: "border:red 2px",
+			want: "border-image-source:initial;border-image-slice:initial;border-image-width:initial;" +
+				"border-image-outset:initial;border-image-repeat:initial;" +
+				"border-top-width:2px;border-right-width:2px;border-bottom-width:2px;border-left-width:2px;" +
+				"border-top-style:none;border-right-style:none;border-bottom-style:none;border-left-style:none;" +
+				"border-top-color:red;border-right-color:red;border-bottom-color:red;border-left-color:red",
+		},
+		{
+			in: "font:italic bold 12px/1.5 \"Helvetica Neue\",serif",
+			want: "font-kerning:initial;font-size-adjust:initial;font-language-override:initial;font-optical-sizing:initial;" +
+				"font-palette:initial;font-variation-settings:initial;" +
+				"font-style:italic;font-variant-ligatures:normal;font-variant-caps:normal;font-variant-alternates:normal;" +
+				"font-variant-numeric:normal;font-variant-east-asian:normal;font-variant-position:normal;" +
+				"font-variant-emoji:normal;font-weight:bold;font-stretch:normal;" +
+				"font-size:12px;line-height:1.5;font-family:\"Helvetica Neue\",serif",
+		},
+		{
+			in: "font:700 condensed large serif",
+			want: "font-kerning:initial;font-size-adjust:initial;font-language-override:initial;font-optical-sizing:initial;" +
+				"font-palette:initial;font-variation-settings:initial;" +
+				"font-style:normal;font-variant-ligatures:normal;font-variant-caps:normal;font-variant-alternates:normal;" +
+				"font-variant-numeric:normal;font-variant-east-asian:normal;font-variant-position:normal;" +
+				"font-variant-emoji:normal;font-weight:700;font-stretch:condensed;" +
+				"font-size:large;line-height:normal;font-family:serif",
+		},
+		{
+			in: "background:url(a.png) no-repeat center/cover,#fff linear-gradient(red, blue) fixed content-box",
+			want: "background-image:url(a.png),linear-gradient(red, blue);background-position:center,0% 0%;" +
+				"background-size:cover,auto;background-repeat:no-repeat,repeat;background-attachment:scroll,fixed;" +
+				"background-origin:padding-box,content-box;background-clip:border-box,content-box;background-color:#fff",
+		},
+		{
+			in:   "flex:2",
+			want: "flex-grow:2;flex-shrink:1;flex-basis:0%",
+		},
+		{
+			in:   "flex:none",
+			want: "flex-grow:0;flex-shrink:0;flex-basis:auto",
+		},
+		{
+			in:   "flex:1 30px",
+			want: "flex-grow:1;flex-shrink:1;flex-basis:30px",
+		},
+		{
+			in:   "grid-area:main",
+			want: "grid-row-start:main;grid-column-start:main;grid-row-end:main;grid-column-end:main",
+		},
+		{
+			in:   "grid-area:1/span 2",
+			want: "grid-row-start:1;grid-column-start:span 2;grid-row-end:auto;grid-column-end:auto",
+		},
+		{
+			in: "transition:opacity .3s ease-in,transform 1s 200ms",
+			want: "transition-property:opacity,transform;transition-duration:.3s,1s;" +
+				"transition-timing-function:ease-in,ease;transition-delay:0s,200ms",
+		},
+		{
+			in:   "border-width:1px 2px",
+			want: "border-top-width:1px;border-right-width:2px;border-bottom-width:1px;border-left-width:2px",
+		},
+		{
+			in:   "border-inline-start:1px solid",
+			want: "border-inline-start-width:1px;border-inline-start-style:solid;border-inline-start-color:currentcolor",
+		},
+		{
+			in:   "margin-block:0 auto",
+			want: "margin-block-start:0;margin-block-end:auto",
+		},
+		{
+			in:   "border-radius:1px 2px/3px",
+			want: "border-top-left-radius:1px 3px;border-top-right-radius:2px 3px;border-bottom-right-radius:1px 3px;border-bottom-left-radius:2px 3px",
+		},
+		{
+			in: "border-image:url(a.png) 30 / / 2px round",
+			want: "border-image-source:url(a.png);border-image-slice:30;border-image-width:1;" +
+				"border-image-outset:2px;border-image-repeat:round",
+		},
+		{
+			in: "font-variant:small-caps tabular-nums",
+			want: "font-variant-ligatures:normal;font-variant-caps:small-caps;font-variant-alternates:normal;" +
+				"font-variant-numeric:tabular-nums;font-variant-east-asian:normal;font-variant-position:normal;" +
+				"font-variant-emoji:normal",
+		},
+		{
+			in:   "list-style:none",
+			want: "list-style-position:outside;list-style-image:none;list-style-type:none",
+		},
+		{
+			in:   "text-decoration:underline overline red",
+			want: "text-decoration-line:underline overline;text-decoration-style:solid;text-decoration-color:red;text-decoration-thickness:auto",
+		},
+		{
+			in:   "grid-column:main",
+			want: "grid-column-start:main;grid-column-end:main",
+		},
+		{
+			in:   "margin:inherit",
+			want: "margin-top:inherit;margin-right:inherit;margin-bottom:inherit;margin-left:inherit",
+		},
+		{
+			in:   "margin:var(--m)",
+			want: "margin:var(--m)",
+		},
+		{
+			in:   "margin:1px 2px 3px 4px 5px",
+			want: "margin:1px 2px 3px 4px 5px",
+		},
+		{
+			in:   "color:red",
+			want: "color:red",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v := &Ruleset{Declarations: testDeclarations(tt.in)}
+			v.ExpandShorthands()
+			if got := testDeclarationsBytes(v.Declarations); got != tt.want {
+				t.Errorf("Ruleset.ExpandShorthands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleset_CollapseShorthands(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{
+			in:   []string{"color:red", "margin-top:1px", "margin-right:2px", "margin-bottom:1px", "margin-left:2px"},
+			want: "color:red;margin:1px 2px",
+		},
+		{
+			in:   []string{"margin-top:1px", "margin-right:1px", "margin-bottom:1px", "margin-left:1px", "margin-top:0"},
+			want: "margin:0 1px 1px",
+		},
+		{
+			in:   []string{"margin-top:1px", "margin-right:1px", "margin-bottom:1px"},
+			want: "margin-top:1px;margin-right:1px;margin-bottom:1px",
+		},
+		{
+			in:   []string{"margin-top:1px !important", "margin-right:1px", "margin-bottom:1px", "margin-left:1px"},
+			want: "margin-top:1px !important;margin-right:1px;margin-bottom:1px;margin-left:1px",
+		},
+		{
+			in:   []string{"margin-top:1px", "margin:0", "margin-right:1px", "margin-bottom:1px", "margin-left:1px"},
+			want: "margin-top:1px;margin:0;margin-right:1px;margin-bottom:1px;margin-left:1px",
+		},
+		{
+			in:   []string{"margin-top:1px", "margin-block:2px", "margin-right:1px", "margin-bottom:1px", "margin-left:1px"},
+			want: "margin-top:1px;margin-block:2px;margin-right:1px;margin-bottom:1px;margin-left:1px",
+		},
+		{
+			in:   []string{"margin-top:1px", "margin-right:1px", "margin-bottom:1px", "margin-left:1px", "margin-inline-start:2px"},
+			want: "margin:1px;margin-inline-start:2px",
+		},
+		{
+			in:   []string{"top:inherit", "right:inherit", "bottom:inherit", "left:inherit"},
+			want: "inset:inherit",
+		},
+		{
+			in:   []string{"flex-grow:0", "flex-shrink:1", "flex-basis:0"},
+			want: "flex:0 1 0",
+		},
+		{
+			in:   []string{"grid-row-start:a", "grid-column-start:b", "grid-row-end:a", "grid-column-end:b"},
+			want: "grid-area:a / b",
+		},
+		{
+			in: []string{
+				"border-image:url(a.png) 30", "border-top-width:1px", "border-right-width:1px", "border-bottom-width:1px",
+				"border-left-width:1px", "border-top-style:solid", "border-right-style:solid", "border-bottom-style:solid",
+				"border-left-style:solid", "border-top-color:red", "border-right-color:red", "border-bottom-color:red",
+				"border-left-color:red",
+			},
+			want: "border-image:url(a.png) 30;border-width:1px;border-style:solid;border-color:red",
+		},
+		{
+			in: []string{
+				"font-style:italic", "font-variant:normal", "font-weight:bold", "font-stretch:normal",
+				"font-size:12px", "line-height:normal", "font-family:serif", "font-kerning:none",
+			},
+			want: "font:italic bold 12px serif;font-kerning:none",
+		},
+		{
+			in: []string{
+				"font-variant-numeric:tabular-nums", "font-style:italic", "font-variant:normal", "font-weight:bold",
+				"font-stretch:normal", "font-size:12px", "line-height:normal", "font-family:serif",
+			},
+			want: "font-variant-numeric:tabular-nums;font-style:italic;font-variant:normal;font-weight:bold;" +
+				"font-stretch:normal;font-size:12px;line-height:normal;font-family:serif",
+		},
+		{
+			in: []string{
+				"font-kerning:initial", "font-style:italic", "font-variant:normal", "font-weight:bold",
+				"font-stretch:normal", "font-size:12px", "line-height:normal", "font-family:serif",
+			},
+			want: "font:italic bold 12px serif",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.in, ";"), func(t *testing.T) {
+			v := &Ruleset{Declarations: testDeclarations(tt.in...)}
+			v.CollapseShorthands()
+			if got := testDeclarationsBytes(v.Declarations); got != tt.want {
+				t.Errorf("Ruleset.CollapseShorthands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleset_ShorthandsRoundTrip(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "margin:1px 2px 3px", want: "margin:1px 2px 3px"},
+		{in: "border:1px solid red", want: "border:1px solid red"},
+		{in: "border:none", want: "border:none"},
+		{in: "border:inherit", want: "border:inherit"},
+		{in: "font:bold 12px/30px Georgia,serif", want: "font:bold 12px/30px Georgia,serif"},
+		{in: "font:12px serif !important", want: "font:12px serif !important"},
+		{in: "background:url(a.png) no-repeat 0 0/50% auto,red", want: "background:url(a.png) 0 0/50% auto no-repeat,red"},
+		{in: "background:none", want: "background:none"},
+		{in: "background:padding-box", want: "background:padding-box"},
+		{in: "flex:auto", want: "flex:auto"},
+		{in: "flex:3", want: "flex:3"},
+		{in: "flex:2 2 10%", want: "flex:2 2 10%"},
+		{in: "grid-area:1 / 2 / 3", want: "grid-area:1 / 2 / 3"},
+		{in: "transition:opacity 1s,all 0s ease-out 1s", want: "transition:opacity 1s,0s ease-out 1s"},
+		{in: "inset:1px 2px 1px 2px !important", want: "inset:1px 2px !important"},
+		{in: "border-color:red blue", want: "border-color:red blue"},
+		{in: "border-left:thin dotted", want: "border-left:thin dotted"},
+		{in: "border-block:1px solid red", want: "border-block:1px solid red"},
+		{in: "padding-inline:1px 1px", want: "padding-inline:1px"},
+		{in: "border-radius:1px 2px/3px", want: "border-radius:1px 2px / 3px"},
+		{in: "border-radius:50%", want: "border-radius:50%"},
+		{in: "border-image:url(a.png) 30 / / 2px round", want: "border-image:url(a.png) 30 / / 2px round"},
+		{in: "border-image:none", want: "border-image:none"},
+		{in: "font-variant:none", want: "font-variant:none"},
+		{in: "font:small-caps 12px serif", want: "font:small-caps 12px serif"},
+		{in: "list-style:inside url(a.png)", want: "list-style:inside url(a.png)"},
+		{in: "list-style:none", want: "list-style:none"},
+		{in: "text-decoration:underline dotted", want: "text-decoration:underline dotted"},
+		{in: "flex-flow:column wrap", want: "flex-flow:column wrap"},
+		{in: "columns:12em 3", want: "columns:12em 3"},
+		{in: "grid-row:1 / span 2", want: "grid-row:1 / span 2"},
+		{in: "grid-column:main", want: "grid-column:main"},
+		{in: "gap:1px 2px", want: "gap:1px 2px"},
+		{in: "overflow:hidden", want: "overflow:hidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v := &Ruleset{Declarations: testDeclarations(tt.in)}
+			v.ExpandShorthands()
+			v.CollapseShorthands()
+			if got := testDeclarationsBytes(v.Declarations); got != tt.want {
+				t.Errorf("Ruleset.CollapseShorthands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownProperty
+	ErrUnknownProperty = errors.New("unknown property")
+	// ErrInvalidValue
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrMisplacedDescriptor
+	ErrMisplacedDescriptor = errors.New("misplaced descriptor")
+)
+
+// Diagnostic is a problem found by Validate
+type Diagnostic struct {
+	Selector TextBytes
+	Property TextBytes
+	Value    TextBytes
+	Err      error
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("%s{%s:%s}: %s", d.Selector, d.Property, d.Value, d.Err)
+}
+
+// Unwrap returns ErrUnknownProperty, ErrInvalidValue or ErrMisplacedDescriptor
+func (d *Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+var fontFaceRule = TextBytes("@font-face")
+
+// Validate checks every declaration against the built-in table of
+// properties and @font-face descriptors. Custom and vendor prefixed
+// properties are checked only when registered by RegisterProperty, known
+// properties without a built-in syntax like grid-template are not checked.
+// Values with var() are never checked, they are known at computed-value time.
+func Validate(s Statements) []Diagnostic {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var ret []Diagnostic
+	for k := range s {
+		ret = validateStatement(&s[k], ret)
+	}
+	return ret
+}
+
+// ValidateDeclaration checks declaration of a ruleset, returns *Diagnostic
+// or nil
+func ValidateDeclaration(d Declaration) error {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if ret := validateDeclaration(nil, d, properties, uncheckedProperties, nil); len(ret) > 0 {
+		return &ret[0]
+	}
+	return nil
+}
+
+func validateStatement(v *Statement, dst []Diagnostic) []Diagnostic {
+	if v.AtRule != nil {
+		if info, ok := v.AtRule.Identifier.Information.(*FontFaceInformation); ok {
+			for _, d := range info.Declarations {
+				dst = validateDeclaration(fontFaceRule, d, fontFace, nil, dst)
+			}
+		}
+		for _, i := range v.AtRule.Nested {
+			dst = validateStatement(i, dst)
+		}
+	}
+
+	if v.Ruleset != nil {
+		selector := TextBytes(strings.Join(selectorKeys(v.Ruleset), ","))
+		for _, d := range v.Ruleset.Declarations {
+			dst = validateDeclaration(selector, d, properties, uncheckedProperties, dst)
+		}
+		for _, i := range v.Ruleset.Nested {
+			dst = validateStatement(i, dst)
+		}
+	}
+
+	return dst
+}
+
+// validateDeclaration appends a diagnostic of the declaration checked by
+// table, names of unchecked are known but not checked
+func validateDeclaration(selector TextBytes, d Declaration, table map[string]*grammar, unchecked map[string]bool, dst []Diagnostic) []Diagnostic {
+	name := string(d.Property)
+	if !isCustomProperty(d.Property) {
+		name = strings.ToLower(name)
+	}
+
+	g, ok := table[name]
+	if !ok && (isCustomProperty(d.Property) || isVendorPrefixed(name) || unchecked[name]) {
+		return dst
+	}
+
+	raw := valuesBytes(d.Values)
+	diagnostic := Diagnostic{
+		Selector: selector,
+		Property: d.Property,
+		Value:    TextBytes(raw),
+	}
+
+	if !ok {
+		diagnostic.Err = ErrUnknownProperty
+		if _, isProperty := properties[name]; isProperty || uncheckedProperties[name] || isDescriptor(name) {
+			diagnostic.Err = ErrMisplacedDescriptor
+		}
+		return append(dst, diagnostic)
+	}
+
+	if bytes.Contains(bytes.ToLower(raw), []byte("var(")) {
+		return dst
+	}
+
+	values, _ := splitImportant(d.Values)
+	if _, ok := cssWideKeyword(values); ok {
+		return dst
+	}
+
+	m := &grammarMatcher{types: types, properties: properties}
+	if !m.matches(g, grammarTokens(values)) {
+		diagnostic.Err = ErrInvalidValue
+		return append(dst, diagnostic)
+	}
+
+	return dst
+}
+
+// isDescriptor reports whether name is a @font-face descriptor
+func isDescriptor(name string) bool {
+	_, ok := fontFace[name]
+	return ok
+}
+
+func isVendorPrefixed(name string) bool {
+	return len(name) > 1 && name[0] == '-' && name[1] != '-'
+}
@@ -0,0 +1,134 @@
+package css2json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	fontFaceRule := Statement{
+		AtRule: &AtRule{
+			Identifier: Identifier{
+				Type: TextBytes("font-face"),
+				Information: &FontFaceInformation{
+					Declarations: testDeclarations(
+						`font-family:"Open Sans"`,
+						`src:url(a.woff2) format("woff2"),local(Open Sans)`,
+						"font-display:swap",
+						"unicode-range:U+0000-00FF",
+						"color:red",
+					),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    Statements
+		want []error
+	}{
+		{
+			name: "valid",
+			s: Statements{
+				testRule([]string{"p"},
+					"color:#06c",
+					"margin:0 auto",
+					"border:1px solid rgb(0 0 0 / 50%)",
+					"font:italic bold 12px/1.5 \"Helvetica Neue\",Arial,sans-serif",
+					"background:url(a.png) no-repeat center/cover,#fff",
+					"transition:opacity .3s ease-in-out,transform 1s cubic-bezier(0.4, 0, 0.2, 1) 200ms",
+					"transform:translate(10px, 20%) rotate(45deg)",
+					"box-shadow:inset 0 1px 2px rgba(0,0,0,.5),0 0 1px red",
+					"grid-area:1 / span 2",
+					"display:inline flex",
+					"width:calc(100% - 2px) !important",
+					"flex:1 1 0%",
+					"z-index:inherit",
+					"--custom:whatever",
+					"-webkit-box-orient:vertical",
+					"height:var(--h)",
+					"grid-template-columns:repeat(3, 1fr) minmax(0, 1fr)",
+					"clip-path:polygon(0 0, 100% 0, 50% 100%)",
+					"clip:rect(0, 0, 0, 0)",
+					"transition-timing-function:linear(0, .5, 1)",
+				),
+			},
+		},
+		{
+			name: "logical and other properties",
+			s: Statements{
+				testRule([]string{"p"},
+					"direction:rtl",
+					"writing-mode:vertical-rl",
+					"margin-inline:0 auto",
+					"padding-block-start:1em",
+					"inset-inline-start:0",
+					"inline-size:50%",
+					"border-inline-end:1px solid red",
+					"clip-path:circle(50%) border-box",
+					"touch-action:pan-x pinch-zoom",
+					"place-items:center",
+					"place-self:start end",
+					"font-variant-numeric:tabular-nums slashed-zero",
+					"mix-blend-mode:multiply",
+					"scroll-snap-type:x mandatory",
+					"grid-template:auto / 1fr 1fr",
+				),
+			},
+		},
+		{
+			name: "nested rules",
+			s: Statements{
+				testNested(testRule([]string{"p"}, "color:red"),
+					testRule([]string{"&:hover"}, "color:rde"),
+				),
+			},
+			want: []error{ErrInvalidValue},
+		},
+		{
+			name: "unknown property",
+			s: Statements{
+				testRule([]string{"p"}, "colr:red", "color:red"),
+			},
+			want: []error{ErrUnknownProperty},
+		},
+		{
+			name: "bad values",
+			s: Statements{
+				testMedia("min-width", "1px",
+					testRule([]string{"p"}, "color:rde", "margin:1px 2px 3px 4px 5px", "display:blocky", "opacity:1px"),
+				),
+			},
+			want: []error{ErrInvalidValue, ErrInvalidValue, ErrInvalidValue, ErrInvalidValue},
+		},
+		{
+			name: "bad values of logical properties",
+			s: Statements{
+				testRule([]string{"p"}, "direction:up", "margin-inline:1px 2px 3px", "touch-action:auto none"),
+			},
+			want: []error{ErrInvalidValue, ErrInvalidValue, ErrInvalidValue},
+		},
+		{
+			name: "misplaced descriptors",
+			s: Statements{
+				testRule([]string{"p"}, "font-display:swap"),
+				fontFaceRule,
+			},
+			want: []error{ErrMisplacedDescriptor, ErrMisplacedDescriptor},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(tt.s)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", got, tt.want)
+			}
+			for k := range got {
+				if !errors.Is(&got[k], tt.want[k]) {
+					t.Errorf("Validate()[%d] = %v, want %v", k, got[k].Error(), tt.want[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package css2json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrUndefinedVariable
+	ErrUndefinedVariable = errors.New("undefined variable")
+	// ErrCyclicVariable
+	ErrCyclicVariable = errors.New("cyclic variable")
+)
+
+// rootSelectors define custom properties visible to every rule
+var rootSelectors = []string{":root", "html", "*"}
+
+// VarError is a var() reference that can not be resolved
+type VarError struct {
+	Selector TextBytes
+	Property TextBytes
+	Name     TextBytes
+	Err      error
+}
+
+func (e *VarError) Error() string {
+	return fmt.Sprintf("%s{%s}: %s %s", e.Selector, e.Property, e.Err, e.Name)
+}
+
+// Unwrap returns ErrUndefinedVariable or ErrCyclicVariable
+func (e *VarError) Unwrap() error {
+	return e.Err
+}
+
+// ResolveVars substitutes var() references with the values of custom
+// properties and removes custom property declarations, so the result
+// can be used by browsers without custom properties support.
+//
+// A custom property is visible to rules with the same selector and,
+// through :root, html and *, to every rule. Definitions inside an
+// at-rule block override the ones of the enclosing blocks. When a rule
+// has several selectors resolving to different values, it is split.
+// Declarations referencing an undefined variable without fallback or
+// a cyclic variable are dropped and reported. Nested rules are flattened
+// first, browsers without custom properties don't support nesting either.
+func ResolveVars(s Statements) (Statements, []*VarError) {
+	r := &varResolver{seen: map[string]bool{}}
+
+	s = withoutNesting(s)
+	list := make([]*Statement, len(s))
+	for k := range s {
+		list[k] = &s[k]
+	}
+
+	var ret Statements
+	for _, i := range r.statements(nil, list) {
+		ret = append(ret, *i)
+	}
+
+	return ret, r.errs
+}
+
+type varBlock struct {
+	parent *varBlock
+	scopes map[string]map[string]TextBytes
+}
+
+func (b *varBlock) lookup(selector, name string) (TextBytes, bool) {
+	for i := b; i != nil; i = i.parent {
+		if v, ok := i.scopes[selector][name]; ok {
+			return v, true
+		}
+	}
+
+	for i := b; i != nil; i = i.parent {
+		for _, root := range rootSelectors {
+			if v, ok := i.scopes[root][name]; ok {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+type varResolver struct {
+	errs     []*VarError
+	seen     map[string]bool
+	property TextBytes
+}
+
+func (r *varResolver) report(selector string, property TextBytes, err *VarError) {
+	err.Selector = TextBytes(selector)
+	err.Property = property
+
+	if key := err.Error(); !r.seen[key] {
+		r.seen[key] = true
+		r.errs = append(r.errs, err)
+	}
+}
+
+func (r *varResolver) statements(parent *varBlock, list []*Statement) []*Statement {
+	b := &varBlock{
+		parent: parent,
+		scopes: map[string]map[string]TextBytes{},
+	}
+
+	for _, i := range list {
+		if i.Ruleset == nil {
+			continue
+		}
+		for _, sel := range selectorKeys(i.Ruleset) {
+			for _, d := range i.Ruleset.Declarations {
+				if !isCustomProperty(d.Property) {
+					continue
+				}
+				if b.scopes[sel] == nil {
+					b.scopes[sel] = map[string]TextBytes{}
+				}
+				b.scopes[sel][string(d.Property)] = valuesBytes(d.Values)
+			}
+		}
+	}
+
+	var ret []*Statement
+	for _, i := range list {
+		st := &Statement{}
+
+		if i.AtRule != nil {
+			st.AtRule = r.atRule(b, i.AtRule)
+		}
+
+		var rulesets []*Ruleset
+		if i.Ruleset != nil {
+			rulesets = r.ruleset(b, i.Ruleset)
+			if len(rulesets) > 0 {
+				st.Ruleset = rulesets[0]
+				rulesets = rulesets[1:]
+			}
+		}
+
+		if st.AtRule != nil || st.Ruleset != nil {
+			ret = append(ret, st)
+		}
+		for _, rs := range rulesets {
+			ret = append(ret, &Statement{Ruleset: rs})
+		}
+	}
+
+	return ret
+}
+
+func (r *varResolver) atRule(b *varBlock, v *AtRule) *AtRule {
+	ret := &AtRule{Identifier: v.Identifier}
+
+	if info, ok := v.Identifier.Information.(*FontFaceInformation); ok {
+		ret.Identifier.Information = &FontFaceInformation{
+			Declarations: r.declarations(b, "", info.Declarations),
+		}
+	}
+
+	if v.Nested != nil {
+		ret.Nested = r.statements(b, v.Nested)
+		if len(ret.Nested) == 0 && len(v.Nested) > 0 {
+			return nil
+		}
+	}
+
+	return ret
+}
+
+func (r *varResolver) ruleset(b *varBlock, v *Ruleset) []*Ruleset {
+	var ret []*Ruleset
+
+	for idx, sel := range selectorKeys(v) {
+		decls := r.declarations(b, sel, v.Declarations)
+		if len(decls) == 0 {
+			continue
+		}
+
+		var selectors []Selector
+		if len(v.Selectors) > 0 {
+			selectors = []Selector{v.Selectors[idx]}
+		}
+
+		if n := len(ret); n > 0 && reflect.DeepEqual(ret[n-1].Declarations, decls) {
+			ret[n-1].Selectors = append(ret[n-1].Selectors, selectors...)
+			continue
+		}
+
+		ret = append(ret, &Ruleset{
+			Selectors:    selectors,
+			Declarations: decls,
+		})
+	}
+
+	return ret
+}
+
+func (r *varResolver) declarations(b *varBlock, selector string, decls []Declaration) []Declaration {
+	var ret []Declaration
+
+	for _, d := range decls {
+		if isCustomProperty(d.Property) {
+			continue
+		}
+
+		raw := valuesBytes(d.Values)
+		if indexVar(raw) < 0 {
+			ret = append(ret, d)
+			continue
+		}
+
+		r.property = d.Property
+		resolved, err := r.substitute(b, selector, raw, nil)
+		if err != nil {
+			r.report(selector, d.Property, err)
+			continue
+		}
+
+		ret = append(ret, Declaration{
+			Property: d.Property,
+			Values:   parseValues(resolved),
+		})
+	}
+
+	return ret
+}
+
+// substitute replaces every var() in raw, stack holds the names being
+// resolved to detect cycles
+func (r *varResolver) substitute(b *varBlock, selector string, raw []byte, stack []string) ([]byte, *VarError) {
+	var dst []byte
+
+	for {
+		start := indexVar(raw)
+		if start < 0 {
+			return append(dst, raw...), nil
+		}
+		end := closingParenthesis(raw, start+3)
+		if end < 0 {
+			return append(dst, raw...), nil
+		}
+
+		args := splitTopLevel(raw[start+4:end], comma)
+		name := string(bytes.TrimSpace(args[0]))
+
+		value, err := r.variable(b, selector, name, stack)
+		if err != nil && len(args) > 1 {
+			if err.Err == ErrCyclicVariable {
+				r.report(selector, r.property, err)
+			}
+			fallback := bytes.TrimSpace(raw[start+4+len(args[0])+1 : end])
+			value, err = r.substitute(b, selector, fallback, stack)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		dst = append(dst, raw[:start]...)
+		dst = append(dst, value...)
+		raw = raw[end+1:]
+	}
+}
+
+func (r *varResolver) variable(b *varBlock, selector, name string, stack []string) ([]byte, *VarError) {
+	for _, i := range stack {
+		if i == name {
+			return nil, &VarError{Name: TextBytes(name), Err: ErrCyclicVariable}
+		}
+	}
+
+	value, ok := b.lookup(selector, name)
+	if !ok {
+		return nil, &VarError{Name: TextBytes(name), Err: ErrUndefinedVariable}
+	}
+
+	return r.substitute(b, selector, value, append(stack, name))
+}
+
+// indexVar returns index of the first var() outside of strings, its name
+// is matched ignoring case, or -1
+func indexVar(raw []byte) int {
+	var quote byte
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == doubleQuote || c == '\'':
+			quote = c
+		case c == '\\':
+			i++
+		case (i == 0 || !isIdentByte(raw[i-1])) && bytes.HasPrefix(bytes.ToLower(raw[i:min(i+4, len(raw))]), []byte("var(")):
+			return i
+		}
+	}
+	return -1
+}
+
+// closingParenthesis returns index of the parenthesis closing the one
+// at open, parentheses in strings are skipped
+func closingParenthesis(b []byte, open int) int {
+	var (
+		depth int
+		quote byte
+	)
+	for i := open; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == doubleQuote || c == '\'':
+			quote = c
+		case c == leftParenthesis:
+			depth++
+		case c == rightParenthesis:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func selectorKeys(v *Ruleset) []string {
+	if len(v.Selectors) == 0 {
+		return []string{""}
+	}
+
+	ret := make([]string, len(v.Selectors))
+	for k := range v.Selectors {
+		ret[k] = string(encodeBytes(&v.Selectors[k]))
+	}
+	return ret
+}
+
+func isCustomProperty(p TextBytes) bool {
+	return bytes.HasPrefix(p, []byte("--"))
+}
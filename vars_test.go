@@ -0,0 +1,120 @@
+package css2json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        Statements
+		want     string
+		wantErrs []error
+	}{
+		{
+			name: "root scope",
+			s: Statements{
+				testRule([]string{":root"}, "--main:#06c", "--gap:4px"),
+				testRule([]string{"a"}, "color:var(--main)", "margin:var(--gap) calc(var(--gap) * 2)"),
+			},
+			want: "a{color:#06c;margin:4px calc(4px * 2)}",
+		},
+		{
+			name: "selector scope overrides root",
+			s: Statements{
+				testRule([]string{":root"}, "--c:red"),
+				testRule([]string{".dark"}, "--c:black"),
+				testRule([]string{".dark", ".light"}, "color:var(--c)"),
+			},
+			want: ".dark{color:black}.light{color:red}",
+		},
+		{
+			name: "fallback",
+			s: Statements{
+				testRule([]string{"p"}, "color:var(--none, var(--also-none, blue))", "font:var(--f,12px/1.5 serif)"),
+			},
+			want: "p{color:blue;font:12px/1.5 serif}",
+		},
+		{
+			name: "nested references",
+			s: Statements{
+				testRule([]string{":root"}, "--a:var(--b)", "--b:1px solid var(--c)", "--c:red"),
+				testRule([]string{"p"}, "border:var(--a)"),
+			},
+			want: "p{border:1px solid red}",
+		},
+		{
+			name: "media block scope",
+			s: Statements{
+				testRule([]string{":root"}, "--w:10px"),
+				testMedia("min-width", "768px",
+					testRule([]string{":root"}, "--w:20px"),
+					testRule([]string{"p"}, "width:var(--w)"),
+				),
+				testRule([]string{"p"}, "width:var(--w)"),
+			},
+			want: "@media (min-width:768px){p{width:20px}}p{width:10px}",
+		},
+		{
+			name: "nested rules",
+			s: Statements{
+				testRule([]string{":root"}, "--x:red", "--y:1px"),
+				testNested(testRule([]string{".a"}, "color:var(--x)"),
+					testRule([]string{"&:hover"}, "margin:var(--y)"),
+				),
+			},
+			want: ".a{color:red}.a:hover{margin:1px}",
+		},
+		{
+			name: "strings and case",
+			s: Statements{
+				testRule([]string{":root"}, "--x:red", "--y:blue"),
+				testRule([]string{"p"}, `content:"var(--y)"`, "background:VAR(--x)", `font-family:var(--f, ")")`),
+			},
+			want: `p{content:"var(--y)";background:red;font-family:")"}`,
+		},
+		{
+			name: "undefined",
+			s: Statements{
+				testRule([]string{"p"}, "color:var(--none)", "margin:0"),
+			},
+			want: "p{margin:0}",
+			wantErrs: []error{
+				ErrUndefinedVariable,
+			},
+		},
+		{
+			name: "cyclic",
+			s: Statements{
+				testRule([]string{":root"}, "--a:var(--b)", "--b:var(--a)"),
+				testRule([]string{"p"}, "color:var(--a)", "background:var(--b, white)"),
+			},
+			want: "p{background:white}",
+			wantErrs: []error{
+				ErrCyclicVariable,
+				ErrCyclicVariable,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := ResolveVars(tt.s)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("ResolveVars() errs = %v, want %v", errs, tt.wantErrs)
+			}
+			for k, err := range errs {
+				if !errors.Is(err, tt.wantErrs[k]) {
+					t.Errorf("ResolveVars() errs[%d] = %v, want %v", k, err, tt.wantErrs[k])
+				}
+			}
+			b, err := Encode(got)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("ResolveVars() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package css2json
+
+import (
+	"bytes"
+)
+
+// Action is returned by hooks of Visitor
+type Action int
+
+// Actions
+const (
+	// Continue walks children of the node
+	Continue Action = iota
+	// Skip doesn't walk children of the node, its leave hook isn't called
+	Skip
+	// Remove removes the node
+	Remove
+)
+
+// Visitor holds optional hooks called on entering a node before its
+// children and on leaving it after them. A hook replaces the node by
+// assigning to the pointer and removes it by returning Remove.
+type Visitor struct {
+	EnterStatement, LeaveStatement     func(*Statement) Action
+	EnterAtRule, LeaveAtRule           func(*AtRule) Action
+	EnterRuleset, LeaveRuleset         func(*Ruleset) Action
+	EnterSelector, LeaveSelector       func(*Selector) Action
+	EnterSimple, LeaveSimple           func(*Simple) Action
+	EnterDeclaration, LeaveDeclaration func(*Declaration) Action
+	EnterValue, LeaveValue             func(*Value) Action
+}
+
+// Walk visits statements depth-first in order of appearance: nested
+// statements of at-rules, declarations of @font-face, selectors with
+// selector arguments of pseudo-classes and negations, declarations, values
+// and nested statements of rulesets. The statements left are returned.
+//
+// Walk changes nodes only through the hook pointers: hooks get copies of
+// the nodes which are written to the returned statements, so s and slices
+// of its nodes are not changed by Walk itself or by assigning a field of a
+// node. Slices not walked, like Classes, are shared with s.
+//
+// A node left without children it had is removed too: a statement without
+// at-rule and ruleset, an at-rule other than @layer without nested
+// statements, a ruleset without selectors or without declarations and
+// nested statements, a declaration without values. When the first
+// compound of a selector is removed, the next one takes its place. A
+// compound with :is(), :has() or another selector list pseudo-class left
+// without arguments is removed as it matches nothing, such :not() is
+// removed from the compound as it excludes nothing.
+func Walk(s Statements, v Visitor) Statements {
+	w := &walker{v: v}
+
+	var ret Statements
+	for k := range s {
+		v := s[k]
+		if w.statement(&v) {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
+
+type walker struct {
+	v Visitor
+}
+
+func (w *walker) statement(v *Statement) bool {
+	if w.v.EnterStatement != nil {
+		if a := w.v.EnterStatement(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	had := v.AtRule != nil || v.Ruleset != nil
+	if v.AtRule != nil {
+		at := *v.AtRule
+		v.AtRule = nil
+		if w.atRule(&at) {
+			v.AtRule = &at
+		}
+	}
+	if v.Ruleset != nil {
+		rs := *v.Ruleset
+		v.Ruleset = nil
+		if w.ruleset(&rs) {
+			v.Ruleset = &rs
+		}
+	}
+	if had && v.AtRule == nil && v.Ruleset == nil {
+		return false
+	}
+
+	return w.v.LeaveStatement == nil || w.v.LeaveStatement(v) != Remove
+}
+
+func (w *walker) nested(s []*Statement) []*Statement {
+	if s == nil {
+		return nil
+	}
+
+	ret := make([]*Statement, 0, len(s))
+	for _, i := range s {
+		v := *i
+		if w.statement(&v) {
+			ret = append(ret, &v)
+		}
+	}
+	return ret
+}
+
+func (w *walker) atRule(v *AtRule) bool {
+	if w.v.EnterAtRule != nil {
+		if a := w.v.EnterAtRule(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	if info, ok := v.Identifier.Information.(*FontFaceInformation); ok {
+		fontFace := *info
+		fontFace.Declarations = w.declarations(info.Declarations)
+		v.Identifier.Information = &fontFace
+	}
+
+	// an empty @layer block still orders the layer
+	_, layer := v.Identifier.Information.(*LayerInformation)
+	nested := len(v.Nested)
+	v.Nested = w.nested(v.Nested)
+	if nested > 0 && len(v.Nested) == 0 && !layer {
+		return false
+	}
+
+	return w.v.LeaveAtRule == nil || w.v.LeaveAtRule(v) != Remove
+}
+
+func (w *walker) ruleset(v *Ruleset) bool {
+	if w.v.EnterRuleset != nil {
+		if a := w.v.EnterRuleset(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	selectors, children := len(v.Selectors), len(v.Declarations)+len(v.Nested)
+	v.Selectors = w.selectors(v.Selectors)
+	v.Declarations = w.declarations(v.Declarations)
+	v.Nested = w.nested(v.Nested)
+	if selectors > 0 && len(v.Selectors) == 0 || children > 0 && len(v.Declarations)+len(v.Nested) == 0 {
+		return false
+	}
+
+	return w.v.LeaveRuleset == nil || w.v.LeaveRuleset(v) != Remove
+}
+
+func (w *walker) selectors(s []Selector) []Selector {
+	if s == nil {
+		return nil
+	}
+
+	ret := make([]Selector, 0, len(s))
+	for k := range s {
+		v := s[k]
+		if w.selector(&v) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func (w *walker) selector(v *Selector) bool {
+	if w.v.EnterSelector != nil {
+		if a := w.v.EnterSelector(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	first := w.simple(&v.Simple)
+
+	var combinates []Combinate
+	for k := range v.Combinates {
+		c := v.Combinates[k]
+		if w.simple(&c.Simple) {
+			combinates = append(combinates, c)
+		}
+	}
+	v.Combinates = combinates
+
+	if !first {
+		if len(v.Combinates) == 0 {
+			return false
+		}
+		v.Simple, v.Combinates = v.Combinates[0].Simple, v.Combinates[1:]
+	}
+
+	return w.v.LeaveSelector == nil || w.v.LeaveSelector(v) != Remove
+}
+
+func (w *walker) simple(v *Simple) bool {
+	if w.v.EnterSimple != nil {
+		if a := w.v.EnterSimple(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	var ok bool
+	if v.PseudoElements, ok = w.pseudos(v.PseudoElements); !ok {
+		return false
+	}
+	if v.PseudoClasses, ok = w.pseudos(v.PseudoClasses); !ok {
+		return false
+	}
+
+	if v.Negations != nil {
+		negations := make([]Simple, 0, len(v.Negations))
+		for k := range v.Negations {
+			n := v.Negations[k]
+			if w.simple(&n) {
+				negations = append(negations, n)
+			}
+		}
+		v.Negations = negations
+	}
+
+	return w.v.LeaveSimple == nil || w.v.LeaveSimple(v) != Remove
+}
+
+// pseudos walks selector arguments of pseudo-classes, it fails when a
+// compound with them matches nothing
+func (w *walker) pseudos(s []Pseudo) ([]Pseudo, bool) {
+	if s == nil {
+		return nil, true
+	}
+
+	ret := make([]Pseudo, 0, len(s))
+	for _, p := range s {
+		selectors := len(p.Selectors)
+		p.Selectors = w.selectors(p.Selectors)
+		if selectors > 0 && len(p.Selectors) == 0 {
+			if !bytes.EqualFold(p.Ident, []byte("not")) {
+				return nil, false
+			}
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret, true
+}
+
+func (w *walker) declarations(decls []Declaration) []Declaration {
+	if decls == nil {
+		return nil
+	}
+
+	ret := make([]Declaration, 0, len(decls))
+	for k := range decls {
+		v := decls[k]
+		if w.declaration(&v) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func (w *walker) declaration(v *Declaration) bool {
+	if w.v.EnterDeclaration != nil {
+		if a := w.v.EnterDeclaration(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	if v.Values != nil {
+		values := make([]Value, 0, len(v.Values))
+		for k := range v.Values {
+			i := v.Values[k]
+			if w.value(&i) {
+				values = append(values, i)
+			}
+		}
+		if len(v.Values) > 0 && len(values) == 0 {
+			return false
+		}
+		v.Values = values
+	}
+
+	return w.v.LeaveDeclaration == nil || w.v.LeaveDeclaration(v) != Remove
+}
+
+func (w *walker) value(v *Value) bool {
+	if w.v.EnterValue != nil {
+		if a := w.v.EnterValue(v); a != Continue {
+			return a == Skip
+		}
+	}
+
+	return w.v.LeaveValue == nil || w.v.LeaveValue(v) != Remove
+}
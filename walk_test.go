@@ -0,0 +1,230 @@
+package css2json
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	keyframes := func() Statement {
+		from := testRule([]string{"from"}, "color:red")
+		return Statement{
+			AtRule: &AtRule{
+				Identifier: Identifier{
+					Type:        TextBytes("keyframes"),
+					Information: &KeyframesInformation{Value: TextBytes("blink")},
+				},
+				Nested: []*Statement{&from},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		s    Statements
+		v    Visitor
+		want string
+	}{
+		{
+			name: "replace",
+			s: Statements{
+				testRule([]string{".btn:is(.btn-primary, a.btn)", "div:not(.btn)"}, "color:red"),
+			},
+			v: Visitor{
+				EnterSimple: func(v *Simple) Action {
+					for k, c := range v.Classes {
+						if string(c) == "btn" {
+							v.Classes[k] = TextBytes("x-btn")
+						}
+					}
+					return Continue
+				},
+			},
+			want: `.x-btn:is(.btn-primary,a.x-btn),div:not(.x-btn){color:red}`,
+		},
+		{
+			name: "remove declarations",
+			s: Statements{
+				testRule([]string{"a"}, "color:red"),
+				testRule([]string{"b"}, "color:red", "margin:0"),
+				testMedia("min-width", "800px", testRule([]string{"i"}, "color:blue")),
+				{
+					AtRule: &AtRule{
+						Identifier: Identifier{
+							Type:        TextBytes("font-face"),
+							Information: &FontFaceInformation{Declarations: testDeclarations("font-family:A", "color:red")},
+						},
+					},
+				},
+			},
+			v: Visitor{
+				EnterDeclaration: func(d *Declaration) Action {
+					if string(d.Property) == "color" {
+						return Remove
+					}
+					return Skip
+				},
+			},
+			want: `b{margin:0}@font-face {font-family:A}`,
+		},
+		{
+			name: "remove values and selectors",
+			s: Statements{
+				testRule([]string{"div > p", "span", "a:has(> img, svg)"}, "font-family:A,B,C", "box-shadow:none"),
+			},
+			v: Visitor{
+				EnterSimple: func(v *Simple) Action {
+					switch string(v.Element) {
+					case "div", "span", "svg":
+						return Remove
+					}
+					return Continue
+				},
+				EnterValue: func(v *Value) Action {
+					if string(v.ValueSpace[0]) == "B" || string(v.ValueSpace[0]) == "none" {
+						return Remove
+					}
+					return Continue
+				},
+			},
+			want: `p,a:has(>img){font-family:A,C}`,
+		},
+		{
+			name: "emptied selector arguments",
+			s: Statements{
+				testRule([]string{"a:is(.x)", "b:not(.x)", "i:has(.x, p)"}, "color:red"),
+				testLayer([]string{"base"}, testRule([]string{":where(.x)"}, "color:red")),
+			},
+			v: Visitor{
+				EnterSimple: func(v *Simple) Action {
+					if len(v.Classes) > 0 && string(v.Classes[0]) == "x" {
+						return Remove
+					}
+					return Continue
+				},
+			},
+			want: `b,i:has(p){color:red}@layer base{}`,
+		},
+		{
+			name: "skip and leave",
+			s: Statements{
+				keyframes(),
+				testRule([]string{"a"}, "color:red"),
+			},
+			v: Visitor{
+				EnterAtRule: func(v *AtRule) Action {
+					return Skip
+				},
+				LeaveRuleset: func(v *Ruleset) Action {
+					return Remove
+				},
+			},
+			want: `@keyframes blink{from{color:red}}`,
+		},
+		{
+			name: "nested rules",
+			s: Statements{
+				testNested(testRule([]string{".a"}, "color:red"),
+					testRule([]string{"&:hover"}, "color:blue"),
+					testMedia("min-width", "800px", testRule([]string{"&"}, "color:green")),
+				),
+				testNested(testRule([]string{".b"}, "color:red"),
+					testRule([]string{"&:hover"}, "margin:0"),
+				),
+			},
+			v: Visitor{
+				EnterDeclaration: func(d *Declaration) Action {
+					if string(d.Property) == "color" {
+						return Remove
+					}
+					return Skip
+				},
+			},
+			want: `.b{&:hover{margin:0}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(Walk(tt.s, tt.v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Walk() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk_order(t *testing.T) {
+	s := Statements{
+		testMedia("print", "", testRule([]string{"a b"}, "color:red")),
+	}
+
+	dst := &bytes.Buffer{}
+	hook := func(name string) func() Action {
+		return func() Action {
+			dst.WriteString(name + " ")
+			return Continue
+		}
+	}
+	Walk(s, Visitor{
+		EnterStatement:   func(*Statement) Action { return hook("statement")() },
+		LeaveStatement:   func(*Statement) Action { return hook("/statement")() },
+		EnterAtRule:      func(*AtRule) Action { return hook("atrule")() },
+		LeaveAtRule:      func(*AtRule) Action { return hook("/atrule")() },
+		EnterRuleset:     func(*Ruleset) Action { return hook("ruleset")() },
+		LeaveRuleset:     func(*Ruleset) Action { return hook("/ruleset")() },
+		EnterSelector:    func(*Selector) Action { return hook("selector")() },
+		LeaveSelector:    func(*Selector) Action { return hook("/selector")() },
+		EnterSimple:      func(*Simple) Action { return hook("simple")() },
+		LeaveSimple:      func(*Simple) Action { return hook("/simple")() },
+		EnterDeclaration: func(*Declaration) Action { return hook("declaration")() },
+		LeaveDeclaration: func(*Declaration) Action { return hook("/declaration")() },
+		EnterValue:       func(*Value) Action { return hook("value")() },
+		LeaveValue:       func(*Value) Action { return hook("/value")() },
+	})
+
+	want := "statement atrule statement ruleset selector simple /simple simple /simple /selector " +
+		"declaration value /value /declaration /ruleset /statement /atrule /statement"
+	if got := strings.TrimSpace(dst.String()); got != want {
+		t.Errorf("Walk() order = %s, want %s", got, want)
+	}
+}
+
+func TestWalk_unchanged(t *testing.T) {
+	in := Statements{
+		testRule([]string{"a.x"}, "color:red"),
+		testRule([]string{"b"}, "color:red", "margin:0"),
+		testMedia("print", "", testRule([]string{"c"}, "color:red")),
+		testNested(testRule([]string{"c"}, "color:red"), testRule([]string{"&:hover"}, "margin:0")),
+	}
+	before, _ := Encode(in)
+
+	v := Visitor{
+		EnterSimple: func(v *Simple) Action {
+			v.Classes = nil
+			return Continue
+		},
+		EnterDeclaration: func(d *Declaration) Action {
+			if string(d.Property) == "color" {
+				return Remove
+			}
+			return Skip
+		},
+	}
+
+	got, _ := Encode(Walk(in, v))
+	if want := `b{margin:0}c{&:hover{margin:0}}`; string(got) != want {
+		t.Errorf("Walk() = %s, want %s", got, want)
+	}
+	if after, _ := Encode(in); string(after) != string(before) {
+		t.Errorf("Walk() changed statements %s, want %s", after, before)
+	}
+
+	Walk(Optimize(in), v)
+	if after, _ := Encode(in); string(after) != string(before) {
+		t.Errorf("Walk() of Optimize() changed statements %s, want %s", after, before)
+	}
+}